@@ -183,6 +183,68 @@ func startVaccinationReminderTask(vaccinationService *services.VaccinationServic
 }
 
 
+// startLowStockCheckTask periodically raises low stock alerts missed by write-time checks
+func startLowStockCheckTask(inventoryService *services.InventoryService) {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := inventoryService.CheckAllLowStock(); err != nil {
+			log.Printf("Error checking low stock: %v", err)
+		}
+	}
+}
+
+// startLotExpiryCheckTask warns users daily about inventory lots nearing expiry
+func startLotExpiryCheckTask(inventoryService *services.InventoryService) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := inventoryService.CheckExpiringLots(); err != nil {
+			log.Printf("Error checking expiring lots: %v", err)
+		}
+	}
+}
+
+// startRecurringExpenseTask posts recurring expenses as they fall due, catching up on startup
+func startRecurringExpenseTask(recurringExpenseService *services.RecurringExpenseService) {
+	if err := recurringExpenseService.PostDueExpenses(time.Now()); err != nil {
+		log.Printf("Error posting recurring expenses: %v", err)
+	}
+
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := recurringExpenseService.PostDueExpenses(time.Now()); err != nil {
+			log.Printf("Error posting recurring expenses: %v", err)
+		}
+	}
+}
+
+// startFinancialReconcileTask recomputes flocks' recent and changed monthly financial data each night,
+// catching changes the write-time recompute missed, and clears ledger entries of deleted records.
+// The first run after startup covers every month with activity.
+func startFinancialReconcileTask(aggregationService *services.FinancialAggregationService) {
+	var lastRun time.Time
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 2, 0, 0, 0, now.Location())
+		time.Sleep(next.Sub(now))
+
+		started := time.Now()
+		if err := aggregationService.ReconcileAll(started, lastRun); err != nil {
+			log.Printf("Error reconciling flock financial data: %v", err)
+		} else {
+			lastRun = started
+		}
+		if err := services.NewLedgerService(db.DB).RemoveOrphanedEntries(); err != nil {
+			log.Printf("Error removing orphaned ledger entries: %v", err)
+		}
+	}
+}
+
 func main() {
 	
 	gin.SetMode(gin.ReleaseMode) 
@@ -202,6 +264,10 @@ func main() {
 	// Initialize the database
 	db.InitializeDB()
 
+	if err := models.DedupeFlocksFinancialData(); err != nil {
+		log.Fatalf("Failed to dedupe flock financial data: %v", err)
+	}
+
 	// Auto-migrate all models
 	err := db.DB.AutoMigrate(
 		&models.Flock{},
@@ -221,6 +287,28 @@ func main() {
 		&models.Admin{},
 		&models.Payment{},
 		&models.EggAdjustment{},
+		&models.MortalityEvent{},
+		&models.FeedLog{},
+		&models.BreedStandard{},
+		&models.EggGradeLine{},
+		&models.SaleGradeLine{},
+		&models.InventoryMovement{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderLine{},
+		&models.InventoryLot{},
+		&models.Customer{},
+		&models.SalePayment{},
+		&models.SaleReturn{},
+		&models.PriceListItem{},
+		&models.RecurringExpense{},
+		&models.RecurringExpenseException{},
+		&models.ExpenseAttachment{},
+		&models.ExpenseAllocation{},
+		&models.Category{},
+		&models.Account{},
+		&models.JournalEntry{},
+		&models.JournalLine{},
 	)
 	if err != nil {
 		log.Fatalf("Error during auto migration: %v", err)
@@ -236,6 +324,27 @@ func main() {
 		log.Fatalf("Migration failed: %v", err)
 	}
 	log.Println("Beta Fields Migration completed successfully")
+	if err := models.SeedBreedStandards(); err != nil {
+		log.Fatalf("Failed to seed breed standards: %v", err)
+	}
+	if err := models.MigrateInventoryOpeningBalances(); err != nil {
+		log.Fatalf("Failed to migrate inventory opening balances: %v", err)
+	}
+	if err := models.SeedCategories(); err != nil {
+		log.Fatalf("Failed to seed categories: %v", err)
+	}
+	if err := models.MigrateCategoryReferences(); err != nil {
+		log.Fatalf("Failed to migrate categories: %v", err)
+	}
+	if err := models.SeedAccounts(); err != nil {
+		log.Fatalf("Failed to seed accounts: %v", err)
+	}
+	if err := services.NewLedgerService(db.DB).BackfillLedger(); err != nil {
+		log.Printf("Error posting ledger for existing records: %v", err)
+	}
+	if err := services.NewLedgerService(db.DB).RepostOpeningBalances(); err != nil {
+		log.Printf("Error reposting inventory opening balances: %v", err)
+	}
 
 	// Initialize authentication middleware
 	middlewares.InitAuthMiddleware()
@@ -261,12 +370,21 @@ func main() {
 
 	// Set up API routes
 	api.SetupInventoryRoutes(router)
+	api.SetupSupplierRoutes(router)
+	api.SetupPurchaseOrderRoutes(router)
 	api.SetupRoutes(router)
 	expenseService := &services.ExpenseService{DB: db.DB}
 	api.SetupExpenseRoutes(router, expenseService)
+	api.SetupExpenseAttachmentRoutes(router)
+	api.SetupRecurringExpenseRoutes(router)
+	api.SetupCategoryRoutes(router)
 	api.SetupSalesRoutes(router)
+	api.SetupCustomerRoutes(router)
+	api.SetupPriceListRoutes(router)
 	api.SetupEggProductionRoutes(router)
 	api.SetupFlockRoutes(router)
+	api.SetupMortalityRoutes(router)
+	api.SetupFeedRoutes(router)
 	api.SetupVaccinationRoutes(router)
 	api.SetupBillingRoutes(router)
 	api.SetupSubscriptionRoutes(router)
@@ -274,10 +392,13 @@ func main() {
 	api.SetupFlockFinancialRoutes(router)
 	api.SetupNotificationRoutes(router)
 	api.SetupBudgetRoutes(router)
+	api.SetupAnalyticsRoutes(router)
+	api.SetupLedgerRoutes(router)
 	api.SetupStatsRoutes(router)
 	api.RegisterPaymentRoutes(router)
 	api.RegisterWebhookRoutes(router)
 	api.SetupEggAdjustmentRoutes(router)
+	api.SetupEggStockRoutes(router)
 	api.SetupPaystackRoutes(router, db.DB)
 
 
@@ -295,6 +416,19 @@ func main() {
 	vaccinationService := services.NewVaccinationService(db.DB)
 	go startVaccinationReminderTask(vaccinationService)
 
+	// Start low stock alert background task
+	inventoryService := services.NewInventoryService(db.DB)
+	go startLowStockCheckTask(inventoryService)
+	go startLotExpiryCheckTask(inventoryService)
+
+	// Start recurring expense posting background task
+	go startRecurringExpenseTask(services.NewRecurringExpenseService(db.DB))
+
+	// Start flock financial data recompute worker and nightly reconcile
+	aggregationService := services.NewFinancialAggregationService(db.DB)
+	go aggregationService.Run()
+	go startFinancialReconcileTask(aggregationService)
+
 	// Start the server
 	port := os.Getenv("PORT")
 	if port == "" {
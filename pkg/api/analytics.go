@@ -0,0 +1,68 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler handles farm analytics requests
+type AnalyticsHandler struct {
+	UnitEconomics *services.UnitEconomicsService
+}
+
+// SetupAnalyticsRoutes sets up the analytics API routes
+func SetupAnalyticsRoutes(r *gin.Engine) {
+	handler := &AnalyticsHandler{UnitEconomics: services.NewUnitEconomicsService(db.DB)}
+
+	routes := r.Group("/analytics").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/unit-economics", handler.GetUnitEconomics)
+	}
+}
+
+// GetUnitEconomics returns cost per egg, per dozen and per bird and the break-even egg price, month by month.
+// Query parameters: start and end (YYYY-MM-DD, default the last twelve months) and flock_id.
+// Without flock_id it returns the whole farm first, then each flock.
+func (h *AnalyticsHandler) GetUnitEconomics(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	defaultStart := time.Date(now.Year(), now.Month()-11, 1, 0, 0, 0, 0, time.Local)
+	start, end, err := parseDateRangeQuery(c, defaultStart, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if value := c.Query("flock_id"); value != "" {
+		flockID := parseUint(value)
+		if flockID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+			return
+		}
+		economics, err := h.UnitEconomics.GetFlockUnitEconomics(userID, flockID, start, end)
+		if errors.Is(err, services.ErrFlockNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute unit economics"})
+			return
+		}
+		c.JSON(http.StatusOK, economics)
+		return
+	}
+
+	economics, err := h.UnitEconomics.GetUnitEconomics(userID, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute unit economics"})
+		return
+	}
+	c.JSON(http.StatusOK, economics)
+}
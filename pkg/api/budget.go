@@ -4,10 +4,12 @@ import (
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
 	"log"
 	"net/http"
 	"strconv"
 	"errors"
+	"time"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,6 +23,7 @@ func SetupBudgetRoutes(r *gin.Engine) {
 	budgetRoutes := r.Group("/budget").Use(middlewares.AuthMiddleware())
 	{
 		budgetRoutes.GET("/", handler.GetBudgets)
+		budgetRoutes.GET("/variance", handler.GetVariance)
 		budgetRoutes.GET("/flock/:flockID", handler.GetBudgetsByFlock)
 		budgetRoutes.GET("/:month/:year", handler.GetBudgetByMonthYear)
 		budgetRoutes.POST("/", handler.AddBudget)
@@ -48,6 +51,44 @@ func (h *BudgetHandler) GetBudgets(c *gin.Context) {
 	c.JSON(http.StatusOK, budgets)
 }
 
+// GetVariance compares budgets with actual spend per flock and month for a year, or one month of it.
+// Query parameters: year (default current), month, flock_id and by_category=true for a category breakdown.
+func (h *BudgetHandler) GetVariance(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	year := time.Now().Year()
+	var month int
+	var flockID uint
+	var err error
+	if value := c.Query("year"); value != "" {
+		if year, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+	}
+	if value := c.Query("month"); value != "" {
+		if month, err = strconv.Atoi(value); err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return
+		}
+	}
+	if value := c.Query("flock_id"); value != "" {
+		if flockID = parseUint(value); flockID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+			return
+		}
+	}
+	byCategory := c.Query("by_category") == "true"
+
+	variances, err := services.NewBudgetService(db.DB).GetVariance(userID, year, month, flockID, byCategory)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute budget variance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, variances)
+}
+
 // GetBudgetsByFlock retrieves all budgets for a specific flock
 func (h *BudgetHandler) GetBudgetsByFlock(c *gin.Context) {
 	log.Println("GET /budget/flock/:flockID called")
@@ -118,6 +159,7 @@ func (h *BudgetHandler) AddBudget(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create budget"})
 		return
 	}
+	budgetChanged(budget)
 
 	c.JSON(http.StatusCreated, budget)
 }
@@ -162,6 +204,7 @@ func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
 		return
 	}
+	budgetChanged(budget)
 
 	c.JSON(http.StatusOK, budget)
 }
@@ -196,10 +239,21 @@ func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete budget"})
 		return
 	}
+	budgetChanged(budget)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Budget deleted successfully"})
 }
 
+// budgetChanged notifies the user if a new or changed budget is already nearly or fully spent
+// and refreshes the flock's financial data for the month
+func budgetChanged(budget models.Budget) {
+	if err := services.NewBudgetService(db.DB).CheckAlerts(uint(budget.UserID), budget.FlockID, budget.Year, budget.Month); err != nil {
+		log.Printf("Error checking budget alerts for flock %d: %v", budget.FlockID, err)
+	}
+	services.QueueFinancialRecompute(uint(budget.UserID), budget.FlockID,
+		time.Date(budget.Year, time.Month(budget.Month), 1, 0, 0, 0, 0, time.Local))
+}
+
 // Helper function to get the authenticated user
 func getUserFromContext(c *gin.Context) (*models.User, error) {
 	userIDVal, exists := c.Get("user_id")
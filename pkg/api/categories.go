@@ -0,0 +1,88 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler handles expense and sale category requests
+type CategoryHandler struct {
+	Service *services.CategoryService
+}
+
+// SetupCategoryRoutes sets up the category API routes
+func SetupCategoryRoutes(r *gin.Engine) {
+	handler := &CategoryHandler{Service: services.NewCategoryService(db.DB)}
+
+	routes := r.Group("/categories").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetCategories)
+		routes.POST("/", handler.AddSubcategory)
+		routes.PUT("/:id", handler.RenameSubcategory)
+		routes.DELETE("/:id", handler.DeleteSubcategory)
+	}
+}
+
+// GetCategories returns the system categories with the user's subcategories.
+// Accepts an optional "kind" query parameter (expense or sale).
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	kind := c.Query("kind")
+	if kind != "" && kind != models.CategoryKindExpense && kind != models.CategoryKindSale {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be expense or sale"})
+		return
+	}
+
+	categories, err := h.Service.GetCategories(c.GetUint("user_id"), kind)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// AddSubcategory adds a subcategory beneath a system category
+func (h *CategoryHandler) AddSubcategory(c *gin.Context) {
+	var sub models.Category
+	if err := c.ShouldBindJSON(&sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.AddSubcategory(c.GetUint("user_id"), &sub); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// RenameSubcategory renames one of the user's subcategories
+func (h *CategoryHandler) RenameSubcategory(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := h.Service.RenameSubcategory(parseUint(c.Param("id")), c.GetUint("user_id"), req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubcategory removes one of the user's subcategories, moving its records up to the parent category
+func (h *CategoryHandler) DeleteSubcategory(c *gin.Context) {
+	if err := h.Service.DeleteSubcategory(parseUint(c.Param("id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Subcategory deleted successfully"})
+}
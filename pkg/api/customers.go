@@ -0,0 +1,128 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CustomerHandler handles customer and receivables requests
+type CustomerHandler struct {
+	Service *services.CustomerService
+}
+
+// SetupCustomerRoutes sets up the customer and receivables API routes
+func SetupCustomerRoutes(r *gin.Engine) {
+	handler := &CustomerHandler{Service: services.NewCustomerService(db.DB)}
+
+	routes := r.Group("/customers").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetCustomers)
+		routes.GET("/:id", handler.GetCustomer)
+		routes.GET("/:id/sales", handler.GetOpenSales)
+		routes.POST("/:id/invoice", handler.DownloadInvoice)
+		routes.POST("/:id/invoice/email", handler.EmailInvoice)
+		routes.POST("/", handler.AddCustomer)
+		routes.PUT("/:id", handler.UpdateCustomer)
+		routes.DELETE("/:id", handler.DeleteCustomer)
+	}
+
+	receivables := r.Group("/receivables").Use(middlewares.AuthMiddleware())
+	{
+		receivables.GET("/aged", handler.GetAgedReceivables)
+	}
+}
+
+// GetCustomers returns the user's customers with their outstanding balances
+func (h *CustomerHandler) GetCustomers(c *gin.Context) {
+	customers, err := h.Service.GetCustomers(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve customers"})
+		return
+	}
+	c.JSON(http.StatusOK, customers)
+}
+
+// GetCustomer returns a single customer
+func (h *CustomerHandler) GetCustomer(c *gin.Context) {
+	customer, err := h.Service.GetCustomer(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, customer)
+}
+
+// GetOpenSales returns a customer's sales that still have a balance
+func (h *CustomerHandler) GetOpenSales(c *gin.Context) {
+	sales, err := h.Service.GetOpenSales(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sales)
+}
+
+// AddCustomer creates a customer
+func (h *CustomerHandler) AddCustomer(c *gin.Context) {
+	var customer models.Customer
+	if err := c.ShouldBindJSON(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	customer.ID = 0
+	customer.UserID = c.GetUint("user_id")
+
+	if err := h.Service.AddCustomer(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, customer)
+}
+
+// UpdateCustomer updates a customer's details
+func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
+	var customer models.Customer
+	if err := c.ShouldBindJSON(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	customer.ID = parseUint(c.Param("id"))
+	customer.UserID = c.GetUint("user_id")
+
+	if err := h.Service.UpdateCustomer(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, customer)
+}
+
+// DeleteCustomer removes a customer without sales
+func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
+	if err := h.Service.DeleteCustomer(parseUint(c.Param("id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Customer deleted successfully"})
+}
+
+// GetAgedReceivables returns outstanding sale balances in 0-30, 31-60, 61-90 and 90+ day buckets.
+// Accepts an optional as_of (YYYY-MM-DD) query parameter; defaults to today.
+func (h *CustomerHandler) GetAgedReceivables(c *gin.Context) {
+	asOf, err := parseAsOfQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.Service.GetAgedReceivables(c.GetUint("user_id"), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute aged receivables"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -7,6 +7,7 @@ import (
 	"birdseye-backend/pkg/services"
 	"net/http"
 	"github.com/gin-gonic/gin"
+	"errors"
 	"fmt"
 )
 
@@ -35,6 +36,10 @@ func (h *EggAdjustmentHandler) GetAdjustments(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch adjustments"})
 		return
 	}
+	unit := resolveEggUnit(c, userID)
+	for i := range adjustments {
+		adjustments[i].QuantityDisplay = models.FormatEggCount(adjustments[i].Quantity, unit)
+	}
 	c.JSON(http.StatusOK, adjustments)
 }
 
@@ -48,9 +53,14 @@ func (h *EggAdjustmentHandler) AddAdjustment(c *gin.Context) {
 	adj.UserID = userID
 
 	if err := h.Service.AddAdjustment(&adj); err != nil {
+		if errors.Is(err, services.ErrInsufficientEggStock) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add adjustment"})
 		return
 	}
+	adj.QuantityDisplay = models.FormatEggCount(adj.Quantity, resolveEggUnit(c, userID))
 	c.JSON(http.StatusCreated, adj)
 }
 
@@ -67,9 +77,14 @@ func (h *EggAdjustmentHandler) UpdateAdjustment(c *gin.Context) {
 	adj.UserID = userID
 
 	if err := h.Service.UpdateAdjustment(&adj); err != nil {
+		if errors.Is(err, services.ErrInsufficientEggStock) || errors.Is(err, services.ErrAdjustmentFromReturn) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update adjustment"})
 		return
 	}
+	adj.QuantityDisplay = models.FormatEggCount(adj.Quantity, resolveEggUnit(c, userID))
 	c.JSON(http.StatusOK, adj)
 }
 
@@ -78,6 +93,10 @@ func (h *EggAdjustmentHandler) DeleteAdjustment(c *gin.Context) {
 	id := parseUint(c.Param("id"))
 
 	if err := h.Service.DeleteAdjustment(id, userID); err != nil {
+		if errors.Is(err, services.ErrAdjustmentFromReturn) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
@@ -4,6 +4,8 @@ import (
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"errors"
 	"log"
 	"net/http"
 
@@ -11,11 +13,13 @@ import (
 )
 
 // EggProductionHandler handles egg production-related requests
-type EggProductionHandler struct{}
+type EggProductionHandler struct {
+	Service *services.EggProductionService
+}
 
 // SetupEggProductionRoutes sets up the API routes with authentication middleware
 func SetupEggProductionRoutes(r *gin.Engine) {
-	handler := &EggProductionHandler{}
+	handler := &EggProductionHandler{Service: services.NewEggProductionService(db.DB)}
 
 	routes := r.Group("/egg-productions").Use(middlewares.AuthMiddleware())
 	{
@@ -23,6 +27,7 @@ func SetupEggProductionRoutes(r *gin.Engine) {
 		routes.POST("/", handler.AddEggProduction)
 		routes.PUT("/:id", handler.UpdateEggProduction)
 		routes.DELETE("/:id", handler.DeleteEggProduction)
+		routes.GET("/performance/:flock_id", handler.GetLayingPerformance)
 	}
 }
 
@@ -44,7 +49,7 @@ func (h *EggProductionHandler) GetEggProduction(c *gin.Context) {
 	}
 
 	var records []models.EggProduction
-	if err := db.DB.Table("egg_productions").
+	if err := db.DB.Table("egg_productions").Preload("Grades").
 		Select("egg_productions.*, flocks.name AS flock_name").
 		Joins("JOIN flocks ON flocks.id = egg_productions.flock_id").
 		Where("egg_productions.user_id = ?", user.ID).
@@ -54,6 +59,11 @@ func (h *EggProductionHandler) GetEggProduction(c *gin.Context) {
 		return
 	}
 
+	unit := resolveEggUnit(c, user.ID)
+	for i := range records {
+		records[i].EggsDisplay = models.FormatEggCount(records[i].EggsCollected, unit)
+	}
+
 	c.JSON(http.StatusOK, records)
 }
 
@@ -81,11 +91,16 @@ func (h *EggProductionHandler) AddEggProduction(c *gin.Context) {
 	// Assign authenticated user's ID to the record
 	record.UserID = user.ID
 
-	if err := db.DB.Create(&record).Error; err != nil {
+	if err := h.Service.AddEggProduction(&record); err != nil {
+		if errors.Is(err, models.ErrInvalidGradeLine) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		log.Println("AddEggProduction: Error creating record:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create record"})
 		return
 	}
+	record.EggsDisplay = models.FormatEggCount(record.EggsCollected, resolveEggUnit(c, user.ID))
 
 	c.JSON(http.StatusCreated, record)
 }
@@ -108,7 +123,7 @@ func (h *EggProductionHandler) UpdateEggProduction(c *gin.Context) {
 	id := c.Param("id")
 
 	var record models.EggProduction
-	if err := db.DB.Where("id = ? AND user_id = ?", id, user.ID).First(&record).Error; err != nil {
+	if err := db.DB.Preload("Grades").Where("id = ? AND user_id = ?", id, user.ID).First(&record).Error; err != nil {
 		log.Println("UpdateEggProduction: Record not found or unauthorized")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Record not found or unauthorized"})
 		return
@@ -119,11 +134,16 @@ func (h *EggProductionHandler) UpdateEggProduction(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Save(&record).Error; err != nil {
+	if err := h.Service.UpdateEggProduction(&record); err != nil {
+		if errors.Is(err, models.ErrInvalidGradeLine) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		log.Println("UpdateEggProduction: Error updating record:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update record"})
 		return
 	}
+	record.EggsDisplay = models.FormatEggCount(record.EggsCollected, resolveEggUnit(c, user.ID))
 
 	c.JSON(http.StatusOK, record)
 }
@@ -152,7 +172,7 @@ func (h *EggProductionHandler) DeleteEggProduction(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&record).Error; err != nil {
+	if err := h.Service.DeleteEggProduction(record.ID, user.ID); err != nil {
 		log.Println("DeleteEggProduction: Error deleting record:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete record"})
 		return
@@ -160,3 +180,23 @@ func (h *EggProductionHandler) DeleteEggProduction(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Record deleted successfully"})
 }
+
+// GetLayingPerformance returns weekly hen-day % and hen-housed eggs for a flock against its breed standard
+func (h *EggProductionHandler) GetLayingPerformance(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var flock models.Flock
+	if err := db.DB.Where("id = ? AND user_id = ?", c.Param("flock_id"), userID).First(&flock).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
+
+	performance, err := h.Service.GetLayingPerformance(&flock)
+	if err != nil {
+		log.Println("GetLayingPerformance: Error computing performance:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute laying performance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, performance)
+}
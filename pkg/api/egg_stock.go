@@ -0,0 +1,79 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EggStockHandler handles egg stock ledger requests
+type EggStockHandler struct {
+	Service *services.EggStockService
+}
+
+// SetupEggStockRoutes sets up the egg stock API routes
+func SetupEggStockRoutes(r *gin.Engine) {
+	handler := &EggStockHandler{Service: services.NewEggStockService(db.DB)}
+
+	routes := r.Group("/egg-stock").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetLedger)
+		routes.GET("/on-hand", handler.GetStockOnHand)
+	}
+}
+
+// GetLedger returns the daily egg stock ledger.
+// Accepts optional flock_id (all flocks when omitted), start and end (YYYY-MM-DD) query parameters; defaults to the last 30 days.
+func (h *EggStockHandler) GetLedger(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var flockID uint64
+	if id := c.Query("flock_id"); id != "" {
+		var err error
+		if flockID, err = strconv.ParseUint(id, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+			return
+		}
+	}
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, now.AddDate(0, 0, -29), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ledger, err := h.Service.GetLedger(userID, uint(flockID), start, end)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// GetStockOnHand returns eggs currently in store for the dashboard widget.
+// Counts are also shown in the optional "unit" query parameter or the user's preferred unit.
+func (h *EggStockHandler) GetStockOnHand(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	onHand, err := h.Service.GetStockOnHand(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute egg stock"})
+		return
+	}
+
+	unit := resolveEggUnit(c, userID)
+	onHand.TotalDisplay = models.FormatEggCount(onHand.TotalEggs, unit)
+	for i := range onHand.Flocks {
+		onHand.Flocks[i].Display = models.FormatEggCount(onHand.Flocks[i].Eggs, unit)
+	}
+
+	c.JSON(http.StatusOK, onHand)
+}
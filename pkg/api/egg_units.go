@@ -0,0 +1,19 @@
+package api
+
+import (
+	"birdseye-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveEggUnit picks the unit egg counts are shown in: the "unit" query parameter,
+// then the user's saved preference, then single eggs
+func resolveEggUnit(c *gin.Context, userID uint) string {
+	if unit := c.Query("unit"); models.IsValidEggUnit(unit) {
+		return unit
+	}
+	if user, err := models.GetUserByID(userID); err == nil && models.IsValidEggUnit(user.EggUnit) {
+		return user.EggUnit
+	}
+	return models.EggUnitEggs
+}
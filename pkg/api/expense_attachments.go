@@ -0,0 +1,118 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"birdseye-backend/pkg/storage"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExpenseAttachmentHandler handles receipt and invoice attachments on expenses
+type ExpenseAttachmentHandler struct {
+	Service *services.ExpenseAttachmentService
+}
+
+// SetupExpenseAttachmentRoutes sets up the expense attachment API routes
+func SetupExpenseAttachmentRoutes(r *gin.Engine) {
+	handler := &ExpenseAttachmentHandler{Service: services.NewExpenseAttachmentService(db.DB, storage.Default())}
+
+	routes := r.Group("/expenses").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/:id/attachments", handler.GetAttachments)
+		routes.POST("/:id/attachments", handler.AddAttachment)
+		routes.GET("/:id/attachments/:attachment_id", handler.DownloadAttachment)
+		routes.GET("/:id/attachments/:attachment_id/thumbnail", handler.DownloadThumbnail)
+		routes.DELETE("/:id/attachments/:attachment_id", handler.DeleteAttachment)
+	}
+}
+
+// GetAttachments lists the attachments on an expense
+func (h *ExpenseAttachmentHandler) GetAttachments(c *gin.Context) {
+	attachments, err := h.Service.GetAttachments(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// AddAttachment uploads a receipt image or PDF, sent as the multipart form field "file"
+func (h *ExpenseAttachmentHandler) AddAttachment(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attachment file required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read attachment"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.Service.AddAttachment(parseUint(c.Param("id")), c.GetUint("user_id"), header.Filename, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAttachmentInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAttachmentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload attachment"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment streams an attachment to its owner
+func (h *ExpenseAttachmentHandler) DownloadAttachment(c *gin.Context) {
+	h.serveAttachment(c, false)
+}
+
+// DownloadThumbnail streams an image attachment's thumbnail to its owner
+func (h *ExpenseAttachmentHandler) DownloadThumbnail(c *gin.Context) {
+	h.serveAttachment(c, true)
+}
+
+func (h *ExpenseAttachmentHandler) serveAttachment(c *gin.Context, thumbnail bool) {
+	attachment, file, err := h.Service.OpenAttachment(parseUint(c.Param("id")), parseUint(c.Param("attachment_id")),
+		c.GetUint("user_id"), thumbnail)
+	if err != nil {
+		if errors.Is(err, services.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open attachment"})
+		return
+	}
+	defer file.Close()
+
+	contentType, size := attachment.ContentType, attachment.Size
+	if thumbnail {
+		contentType, size = "image/jpeg", -1
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}),
+		"Cache-Control":          "private, max-age=3600",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment removes an attachment from an expense
+func (h *ExpenseAttachmentHandler) DeleteAttachment(c *gin.Context) {
+	if err := h.Service.DeleteAttachment(parseUint(c.Param("id")), parseUint(c.Param("attachment_id")), c.GetUint("user_id")); err != nil {
+		if errors.Is(err, services.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
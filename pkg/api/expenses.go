@@ -1,10 +1,14 @@
 package api
 
 import (
+	"errors"
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/models"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 "birdseye-backend/pkg/middlewares"
 	"github.com/gin-gonic/gin"
 	"birdseye-backend/pkg/services"
@@ -26,6 +30,7 @@ func SetupExpenseRoutes(r *gin.Engine, expenseService *services.ExpenseService)
 		expenseRoutes.POST("/", handler.AddExpense)
 		expenseRoutes.PUT("/:id", handler.UpdateExpense)
 		expenseRoutes.DELETE("/:id", handler.DeleteExpense)
+		expenseRoutes.POST("/allocation-preview", handler.PreviewAllocation)
 
 		// Budget-related routes
 		expenseRoutes.GET("/budget", handler.GetTotalBudget)
@@ -54,7 +59,7 @@ func (h *ExpenseHandler) GetExpenses(c *gin.Context) {
 	}
 
 	var expenses []models.Expense
-	if err := db.DB.Where("user_id = ?", user.ID).Find(&expenses).Error; err != nil {
+	if err := db.DB.Preload("Attachments").Preload("Allocations").Where("user_id = ?", user.ID).Find(&expenses).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve expenses"})
 		return
 	}
@@ -93,6 +98,10 @@ func (h *ExpenseHandler) AddExpense(c *gin.Context) {
 	err = h.Service.AddExpense(&expense) // ✅ Now it correctly calls the service
 	if err != nil {
 		log.Printf("❌ Error adding expense: %v\n", err)
+		if isExpenseInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create expense"})
 		return
 	}
@@ -120,17 +129,28 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	id := c.Param("id")
 
 	var expense models.Expense
-	if err := db.DB.Where("id = ? AND user_id = ?", id, user.ID).First(&expense).Error; err != nil {
+	if err := db.DB.Preload("Allocations").Where("id = ? AND user_id = ?", id, user.ID).First(&expense).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found or unauthorized"})
 		return
 	}
 
+	// Keep the expense's category unless the request sets one
+	category := expense.CategoryRef
+	expense.CategoryRef = models.CategoryRef{}
 	if err := c.ShouldBindJSON(&expense); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if expense.CategoryRef.IsZero() {
+		expense.CategoryRef = category
+	}
+	expense.UserID = user.ID
 
-	if err := db.DB.Save(&expense).Error; err != nil {
+	if err := h.Service.UpdateExpense(&expense); err != nil {
+		if isExpenseInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update expense"})
 		return
 	}
@@ -162,7 +182,7 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&expense).Error; err != nil {
+	if err := h.Service.DeleteExpense(expense.ID, user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete expense"})
 		return
 	}
@@ -170,56 +190,105 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Expense deleted successfully"})
 }
 
+// PreviewAllocation shows how a farm-level expense would be split across flocks without saving it
+func (h *ExpenseHandler) PreviewAllocation(c *gin.Context) {
+	var expense models.Expense
+	if err := c.ShouldBindJSON(&expense); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expense.UserID = c.GetUint("user_id")
 
-func (h *ExpenseHandler) GetTotalBudget(c *gin.Context) {
-	log.Println("GET /expenses/budget called")
-
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	allocations, err := h.Service.PreviewAllocation(&expense)
+	if err != nil {
+		if errors.Is(err, services.ErrExpenseAllocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to allocate expense"})
 		return
 	}
+	c.JSON(http.StatusOK, allocations)
+}
+
+// isExpenseInputError reports whether an expense could not be saved because of the request rather than a server fault
+func isExpenseInputError(err error) bool {
+	return errors.Is(err, services.ErrExpenseAllocation) ||
+		errors.Is(err, services.ErrUnknownCategory)
+}
 
-	var totalBudget float64
-	err := db.DB.Model(&models.Expense{}).
-		Where("user_id = ?", userID).
-		Select("COALESCE(SUM(budget), 0)").
-		Scan(&totalBudget).Error
+// GetTotalBudget returns the user's total flock budget for a month, with actual spend against it.
+// The month defaults to the current one.
+func (h *ExpenseHandler) GetTotalBudget(c *gin.Context) {
+	log.Println("GET /expenses/budget called")
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	year, month := now.Year(), int(now.Month())
+	var err error
+	if value := c.Query("year"); value != "" {
+		if year, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+	}
+	if value := c.Query("month"); value != "" {
+		if month, err = strconv.Atoi(value); err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return
+		}
+	}
 
+	totalBudget, actual, err := services.NewBudgetService(db.DB).GetMonthTotals(userID, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch total budget"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"total_budget": totalBudget})
+	percentUsed := 0.0
+	if totalBudget > 0 {
+		percentUsed = math.Round(actual/totalBudget*10000) / 100
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"month":        month,
+		"year":         year,
+		"total_budget": totalBudget,
+		"actual":       actual,
+		"variance":     math.Round((totalBudget-actual)*100) / 100,
+		"percent_used": percentUsed,
+	})
 }
+
+// UpdateBudget sets a flock's overall budget for a month, the current month by default
 func (h *ExpenseHandler) UpdateBudget(c *gin.Context) {
 	log.Println("PUT /expenses/budget called")
-
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+	userID := c.GetUint("user_id")
 
 	var budgetUpdate struct {
-		FlockID uint    `json:"flock_id"`
+		FlockID uint    `json:"flock_id" binding:"required"`
 		Budget  float64 `json:"budget"`
+		Month   int     `json:"month"`
+		Year    int     `json:"year"`
 	}
 
 	if err := c.ShouldBindJSON(&budgetUpdate); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
 		return
 	}
+	now := time.Now()
+	if budgetUpdate.Month == 0 {
+		budgetUpdate.Month = int(now.Month())
+	}
+	if budgetUpdate.Year == 0 {
+		budgetUpdate.Year = now.Year()
+	}
 
-	err := db.DB.Model(&models.Expense{}).
-		Where("flock_id = ? AND user_id = ?", budgetUpdate.FlockID, userID).
-		Update("budget", budgetUpdate.Budget).Error
-
+	budget, err := services.NewBudgetService(db.DB).SetFlockBudget(userID, budgetUpdate.FlockID,
+		budgetUpdate.Year, budgetUpdate.Month, budgetUpdate.Budget)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Budget updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Budget updated successfully", "budget": budget})
 }
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedHandler handles feed log and feed efficiency requests for a flock
+type FeedHandler struct {
+	Service *services.FeedService
+}
+
+// SetupFeedRoutes sets up the feed API routes nested under a flock
+func SetupFeedRoutes(r *gin.Engine) {
+	handler := &FeedHandler{Service: services.NewFeedService(db.DB)}
+
+	feedRoutes := r.Group("/flocks/:id/feed").Use(middlewares.AuthMiddleware())
+	{
+		feedRoutes.GET("/logs", handler.GetFeedLogs)
+		feedRoutes.POST("/logs", handler.AddFeedLog)
+		feedRoutes.PUT("/logs/:log_id", handler.UpdateFeedLog)
+		feedRoutes.DELETE("/logs/:log_id", handler.DeleteFeedLog)
+		feedRoutes.GET("/analytics", handler.GetFeedAnalytics)
+	}
+}
+
+// GetFeedLogs lists feed logs recorded for a flock
+func (h *FeedHandler) GetFeedLogs(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	flockID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+		return
+	}
+
+	logs, err := h.Service.GetFeedLogs(uint(flockID), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve feed logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
+// AddFeedLog records feed given to a flock and deducts it from inventory
+func (h *FeedHandler) AddFeedLog(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	flockID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+		return
+	}
+
+	var feedLog models.FeedLog
+	if err := c.ShouldBindJSON(&feedLog); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	feedLog.UserID = userID
+	feedLog.FlockID = uint(flockID)
+
+	if err := h.Service.AddFeedLog(&feedLog); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feedLog)
+}
+
+// UpdateFeedLog edits a feed log and rebalances inventory
+func (h *FeedHandler) UpdateFeedLog(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	logID, err := strconv.Atoi(c.Param("log_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed log ID"})
+		return
+	}
+
+	var updated models.FeedLog
+	if err := c.ShouldBindJSON(&updated); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feedLog, err := h.Service.UpdateFeedLog(uint(logID), userID, &updated)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feedLog)
+}
+
+// DeleteFeedLog removes a feed log and returns its feed to inventory
+func (h *FeedHandler) DeleteFeedLog(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	logID, err := strconv.Atoi(c.Param("log_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feed log ID"})
+		return
+	}
+
+	if err := h.Service.DeleteFeedLog(uint(logID), userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feed log deleted successfully"})
+}
+
+// GetFeedAnalytics returns FCR and feed per bird per day for a flock.
+// Accepts optional start, end (YYYY-MM-DD) and egg_weight_kg query parameters; defaults to the last 30 days.
+func (h *FeedHandler) GetFeedAnalytics(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	flockID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+		return
+	}
+
+	var flock models.Flock
+	if err := db.DB.Where("id = ? AND user_id = ?", flockID, userID).First(&flock).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, now.AddDate(0, 0, -29), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	eggWeightKg := services.DefaultEggWeightKg
+	if w := c.Query("egg_weight_kg"); w != "" {
+		if eggWeightKg, err = strconv.ParseFloat(w, 64); err != nil || eggWeightKg <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid egg_weight_kg"})
+			return
+		}
+	}
+
+	analytics, err := h.Service.GetFeedAnalytics(&flock, start, end, eggWeightKg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute feed analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
@@ -4,9 +4,12 @@ import (
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/middlewares"
 	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +27,7 @@ func SetupFlockFinancialRoutes(r *gin.Engine) {
 		financialRoutes.GET("/flock/:flockID", handler.GetFinancialDataByFlock)
 		financialRoutes.POST("/", handler.AddOrUpdateFinancialData)
 		financialRoutes.PUT("/", handler.UpdateFinancialData)
+		financialRoutes.POST("/rebuild", handler.RebuildFinancialData)
 		financialRoutes.DELETE("/flock/:flockID", handler.DeleteFinancialData)
 	}
 }
@@ -153,3 +157,36 @@ func (h *FlockFinancialHandler) DeleteFinancialData(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Financial data deleted successfully"})
 }
+
+// RebuildFinancialData recomputes the authenticated user's monthly financial data from their sales and
+// expenses. Query parameters: start and end (YYYY-MM-DD, default the current year to date) and flock_id.
+func (h *FlockFinancialHandler) RebuildFinancialData(c *gin.Context) {
+	log.Println("POST /flock-financial/rebuild called")
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.Local), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var flockID uint
+	if value := c.Query("flock_id"); value != "" {
+		if flockID = parseUint(value); flockID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+			return
+		}
+	}
+
+	rebuilt, err := services.NewFinancialAggregationService(db.DB).Rebuild(userID, flockID, start, end)
+	if errors.Is(err, services.ErrFlockNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rebuilt)
+}
@@ -160,11 +160,13 @@ func (h *FlockHandler) UpdateFlock(c *gin.Context) {
         return
     }
 
-    // Bind JSON data to the existing flock
+    // Bind JSON data to the existing flock. Bird count and mortality come from the mortality log.
+    birdCount, mortalityRate, mortalityRateData := flock.BirdCount, flock.MortalityRate, flock.MortalityRateData
     if err := c.ShouldBindJSON(&flock); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
+    flock.BirdCount, flock.MortalityRate, flock.MortalityRateData = birdCount, mortalityRate, mortalityRateData
 
     if err := h.Service.UpdateFlock(flock); err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update flock"})
@@ -1,28 +1,43 @@
 package api
 
 import (
+	"birdseye-backend/pkg/broadcast"
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // InventoryHandler handles inventory-related requests
-type InventoryHandler struct{}
+type InventoryHandler struct {
+	Service *services.InventoryService
+}
 
 // SetupInventoryRoutes sets up the inventory API routes with authentication middleware
 func SetupInventoryRoutes(r *gin.Engine) {
-	handler := &InventoryHandler{}
+	handler := &InventoryHandler{Service: services.NewInventoryService(db.DB)}
 
 	inventoryRoutes := r.Group("/inventory").Use(middlewares.AuthMiddleware())
 	{
 		inventoryRoutes.GET("/", handler.GetInventory)
+		inventoryRoutes.GET("/low-stock", handler.GetLowStock)
+		inventoryRoutes.GET("/valuation", handler.GetValuation)
 		inventoryRoutes.POST("/", handler.AddInventoryItem)
 		inventoryRoutes.PUT("/:id", handler.UpdateInventoryItem)
 		inventoryRoutes.DELETE("/:id", handler.DeleteInventoryItem)
+		inventoryRoutes.GET("/:id/movements", handler.GetMovements)
+		inventoryRoutes.POST("/:id/movements", handler.AddMovement)
+		inventoryRoutes.GET("/lots/expiring", handler.GetExpiringLots)
+		inventoryRoutes.GET("/:id/lots", handler.GetLots)
+		inventoryRoutes.POST("/:id/lots", handler.AddLot)
+		inventoryRoutes.PUT("/:id/lots/:lot_id", handler.UpdateLot)
 	}
 }
 // GetInventory retrieves inventory records for the authenticated user, including flock names
@@ -101,7 +116,7 @@ func (h *InventoryHandler) AddInventoryItem(c *gin.Context) {
 
 	item.UserID = user.ID // Use user.ID as uint
 
-	if err := db.DB.Create(&item).Error; err != nil {
+	if err := h.Service.AddInventoryItem(&item); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create inventory item"})
 		return
 	}
@@ -147,9 +162,11 @@ func (h *InventoryHandler) UpdateInventoryItem(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	item.ID, item.UserID = parseUint(id), user.ID
 
-	if err := db.DB.Save(&item).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory item"})
+	if err := h.Service.UpdateInventoryItem(&item); err != nil {
+		log.Println("UpdateInventoryItem: Error updating item:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -190,10 +207,135 @@ func (h *InventoryHandler) DeleteInventoryItem(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&item).Error; err != nil {
+	if err := h.Service.DeleteInventoryItem(item.ID, user.ID); err != nil {
+		if errors.Is(err, services.ErrItemHasHistory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete inventory item"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Inventory item deleted successfully"})
 }
+
+// GetMovements returns the stock movement history of an inventory item.
+// Accepts optional start and end (YYYY-MM-DD) query parameters; defaults to the last 90 days.
+func (h *InventoryHandler) GetMovements(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory item ID"})
+		return
+	}
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, now.AddDate(0, 0, -89), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movements, err := h.Service.GetMovements(uint(itemID), userID, start, end.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, movements)
+}
+
+// AddMovement records a purchase, usage, wastage, transfer or correction against an inventory item.
+// A transfer moves stock to to_item_id and takes a positive quantity.
+func (h *InventoryHandler) AddMovement(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory item ID"})
+		return
+	}
+
+	var request struct {
+		models.InventoryMovement
+		ToItemID uint `json:"to_item_id"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Type == models.MovementTransfer {
+		if request.ToItemID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to_item_id is required for transfers"})
+			return
+		}
+		items, err := h.Service.TransferStock(userID, uint(itemID), request.ToItemID, request.QuantityDelta, request.Reference, request.Notes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, item := range items {
+			broadcast.SendInventoryUpdate(userID, "inventory_updated", *item)
+			h.Service.CheckLowStock(item)
+		}
+		c.JSON(http.StatusCreated, items)
+		return
+	}
+
+	movement := request.InventoryMovement
+	movement.ID = 0
+	movement.UserID = userID
+	movement.InventoryItemID = uint(itemID)
+
+	// Purchases always add stock; usage and wastage always remove it
+	switch movement.Type {
+	case models.MovementPurchase:
+		if movement.QuantityDelta < 0 {
+			movement.QuantityDelta = -movement.QuantityDelta
+		}
+	case models.MovementUsage, models.MovementWastage:
+		if movement.QuantityDelta > 0 {
+			movement.QuantityDelta = -movement.QuantityDelta
+		}
+	}
+
+	item, err := h.Service.RecordMovement(&movement)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	broadcast.SendInventoryUpdate(userID, "inventory_updated", *item)
+	h.Service.CheckLowStock(item)
+
+	c.JSON(http.StatusCreated, movement)
+}
+
+// GetLowStock lists items at or below their reorder level with projected days until stock-out
+func (h *InventoryHandler) GetLowStock(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	items, err := h.Service.GetLowStockItems(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve low stock items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// GetValuation values the user's stock under their costing method.
+// Accepts an optional as_of (YYYY-MM-DD) query parameter; defaults to today.
+func (h *InventoryHandler) GetValuation(c *gin.Context) {
+	asOf, err := parseAsOfQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	valuation, err := h.Service.GetValuation(c.GetUint("user_id"), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to value inventory"})
+		return
+	}
+	c.JSON(http.StatusOK, valuation)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lotRequest is the body for receiving stock into a lot or correcting a lot. Dates are YYYY-MM-DD.
+type lotRequest struct {
+	BatchNumber  string  `json:"batch_number"`
+	ExpiryDate   string  `json:"expiry_date"`
+	ReceivedDate string  `json:"received_date"`
+	Quantity     int     `json:"quantity"`
+	UnitCost     float64 `json:"unit_cost"`
+	Reference    string  `json:"reference"`
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, returning the zero time for an empty string
+func parseOptionalDate(value, field string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, expected YYYY-MM-DD", field)
+	}
+	return parsed, nil
+}
+
+// GetLots lists an item's lots in FEFO order. Pass include_empty=true to include used-up lots.
+func (h *InventoryHandler) GetLots(c *gin.Context) {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory item ID"})
+		return
+	}
+
+	lots, err := h.Service.GetLots(uint(itemID), c.GetUint("user_id"), c.Query("include_empty") == "true")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lots)
+}
+
+// AddLot receives stock into a batch of an inventory item, creating the lot if the batch number is new
+func (h *InventoryHandler) AddLot(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inventory item ID"})
+		return
+	}
+
+	var request lotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expiry, err := parseOptionalDate(request.ExpiryDate, "expiry_date")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	received, err := parseOptionalDate(request.ReceivedDate, "received_date")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lot := models.InventoryLot{
+		UserID:          userID,
+		InventoryItemID: uint(itemID),
+		BatchNumber:     request.BatchNumber,
+		ExpiryDate:      expiry,
+		ReceivedDate:    received,
+		UnitCost:        request.UnitCost,
+	}
+	item, err := h.Service.AddLot(&lot, request.Quantity, request.Reference)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	broadcast.SendInventoryUpdate(userID, "inventory_updated", *item)
+	h.Service.CheckLowStock(item)
+
+	c.JSON(http.StatusCreated, lot)
+}
+
+// UpdateLot corrects a lot's batch number or expiry date
+func (h *InventoryHandler) UpdateLot(c *gin.Context) {
+	lotID, err := strconv.Atoi(c.Param("lot_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lot ID"})
+		return
+	}
+
+	var request lotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expiry, err := parseOptionalDate(request.ExpiryDate, "expiry_date")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lot, err := h.Service.UpdateLot(uint(lotID), c.GetUint("user_id"), request.BatchNumber, expiry)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lot)
+}
+
+// GetExpiringLots lists lots with stock expiring within the "days" query parameter,
+// defaulting to the user's expiry warning window
+func (h *InventoryHandler) GetExpiringLots(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	days := models.DefaultExpiryWarningDays
+	if user, err := services.GetUserByID(userID); err == nil && user.ExpiryWarningDays > 0 {
+		days = user.ExpiryWarningDays
+	}
+	if d := c.Query("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+			return
+		}
+		days = parsed
+	}
+
+	lots, err := h.Service.GetExpiringLots(userID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve expiring lots"})
+		return
+	}
+	c.JSON(http.StatusOK, lots)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/reports"
+	"birdseye-backend/pkg/services/email"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+// invoiceRequest selects the sales for a customer invoice and, when emailing, who receives it
+type invoiceRequest struct {
+	SaleIDs []uint `json:"sale_ids"`
+	Email   string `json:"email"` // defaults to the customer's email
+}
+
+// DownloadInvoice returns the invoice or receipt PDF for a single sale
+func (h *SalesHandler) DownloadInvoice(c *gin.Context) {
+	pdfPath, _, err := reports.GenerateSalesInvoice(db.DB, c.GetUint("user_id"), []uint{parseUint(c.Param("id"))})
+	if err != nil {
+		c.JSON(invoiceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.FileAttachment(pdfPath, filepath.Base(pdfPath))
+}
+
+// EmailInvoice emails the invoice or receipt PDF for a single sale
+func (h *SalesHandler) EmailInvoice(c *gin.Context) {
+	var req invoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sendInvoice(c, []uint{parseUint(c.Param("id"))}, req.Email)
+}
+
+// DownloadInvoice returns one invoice PDF covering several of a customer's sales.
+// With no sale_ids it covers all of the customer's sales that still have a balance.
+func (h *CustomerHandler) DownloadInvoice(c *gin.Context) {
+	req, ok := h.bindCustomerInvoice(c)
+	if !ok {
+		return
+	}
+
+	pdfPath, _, err := reports.GenerateSalesInvoice(db.DB, c.GetUint("user_id"), req.SaleIDs)
+	if err != nil {
+		c.JSON(invoiceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.FileAttachment(pdfPath, filepath.Base(pdfPath))
+}
+
+// EmailInvoice emails one invoice PDF covering several of a customer's sales
+func (h *CustomerHandler) EmailInvoice(c *gin.Context) {
+	req, ok := h.bindCustomerInvoice(c)
+	if !ok {
+		return
+	}
+	sendInvoice(c, req.SaleIDs, req.Email)
+}
+
+// bindCustomerInvoice reads a customer invoice request, filling in the customer's open sales when none are
+// given and checking any that are given belong to the customer
+func (h *CustomerHandler) bindCustomerInvoice(c *gin.Context) (invoiceRequest, bool) {
+	var req invoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+
+	customerID := parseUint(c.Param("id"))
+	userID := c.GetUint("user_id")
+	if len(req.SaleIDs) == 0 {
+		sales, err := h.Service.GetOpenSales(customerID, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return req, false
+		}
+		if len(sales) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "customer has no outstanding sales"})
+			return req, false
+		}
+		for _, sale := range sales {
+			req.SaleIDs = append(req.SaleIDs, sale.ID)
+		}
+		return req, true
+	}
+
+	var customerSales []uint
+	if err := db.DB.Model(&models.Sale{}).
+		Where("id IN ? AND user_id = ? AND customer_id = ?", req.SaleIDs, userID, customerID).
+		Pluck("id", &customerSales).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sales"})
+		return req, false
+	}
+	belongs := make(map[uint]bool, len(customerSales))
+	for _, id := range customerSales {
+		belongs[id] = true
+	}
+	for _, id := range req.SaleIDs {
+		if !belongs[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "all sales must belong to this customer"})
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// sendInvoice generates the invoice for saleIDs and emails it to the given address or the customer
+func sendInvoice(c *gin.Context, saleIDs []uint, to string) {
+	pdfPath, data, err := reports.GenerateSalesInvoice(db.DB, c.GetUint("user_id"), saleIDs)
+	if err != nil {
+		c.JSON(invoiceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if to == "" {
+		to = data.CustomerEmail
+	}
+	if to == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no email address given and the customer has none on file"})
+		return
+	}
+
+	if err := email.SendSaleInvoiceEmail(to, data.CustomerName, data.FarmName, data.Title, data.DocumentNo, data.Balance, pdfPath); err != nil {
+		log.Println("Failed to email invoice:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send email"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": data.Title + " sent to " + to, "document_no": data.DocumentNo})
+}
+
+func invoiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, reports.ErrInvoiceSaleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, reports.ErrInvoiceNoSales), errors.Is(err, reports.ErrInvoiceMixedCustomers):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
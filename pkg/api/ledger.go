@@ -0,0 +1,153 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LedgerHandler handles general ledger requests
+type LedgerHandler struct {
+	Service *services.LedgerService
+}
+
+// SetupLedgerRoutes sets up the general ledger API routes
+func SetupLedgerRoutes(r *gin.Engine) {
+	handler := &LedgerHandler{Service: services.NewLedgerService(db.DB)}
+
+	routes := r.Group("/ledger").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/accounts", handler.GetAccounts)
+		routes.GET("/entries", handler.GetEntries)
+		routes.GET("/trial-balance", handler.GetTrialBalance)
+		routes.GET("/balance-sheet", handler.GetBalanceSheet)
+		routes.GET("/profit-loss", handler.GetProfitAndLoss)
+		routes.POST("/rebuild", handler.Rebuild)
+	}
+}
+
+// GetAccounts returns the chart of accounts
+func (h *LedgerHandler) GetAccounts(c *gin.Context) {
+	accounts, err := h.Service.GetAccounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetEntries returns journal entries with their lines.
+// Query parameters: start and end (YYYY-MM-DD, default the current month to date) and account_id.
+func (h *LedgerHandler) GetEntries(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var accountID uint
+	if value := c.Query("account_id"); value != "" {
+		if accountID = parseUint(value); accountID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+			return
+		}
+	}
+
+	entries, err := h.Service.GetEntries(userID, accountID, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve journal entries"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetTrialBalance returns every account's debits, credits and balance. Query parameter: as_of (YYYY-MM-DD, default today).
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	asOf, err := parseAsOfQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trial, err := h.Service.GetTrialBalance(userID, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute trial balance"})
+		return
+	}
+	c.JSON(http.StatusOK, trial)
+}
+
+// GetBalanceSheet returns assets, liabilities and equity. Query parameter: as_of (YYYY-MM-DD, default today).
+func (h *LedgerHandler) GetBalanceSheet(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	asOf, err := parseAsOfQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sheet, err := h.Service.GetBalanceSheet(userID, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance sheet"})
+		return
+	}
+	c.JSON(http.StatusOK, sheet)
+}
+
+// GetProfitAndLoss returns revenue and expenses read from the ledger.
+// Query parameters: start and end (YYYY-MM-DD, default the current month to date) and flock_id.
+func (h *LedgerHandler) GetProfitAndLoss(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var flockID uint
+	if value := c.Query("flock_id"); value != "" {
+		if flockID = parseUint(value); flockID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+			return
+		}
+	}
+
+	statement, err := h.Service.GetProfitAndLoss(userID, flockID, start, end)
+	if errors.Is(err, services.ErrFlockNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute profit and loss"})
+		return
+	}
+	c.JSON(http.StatusOK, statement)
+}
+
+// Rebuild posts the authenticated user's journal entries again from their sales, expenses and stock movements
+func (h *LedgerHandler) Rebuild(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.Service.Rebuild(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rebuild ledger", "details": err.Error()})
+		return
+	}
+
+	trial, err := h.Service.GetTrialBalance(userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute trial balance"})
+		return
+	}
+	c.JSON(http.StatusOK, trial)
+}
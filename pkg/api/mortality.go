@@ -0,0 +1,193 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MortalityHandler handles per-event mortality requests for a flock
+type MortalityHandler struct {
+	Service *services.MortalityService
+}
+
+// SetupMortalityRoutes sets up the mortality API routes nested under a flock
+func SetupMortalityRoutes(r *gin.Engine) {
+	handler := &MortalityHandler{Service: services.NewMortalityService(db.DB)}
+
+	mortalityRoutes := r.Group("/flocks/:id/mortality").Use(middlewares.AuthMiddleware())
+	{
+		mortalityRoutes.GET("/", handler.GetMortalityEvents)
+		mortalityRoutes.GET("/curve", handler.GetMortalityCurve)
+		mortalityRoutes.POST("/", handler.AddMortalityEvent)
+		mortalityRoutes.PUT("/:event_id", handler.UpdateMortalityEvent)
+		mortalityRoutes.DELETE("/:event_id", handler.DeleteMortalityEvent)
+	}
+}
+
+// GetMortalityEvents lists mortality events recorded for a flock
+func (h *MortalityHandler) GetMortalityEvents(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	flockID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+		return
+	}
+
+	events, err := h.Service.GetMortalityEvents(uint(flockID), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mortality events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetMortalityCurve returns the daily and cumulative mortality curve for a flock.
+// Optional start and end query parameters use the YYYY-MM-DD format.
+func (h *MortalityHandler) GetMortalityCurve(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	flockID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+		return
+	}
+
+	var flock models.Flock
+	if err := db.DB.Where("id = ? AND user_id = ?", flockID, userID).First(&flock).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
+
+	start, end, err := parseDateRangeQuery(c, flock.CreatedAt, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	points, err := h.Service.GetMortalityCurve(&flock, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute mortality curve"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flock_id":       flock.ID,
+		"initial_birds":  flock.InitialBirdCount,
+		"bird_count":     flock.BirdCount,
+		"mortality_rate": flock.MortalityRate,
+		"curve":          points,
+	})
+}
+
+// AddMortalityEvent records a mortality event and decrements the flock's bird count
+func (h *MortalityHandler) AddMortalityEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	flockID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+		return
+	}
+
+	var event models.MortalityEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	event.UserID = userID
+	event.FlockID = uint(flockID)
+
+	if err := h.Service.AddMortalityEvent(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// UpdateMortalityEvent edits a mortality event and adjusts the flock's bird count
+func (h *MortalityHandler) UpdateMortalityEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	eventID, err := strconv.Atoi(c.Param("event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var updated models.MortalityEvent
+	if err := c.ShouldBindJSON(&updated); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := h.Service.UpdateMortalityEvent(uint(eventID), userID, &updated)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// DeleteMortalityEvent removes a mortality event and restores the flock's bird count
+func (h *MortalityHandler) DeleteMortalityEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	eventID, err := strconv.Atoi(c.Param("event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if err := h.Service.DeleteMortalityEvent(uint(eventID), userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mortality event deleted successfully"})
+}
+
+// parseDateRangeQuery reads optional start and end query parameters (YYYY-MM-DD),
+// falling back to the given defaults when they are absent
+func parseDateRangeQuery(c *gin.Context, defaultStart, defaultEnd time.Time) (time.Time, time.Time, error) {
+	start, end := defaultStart, defaultEnd
+
+	if s := c.Query("start"); s != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			return start, end, errors.New("invalid start date, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+	if e := c.Query("end"); e != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", e, time.Local)
+		if err != nil {
+			return start, end, errors.New("invalid end date, expected YYYY-MM-DD")
+		}
+		end = parsed
+	}
+	if end.Before(start) {
+		return start, end, errors.New("end date must not be before start date")
+	}
+
+	return start, end, nil
+}
+
+// parseAsOfQuery reads an optional as_of (YYYY-MM-DD) query parameter, defaulting to now
+func parseAsOfQuery(c *gin.Context) (time.Time, error) {
+	d := c.Query("as_of")
+	if d == "" {
+		return time.Now(), nil
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", d, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("invalid as_of date, expected YYYY-MM-DD")
+	}
+	return parsed, nil
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceListHandler handles price list requests
+type PriceListHandler struct {
+	Service *services.PriceListService
+}
+
+// SetupPriceListRoutes sets up the price list API routes
+func SetupPriceListRoutes(r *gin.Engine) {
+	handler := &PriceListHandler{Service: services.NewPriceListService(db.DB)}
+
+	routes := r.Group("/price-list").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetItems)
+		routes.GET("/resolve", handler.ResolvePrice)
+		routes.GET("/:id", handler.GetItem)
+		routes.POST("/", handler.AddItem)
+		routes.PUT("/:id", handler.UpdateItem)
+		routes.DELETE("/:id", handler.DeleteItem)
+	}
+}
+
+// GetItems returns the user's price list, optionally filtered by a "product" query parameter
+func (h *PriceListHandler) GetItems(c *gin.Context) {
+	items, err := h.Service.GetItems(c.GetUint("user_id"), c.Query("product"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve price list"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetItem returns a single price list item
+func (h *PriceListHandler) GetItem(c *gin.Context) {
+	item, err := h.Service.GetItem(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// AddItem adds a price to the price list
+func (h *PriceListHandler) AddItem(c *gin.Context) {
+	var item models.PriceListItem
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item.ID = 0
+	item.UserID = c.GetUint("user_id")
+
+	if err := h.Service.AddItem(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItem updates a price, e.g. to set the date it ends
+func (h *PriceListHandler) UpdateItem(c *gin.Context) {
+	var item models.PriceListItem
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item.ID = parseUint(c.Param("id"))
+	item.UserID = c.GetUint("user_id")
+
+	if err := h.Service.UpdateItem(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteItem removes a price from the price list
+func (h *PriceListHandler) DeleteItem(c *gin.Context) {
+	if err := h.Service.DeleteItem(parseUint(c.Param("id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Price deleted successfully"})
+}
+
+// ResolvePrice returns the price that would be filled in for a product.
+// Accepts product (required), grade, customer_id and date (YYYY-MM-DD, defaults to today) query parameters.
+func (h *PriceListHandler) ResolvePrice(c *gin.Context) {
+	product := c.Query("product")
+	if product == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "product is required"})
+		return
+	}
+	date, err := parseOptionalDate(c.Query("date"), "date")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var customerID *uint
+	if id := parseUint(c.Query("customer_id")); id != 0 {
+		customerID = &id
+	}
+
+	price, err := h.Service.Resolve(c.GetUint("user_id"), customerID, product, c.Query("grade"), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve price"})
+		return
+	}
+	if price == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no price on the price list for this product and date"})
+		return
+	}
+	c.JSON(http.StatusOK, price)
+}
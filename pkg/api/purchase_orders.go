@@ -0,0 +1,147 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderHandler handles purchase order requests
+type PurchaseOrderHandler struct {
+	Service *services.PurchaseOrderService
+}
+
+// SetupPurchaseOrderRoutes sets up the purchase order API routes
+func SetupPurchaseOrderRoutes(r *gin.Engine) {
+	handler := &PurchaseOrderHandler{Service: services.NewPurchaseOrderService(db.DB)}
+
+	routes := r.Group("/purchase-orders").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetPurchaseOrders)
+		routes.GET("/:id", handler.GetPurchaseOrder)
+		routes.POST("/", handler.CreatePurchaseOrder)
+		routes.PUT("/:id", handler.UpdatePurchaseOrder)
+		routes.PUT("/:id/status", handler.UpdateStatus)
+		routes.POST("/:id/receive", handler.ReceivePurchaseOrder)
+		routes.DELETE("/:id", handler.DeletePurchaseOrder)
+	}
+}
+
+// GetPurchaseOrders lists purchase orders, optionally filtered by "status" and "supplier_id" query parameters
+func (h *PurchaseOrderHandler) GetPurchaseOrders(c *gin.Context) {
+	orders, err := h.Service.GetPurchaseOrders(c.GetUint("user_id"), c.Query("status"), parseUint(c.Query("supplier_id")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve purchase orders"})
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// GetPurchaseOrder returns a single purchase order with its lines
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+	order, err := h.Service.GetPurchaseOrder(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// CreatePurchaseOrder creates a draft or ordered purchase order
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var order models.PurchaseOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order.ID = 0
+	order.UserID = c.GetUint("user_id")
+	order.Supplier = nil
+
+	if err := h.Service.CreatePurchaseOrder(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// UpdatePurchaseOrder replaces a purchase order's details and lines before it is received
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(c *gin.Context) {
+	var order models.PurchaseOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order.ID = parseUint(c.Param("id"))
+	order.UserID = c.GetUint("user_id")
+	order.Supplier = nil
+
+	if err := h.Service.UpdatePurchaseOrder(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// UpdateStatus marks a purchase order as ordered or cancelled
+func (h *PurchaseOrderHandler) UpdateStatus(c *gin.Context) {
+	var req struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.Service.UpdateStatus(parseUint(c.Param("id")), c.GetUint("user_id"), req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// ReceivePurchaseOrder receives an order into inventory and records its expenses.
+// Accepts an optional JSON body with received_date (YYYY-MM-DD); defaults to today.
+func (h *PurchaseOrderHandler) ReceivePurchaseOrder(c *gin.Context) {
+	var req struct {
+		ReceivedDate string `json:"received_date"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var receivedDate time.Time
+	if req.ReceivedDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.ReceivedDate, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid received_date, expected YYYY-MM-DD"})
+			return
+		}
+		receivedDate = parsed
+	}
+
+	order, err := h.Service.ReceivePurchaseOrder(parseUint(c.Param("id")), c.GetUint("user_id"), receivedDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// DeletePurchaseOrder removes a purchase order that has not been received
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(c *gin.Context) {
+	if err := h.Service.DeletePurchaseOrder(parseUint(c.Param("id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Purchase order deleted successfully"})
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecurringExpenseHandler handles recurring expense requests
+type RecurringExpenseHandler struct {
+	Service *services.RecurringExpenseService
+}
+
+// SetupRecurringExpenseRoutes sets up the recurring expense API routes
+func SetupRecurringExpenseRoutes(r *gin.Engine) {
+	handler := &RecurringExpenseHandler{Service: services.NewRecurringExpenseService(db.DB)}
+
+	routes := r.Group("/recurring-expenses").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetRecurringExpenses)
+		routes.GET("/:id", handler.GetRecurringExpense)
+		routes.GET("/:id/upcoming", handler.GetUpcoming)
+		routes.POST("/", handler.AddRecurringExpense)
+		routes.PUT("/:id", handler.UpdateRecurringExpense)
+		routes.PUT("/:id/pause", handler.PauseRecurringExpense)
+		routes.PUT("/:id/resume", handler.ResumeRecurringExpense)
+		routes.POST("/:id/exceptions", handler.SetException)
+		routes.DELETE("/:id/exceptions/:exception_id", handler.DeleteException)
+		routes.DELETE("/:id", handler.DeleteRecurringExpense)
+	}
+}
+
+// GetRecurringExpenses returns the user's recurring expenses
+func (h *RecurringExpenseHandler) GetRecurringExpenses(c *gin.Context) {
+	templates, err := h.Service.GetRecurringExpenses(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recurring expenses"})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetRecurringExpense returns a single recurring expense
+func (h *RecurringExpenseHandler) GetRecurringExpense(c *gin.Context) {
+	template, err := h.Service.GetRecurringExpense(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// GetUpcoming lists a recurring expense's next occurrences. Accepts an optional "count" query parameter (default 12).
+func (h *RecurringExpenseHandler) GetUpcoming(c *gin.Context) {
+	count := 12
+	if v := c.Query("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 366 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "count must be between 1 and 366"})
+			return
+		}
+		count = n
+	}
+
+	occurrences, err := h.Service.GetUpcoming(parseUint(c.Param("id")), c.GetUint("user_id"), count)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, occurrences)
+}
+
+// AddRecurringExpense creates a recurring expense; occurrences already due are posted straight away
+func (h *RecurringExpenseHandler) AddRecurringExpense(c *gin.Context) {
+	var template models.RecurringExpense
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template.ID = 0
+	template.UserID = c.GetUint("user_id")
+	template.Exceptions = nil
+
+	if err := h.Service.AddRecurringExpense(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateRecurringExpense edits a recurring expense; expenses already posted are not changed
+func (h *RecurringExpenseHandler) UpdateRecurringExpense(c *gin.Context) {
+	var template models.RecurringExpense
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	template.ID = parseUint(c.Param("id"))
+	template.UserID = c.GetUint("user_id")
+
+	if err := h.Service.UpdateRecurringExpense(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// PauseRecurringExpense stops a recurring expense from posting
+func (h *RecurringExpenseHandler) PauseRecurringExpense(c *gin.Context) {
+	template, err := h.Service.PauseRecurringExpense(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// ResumeRecurringExpense restarts a paused recurring expense from today
+func (h *RecurringExpenseHandler) ResumeRecurringExpense(c *gin.Context) {
+	template, err := h.Service.ResumeRecurringExpense(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// SetException skips an upcoming occurrence or changes its amount or description
+func (h *RecurringExpenseHandler) SetException(c *gin.Context) {
+	var exception models.RecurringExpenseException
+	if err := c.ShouldBindJSON(&exception); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.SetException(parseUint(c.Param("id")), c.GetUint("user_id"), &exception); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, exception)
+}
+
+// DeleteException restores an occurrence to the recurring expense's schedule and amount
+func (h *RecurringExpenseHandler) DeleteException(c *gin.Context) {
+	if err := h.Service.DeleteException(parseUint(c.Param("id")), parseUint(c.Param("exception_id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Occurrence restored"})
+}
+
+// DeleteRecurringExpense removes a recurring expense, keeping the expenses it posted
+func (h *RecurringExpenseHandler) DeleteRecurringExpense(c *gin.Context) {
+	if err := h.Service.DeleteRecurringExpense(parseUint(c.Param("id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring expense deleted successfully"})
+}
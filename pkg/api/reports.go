@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/services"
 	"net/http"
+	"strconv"
 	
 	"github.com/gin-gonic/gin"
 	"birdseye-backend/pkg/middlewares"
@@ -33,6 +36,11 @@ func SetupReportsRoutes(r *gin.Engine) {
 		reportsRoutes.POST("/inventory", handler.GenerateInventoryReport)
 		reportsRoutes.POST("/flock", handler.GenerateFlockReport)          // Existing route
 		reportsRoutes.POST("/financial", handler.GenerateFinancialReport)  // New route
+		reportsRoutes.POST("/supplier-spend", handler.GenerateSupplierSpendReport)
+		reportsRoutes.GET("/profit-loss", handler.GetProfitAndLoss)
+		reportsRoutes.POST("/profit-loss", handler.GenerateProfitAndLossReport)
+		reportsRoutes.GET("/cash-flow", handler.GetCashFlow)
+		reportsRoutes.POST("/cash-flow", handler.GenerateCashFlowReport)
 		reportsRoutes.DELETE("/:reportID", handler.DeleteReport)
 
 	}
@@ -376,6 +384,276 @@ func (h *ReportsHandler) GenerateFlockReport(c *gin.Context) {
 	c.File(pdfPath)
 }
 
+func (h *ReportsHandler) GenerateSupplierSpendReport(c *gin.Context) {
+	// Get userID from authentication middleware
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authorized"})
+		return
+	}
+
+	// Convert userID to uint
+	authUserID, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Parse request parameters
+	var request struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		UserID    uint   `json:"user_id"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	// Ensure the request user matches the authenticated user
+	if request.UserID != authUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized user ID"})
+		return
+	}
+
+	// Convert string dates from ISO 8601 to `time.Time`
+	startDate, err := time.Parse(time.RFC3339, request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		return
+	}
+
+	endDate, err := time.Parse(time.RFC3339, request.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+		return
+	}
+
+	// Generate the supplier spend report
+	pdfPath, err := reports.GenerateSupplierSpendReport(db.DB, authUserID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report", "details": err.Error()})
+		return
+	}
+
+	// Send the file as response
+	c.File(pdfPath)
+}
+
+// GetProfitAndLoss returns a profit and loss statement as JSON.
+// Query parameters: start and end (YYYY-MM-DD, default the current month to date) and flock_id.
+func (h *ReportsHandler) GetProfitAndLoss(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var flockID uint
+	if value := c.Query("flock_id"); value != "" {
+		if flockID = parseUint(value); flockID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
+			return
+		}
+	}
+
+	statement, err := services.NewProfitAndLossService(db.DB).GetProfitAndLoss(userID, flockID, start, end)
+	if err != nil {
+		if errors.Is(err, services.ErrFlockNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute profit and loss"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *ReportsHandler) GenerateProfitAndLossReport(c *gin.Context) {
+	// Get userID from authentication middleware
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authorized"})
+		return
+	}
+
+	// Convert userID to uint
+	authUserID, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Parse request parameters; flock_id is optional and covers the whole farm when left out
+	var request struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		UserID    uint   `json:"user_id"`
+		FlockID   uint   `json:"flock_id"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	// Ensure the request user matches the authenticated user
+	if request.UserID != authUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized user ID"})
+		return
+	}
+
+	// Convert string dates from ISO 8601 to `time.Time`
+	startDate, err := time.Parse(time.RFC3339, request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		return
+	}
+
+	endDate, err := time.Parse(time.RFC3339, request.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+		return
+	}
+
+	// Generate the profit and loss report
+	pdfPath, err := reports.GenerateProfitAndLossReport(db.DB, authUserID, request.FlockID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report", "details": err.Error()})
+		return
+	}
+
+	// Send the file as response
+	c.File(pdfPath)
+}
+
+// defaultCashFlowHorizon is the number of periods projected when no horizon is given
+const defaultCashFlowHorizon = 3
+
+// GetCashFlow returns a cash flow statement as JSON.
+// Query parameters: start and end (YYYY-MM-DD, default the year to date), interval (week or month,
+// default month) and horizon (0 to 12 projected periods, default 3).
+func (h *ReportsHandler) GetCashFlow(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.Local), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, horizon, err := parseCashFlowOptions(c.Query("interval"), c.Query("horizon"), start)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statement, err := services.NewCashFlowService(db.DB).GetCashFlow(userID, start, end, interval, horizon)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute cash flow"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *ReportsHandler) GenerateCashFlowReport(c *gin.Context) {
+	// Get userID from authentication middleware
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authorized"})
+		return
+	}
+
+	// Convert userID to uint
+	authUserID, ok := userID.(uint)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Parse request parameters; interval and horizon are optional
+	var request struct {
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		UserID    uint   `json:"user_id"`
+		Interval  string `json:"interval"`
+		Horizon   *int   `json:"horizon"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	// Ensure the request user matches the authenticated user
+	if request.UserID != authUserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized user ID"})
+		return
+	}
+
+	// Convert string dates from ISO 8601 to `time.Time`
+	startDate, err := time.Parse(time.RFC3339, request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		return
+	}
+
+	endDate, err := time.Parse(time.RFC3339, request.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+		return
+	}
+
+	horizon := ""
+	if request.Horizon != nil {
+		horizon = strconv.Itoa(*request.Horizon)
+	}
+	interval, horizonPeriods, err := parseCashFlowOptions(request.Interval, horizon, startDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Generate the cash flow report
+	pdfPath, err := reports.GenerateCashFlowReport(db.DB, authUserID, startDate, endDate, interval, horizonPeriods)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate report", "details": err.Error()})
+		return
+	}
+
+	// Send the file as response
+	c.File(pdfPath)
+}
+
+// parseCashFlowOptions validates a cash flow interval and projection horizon, applying their defaults,
+// and checks the statement does not start in the future
+func parseCashFlowOptions(interval, horizon string, start time.Time) (string, int, error) {
+	if interval == "" {
+		interval = models.CashFlowMonthly
+	}
+	if interval != models.CashFlowWeekly && interval != models.CashFlowMonthly {
+		return "", 0, errors.New("interval must be week or month")
+	}
+
+	periods := defaultCashFlowHorizon
+	if horizon != "" {
+		var err error
+		if periods, err = strconv.Atoi(horizon); err != nil || periods < 0 || periods > 12 {
+			return "", 0, errors.New("horizon must be between 0 and 12")
+		}
+	}
+
+	if start.After(time.Now()) {
+		return "", 0, errors.New("start date must not be in the future")
+	}
+	return interval, periods, nil
+}
+
 func (h *ReportsHandler) GenerateFinancialReport(c *gin.Context) {
 	// Get userID from authentication middleware
 	userID, exists := c.Get("user_id")
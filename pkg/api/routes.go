@@ -42,6 +42,7 @@ func SetupRoutes(router *gin.Engine) {
 		protected.PUT("/update-profile", handleUpdateProfile)
 		protected.POST("/update-profile-picture", handleUpdateProfilePicture)
 		protected.PUT("/change-password", handleChangePassword)
+		protected.PUT("/preferences", handleUpdatePreferences)
 
 		// Admin Public routes
 		auth.POST("/admin/register", handleAdminRegistration)
@@ -394,6 +395,10 @@ func handleGetUserProfile(c *gin.Context) {
 		"trial_ends_at":   user.ComputeTrialEndsAt(),
 		"is_trial_active": user.ComputeIsTrialActive(),
 		"email_verified": user.EmailVerified,
+		"egg_unit":       user.EggUnit,
+		"low_stock_emails": user.LowStockEmails,
+		"expiry_warning_days": user.ExpiryWarningDays,
+		"costing_method": user.CostingMethod,
 	},
 })
 
@@ -436,6 +441,34 @@ func handleUpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"user": updatedUser})
 }
 
+// Handle updating preferences such as the default egg unit, alert emails, the expiry warning window and costing method
+func handleUpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var request services.PreferencesUpdate
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	user, err := services.UpdateUserPreferences(userID.(uint), request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"egg_unit":            user.EggUnit,
+		"low_stock_emails":    user.LowStockEmails,
+		"expiry_warning_days": user.ExpiryWarningDays,
+		"costing_method":      user.CostingMethod,
+	})
+}
+
 // Handle updating profile picture
 func handleUpdateProfilePicture(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"birdseye-backend/pkg/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReturns lists the user's credit notes.
+// Accepts optional start and end (YYYY-MM-DD) query parameters; defaults to the current year to date.
+func (h *SalesHandler) GetReturns(c *gin.Context) {
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	returns, err := h.Service.GetReturns(c.GetUint("user_id"), start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sales returns"})
+		return
+	}
+	c.JSON(http.StatusOK, returns)
+}
+
+// GetSaleReturns lists the credit notes raised against a sale
+func (h *SalesHandler) GetSaleReturns(c *gin.Context) {
+	returns, err := h.Service.GetSaleReturns(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, returns)
+}
+
+// AddReturn raises a credit note against a sale and returns it with the updated sale
+func (h *SalesHandler) AddReturn(c *gin.Context) {
+	var ret models.SaleReturn
+	if err := c.ShouldBindJSON(&ret); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ret.SaleID = parseUint(c.Param("id"))
+	ret.UserID = c.GetUint("user_id")
+
+	sale, err := h.Service.AddReturn(&ret)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"return": ret, "sale": sale})
+}
+
+// DeleteReturn voids a credit note raised in error and returns the updated sale
+func (h *SalesHandler) DeleteReturn(c *gin.Context) {
+	sale, err := h.Service.DeleteReturn(parseUint(c.Param("id")), parseUint(c.Param("return_id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sale)
+}
@@ -4,6 +4,8 @@ import (
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"errors"
 	"log"
 	"net/http"
 
@@ -11,19 +13,30 @@ import (
 )
 
 // SalesHandler handles sales-related requests
-type SalesHandler struct{}
+type SalesHandler struct {
+	Service *services.SalesService
+}
 
 // SetupSalesRoutes sets up the sales API routes with authentication middleware
 func SetupSalesRoutes(r *gin.Engine) {
-	handler := &SalesHandler{}
+	handler := &SalesHandler{Service: services.NewSalesService(db.DB)}
 
 	salesRoutes := r.Group("/sales").Use(middlewares.AuthMiddleware())
 	{
 		salesRoutes.GET("/", handler.GetSales)
 		salesRoutes.GET("/flock/:flockID", handler.GetSalesByFlock)
+		salesRoutes.GET("/returns", handler.GetReturns)
 		salesRoutes.POST("/", handler.AddSale)
 		salesRoutes.PUT("/:id", handler.UpdateSale)
 		salesRoutes.DELETE("/:id", handler.DeleteSale)
+		salesRoutes.GET("/:id/payments", handler.GetPayments)
+		salesRoutes.POST("/:id/payments", handler.AddPayment)
+		salesRoutes.DELETE("/:id/payments/:payment_id", handler.DeletePayment)
+		salesRoutes.GET("/:id/returns", handler.GetSaleReturns)
+		salesRoutes.POST("/:id/returns", handler.AddReturn)
+		salesRoutes.DELETE("/:id/returns/:return_id", handler.DeleteReturn)
+		salesRoutes.GET("/:id/invoice", handler.DownloadInvoice)
+		salesRoutes.POST("/:id/invoice/email", handler.EmailInvoice)
 	}
 }
 
@@ -52,11 +65,12 @@ func (h *SalesHandler) GetSales(c *gin.Context) {
 	}
 
 	var sales []models.Sale
-	if err := db.DB.Where("user_id = ?", user.ID).Find(&sales).Error; err != nil {
+	if err := db.DB.Preload("Grades").Preload("Customer").Preload("Payments").Where("user_id = ?", user.ID).Find(&sales).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sales"})
 		return
 	}
 
+	setSaleQuantityDisplay(sales, resolveEggUnit(c, user.ID))
 	c.JSON(http.StatusOK, sales)
 }
 
@@ -86,11 +100,12 @@ func (h *SalesHandler) GetSalesByFlock(c *gin.Context) {
 
 	flockID := c.Param("flockID")
 	var sales []models.Sale
-	if err := db.DB.Where("flock_id = ? AND user_id = ?", flockID, user.ID).Find(&sales).Error; err != nil {
+	if err := db.DB.Preload("Grades").Preload("Customer").Preload("Payments").Where("flock_id = ? AND user_id = ?", flockID, user.ID).Find(&sales).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sales for flock"})
 		return
 	}
 
+	setSaleQuantityDisplay(sales, resolveEggUnit(c, user.ID))
 	c.JSON(http.StatusOK, sales)
 }
 
@@ -123,10 +138,15 @@ func (h *SalesHandler) AddSale(c *gin.Context) {
 	}
 
 	sale.UserID = user.ID
-	if err := db.DB.Create(&sale).Error; err != nil {
+	if err := h.Service.AddSale(&sale); err != nil {
+		if isSaleInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create sale"})
 		return
 	}
+	sale.QuantityDisplay = saleQuantityDisplay(&sale, resolveEggUnit(c, user.ID), eggSalesCategoryID())
 
 	c.JSON(http.StatusCreated, sale)
 }
@@ -155,20 +175,31 @@ func (h *SalesHandler) UpdateSale(c *gin.Context) {
 
 	id := c.Param("id")
 	var sale models.Sale
-	if err := db.DB.Where("id = ? AND user_id = ?", id, user.ID).First(&sale).Error; err != nil {
+	if err := db.DB.Preload("Grades").Where("id = ? AND user_id = ?", id, user.ID).First(&sale).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Sale not found or unauthorized"})
 		return
 	}
 
+	// Keep the sale's category unless the request sets one
+	category := sale.CategoryRef
+	sale.CategoryRef = models.CategoryRef{}
 	if err := c.ShouldBindJSON(&sale); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if sale.CategoryRef.IsZero() {
+		sale.CategoryRef = category
+	}
 
-	if err := db.DB.Save(&sale).Error; err != nil {
+	if err := h.Service.UpdateSale(&sale); err != nil {
+		if isSaleInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sale"})
 		return
 	}
+	sale.QuantityDisplay = saleQuantityDisplay(&sale, resolveEggUnit(c, user.ID), eggSalesCategoryID())
 
 	c.JSON(http.StatusOK, sale)
 }
@@ -202,10 +233,84 @@ func (h *SalesHandler) DeleteSale(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&sale).Error; err != nil {
+	if err := h.Service.DeleteSale(sale.ID, user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete sale"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Sale deleted successfully"})
 }
+
+// GetPayments lists the payments recorded against a sale
+func (h *SalesHandler) GetPayments(c *gin.Context) {
+	payments, err := h.Service.GetPayments(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, payments)
+}
+
+// AddPayment records an instalment against a sale and returns the updated sale
+func (h *SalesHandler) AddPayment(c *gin.Context) {
+	var payment models.SalePayment
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payment.ID = 0
+	payment.SaleID = parseUint(c.Param("id"))
+	payment.UserID = c.GetUint("user_id")
+
+	sale, err := h.Service.AddPayment(&payment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"payment": payment, "sale": sale})
+}
+
+// DeletePayment removes a payment from a sale and returns the updated sale
+func (h *SalesHandler) DeletePayment(c *gin.Context) {
+	sale, err := h.Service.DeletePayment(parseUint(c.Param("id")), parseUint(c.Param("payment_id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sale)
+}
+
+// isSaleInputError reports whether a sale could not be saved because of the request rather than a server fault
+func isSaleInputError(err error) bool {
+	return errors.Is(err, services.ErrInsufficientEggStock) ||
+		errors.Is(err, models.ErrInvalidGradeLine) ||
+		errors.Is(err, services.ErrSaleCustomerNotFound) ||
+		errors.Is(err, services.ErrSaleBelowAmountPaid) ||
+		errors.Is(err, services.ErrSaleBelowReturned) ||
+		errors.Is(err, services.ErrUnknownCategory)
+}
+
+// setSaleQuantityDisplay fills QuantityDisplay for egg sales in the given unit
+func setSaleQuantityDisplay(sales []models.Sale, unit string) {
+	eggSalesID := eggSalesCategoryID()
+	for i := range sales {
+		sales[i].QuantityDisplay = saleQuantityDisplay(&sales[i], unit, eggSalesID)
+	}
+}
+
+func saleQuantityDisplay(sale *models.Sale, unit string, eggSalesID uint) string {
+	if eggSalesID == 0 || sale.CategoryID != eggSalesID {
+		return ""
+	}
+	return models.FormatEggCount(sale.Quantity, unit)
+}
+
+// eggSalesCategoryID returns the Egg Sales category ID, or 0 if it can't be loaded
+func eggSalesCategoryID() uint {
+	id, err := services.EggSalesCategoryID(db.DB)
+	if err != nil {
+		log.Printf("Error loading the egg sales category: %v", err)
+		return 0
+	}
+	return id
+}
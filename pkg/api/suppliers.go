@@ -0,0 +1,113 @@
+package api
+
+import (
+	"birdseye-backend/pkg/db"
+	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler handles supplier directory requests
+type SupplierHandler struct {
+	Service *services.SupplierService
+}
+
+// SetupSupplierRoutes sets up the supplier API routes
+func SetupSupplierRoutes(r *gin.Engine) {
+	handler := &SupplierHandler{Service: services.NewSupplierService(db.DB)}
+
+	routes := r.Group("/suppliers").Use(middlewares.AuthMiddleware())
+	{
+		routes.GET("/", handler.GetSuppliers)
+		routes.GET("/spend", handler.GetSupplierSpend)
+		routes.GET("/:id", handler.GetSupplier)
+		routes.POST("/", handler.AddSupplier)
+		routes.PUT("/:id", handler.UpdateSupplier)
+		routes.DELETE("/:id", handler.DeleteSupplier)
+	}
+}
+
+// GetSuppliers returns the user's suppliers
+func (h *SupplierHandler) GetSuppliers(c *gin.Context) {
+	suppliers, err := h.Service.GetSuppliers(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve suppliers"})
+		return
+	}
+	c.JSON(http.StatusOK, suppliers)
+}
+
+// GetSupplier returns a single supplier
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	supplier, err := h.Service.GetSupplier(parseUint(c.Param("id")), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, supplier)
+}
+
+// AddSupplier creates a supplier
+func (h *SupplierHandler) AddSupplier(c *gin.Context) {
+	var supplier models.Supplier
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	supplier.ID = 0
+	supplier.UserID = c.GetUint("user_id")
+
+	if err := h.Service.AddSupplier(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, supplier)
+}
+
+// UpdateSupplier updates a supplier's details
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	var supplier models.Supplier
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	supplier.ID = parseUint(c.Param("id"))
+	supplier.UserID = c.GetUint("user_id")
+
+	if err := h.Service.UpdateSupplier(&supplier); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, supplier)
+}
+
+// DeleteSupplier removes a supplier without purchase orders
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	if err := h.Service.DeleteSupplier(parseUint(c.Param("id")), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Supplier deleted successfully"})
+}
+
+// GetSupplierSpend returns spend per supplier.
+// Accepts optional start and end (YYYY-MM-DD) query parameters; defaults to the current year to date.
+func (h *SupplierHandler) GetSupplierSpend(c *gin.Context) {
+	now := time.Now()
+	start, end, err := parseDateRangeQuery(c, time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	spend, err := h.Service.GetSupplierSpend(c.GetUint("user_id"), start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute supplier spend"})
+		return
+	}
+	c.JSON(http.StatusOK, spend)
+}
@@ -1,15 +1,19 @@
 package api
 
 import (
+	"birdseye-backend/pkg/broadcast"
 	"birdseye-backend/pkg/db"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/middlewares"
+	"birdseye-backend/pkg/services"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type VaccinationHandler struct{}
@@ -50,7 +54,7 @@ func (h *VaccinationHandler) GetVaccinationsByUserID(c *gin.Context) {
 
 	// Join vaccinations with flocks to ensure only this user's records are fetched
 	var vaccinations []models.Vaccination
-	if err := db.DB.
+	if err := db.DB.Preload("InventoryLot").
 		Joins("JOIN flocks ON flocks.id = vaccinations.flock_id").
 		Where("flocks.user_id = ?", user.ID).
 		Find(&vaccinations).Error; err != nil {
@@ -68,9 +72,13 @@ func (h *VaccinationHandler) GetVaccinations(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
 		return
 	}
+	if !ownsFlock(c.GetUint("user_id"), uint(id)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
 
 	var vaccinations []models.Vaccination
-	if err := db.DB.Where("flock_id = ?", id).Find(&vaccinations).Error; err != nil {
+	if err := db.DB.Preload("InventoryLot").Where("flock_id = ?", id).Find(&vaccinations).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve vaccinations"})
 		return
 	}
@@ -84,6 +92,11 @@ func (h *VaccinationHandler) AddVaccination(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flock ID"})
 		return
 	}
+	userID := c.GetUint("user_id")
+	if !ownsFlock(userID, uint(id)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
 
 	var rawData map[string]interface{}
 	if err := c.ShouldBindJSON(&rawData); err != nil {
@@ -108,12 +121,7 @@ func (h *VaccinationHandler) AddVaccination(c *gin.Context) {
 		return
 	}
 
-	if userID, ok := rawData["user_id"].(float64); ok {
-		vaccination.UserID = uint(userID)
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid 'user_id'"})
-		return
-	}
+	vaccination.UserID = userID
 
 	// New: handle mode_of_administration (optional)
 	if mode, ok := rawData["mode_of_administration"].(string); ok {
@@ -133,15 +141,64 @@ func (h *VaccinationHandler) AddVaccination(c *gin.Context) {
 	vaccination.Date = parsedDate
 	vaccination.FlockID = uint(id)
 
-	result := db.DB.Debug().Create(&vaccination)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add vaccination", "details": result.Error.Error()})
+	// Optional: the vaccine lot used and how many doses it supplied
+	if lotID, ok := rawData["inventory_lot_id"].(float64); ok && lotID > 0 {
+		lotRef := uint(lotID)
+		if _, err := services.NewInventoryService(db.DB).GetLot(lotRef, vaccination.UserID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		vaccination.InventoryLotID = &lotRef
+	}
+	if doses, ok := rawData["doses_used"].(float64); ok {
+		vaccination.DosesUsed = int(doses)
+	}
+
+	var items []*models.InventoryItem
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&vaccination).Error; err != nil {
+			return err
+		}
+		var err error
+		items, err = services.NewVaccinationService(tx).SyncLotUsage(&vaccination, false)
+		return err
+	})
+	if err != nil {
+		if isStockError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add vaccination", "details": err.Error()})
 		return
 	}
+	notifyLotUsage(userID, items)
 
 	c.JSON(http.StatusCreated, vaccination)
 }
 
+// notifyLotUsage broadcasts the inventory items a vaccination drew or returned doses on and checks them for low stock
+func notifyLotUsage(userID uint, items []*models.InventoryItem) {
+	inventory := services.NewInventoryService(db.DB)
+	for _, item := range items {
+		broadcast.SendInventoryUpdate(userID, "inventory_updated", *item)
+		inventory.CheckLowStock(item)
+	}
+}
+
+// ownsFlock reports whether the flock belongs to the user
+func ownsFlock(userID, flockID uint) bool {
+	var count int64
+	if err := db.DB.Model(&models.Flock{}).Where("id = ? AND user_id = ?", flockID, userID).Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// isStockError reports whether a vaccination's lot could not supply its doses
+func isStockError(err error) bool {
+	return errors.Is(err, services.ErrInsufficientStock) || errors.Is(err, services.ErrLotExpired)
+}
+
 func (h *VaccinationHandler) UpdateVaccination(c *gin.Context) {
 	id, err1 := strconv.Atoi(c.Param("id"))
 	vaccinationID, err2 := strconv.Atoi(c.Param("vaccination_id"))
@@ -150,6 +207,11 @@ func (h *VaccinationHandler) UpdateVaccination(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
+	userID := c.GetUint("user_id")
+	if !ownsFlock(userID, uint(id)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
 
 	var vaccination models.Vaccination
 	if err := db.DB.Where("id = ? AND flock_id = ?", vaccinationID, id).First(&vaccination).Error; err != nil {
@@ -164,16 +226,46 @@ func (h *VaccinationHandler) UpdateVaccination(c *gin.Context) {
 	}
 
 	// No change needed here for mode_of_administration as it will be updated if included in updatedData map
+	// The record's identity and owner are not editable
+	for _, key := range []string{"id", "flock_id", "user_id"} {
+		delete(updatedData, key)
+	}
 
-	query := db.DB.Model(&vaccination)
-	if _, exists := updatedData["date"]; !exists {
-		query = query.Omit("date")
+	// A referenced vaccine lot must belong to the user
+	if lotID, ok := updatedData["inventory_lot_id"].(float64); ok && lotID > 0 {
+		if _, err := services.NewInventoryService(db.DB).GetLot(uint(lotID), userID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
 
-	if err := query.Updates(updatedData).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update vaccination"})
+	var items []*models.InventoryItem
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&vaccination)
+		if _, exists := updatedData["date"]; !exists {
+			query = query.Omit("date")
+		}
+		if err := query.Updates(updatedData).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&vaccination, vaccination.ID).Error; err != nil {
+			return err
+		}
+
+		// Marking the vaccination done draws its doses from the referenced lot; other changes repost them
+		var err error
+		items, err = services.NewVaccinationService(tx).SyncLotUsage(&vaccination, false)
+		return err
+	})
+	if err != nil {
+		if isStockError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update vaccination", "details": err.Error()})
 		return
 	}
+	notifyLotUsage(userID, items)
 
 	c.JSON(http.StatusOK, vaccination)
 }
@@ -186,6 +278,11 @@ func (h *VaccinationHandler) DeleteVaccination(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
+	userID := c.GetUint("user_id")
+	if !ownsFlock(userID, uint(id)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flock not found"})
+		return
+	}
 
 	var vaccination models.Vaccination
 	if err := db.DB.Where("id = ? AND flock_id = ?", vaccinationID, id).First(&vaccination).Error; err != nil {
@@ -193,10 +290,20 @@ func (h *VaccinationHandler) DeleteVaccination(c *gin.Context) {
 		return
 	}
 
-	if err := db.DB.Delete(&vaccination).Error; err != nil {
+	// Doses the vaccination drew go back to their lot
+	var items []*models.InventoryItem
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if items, err = services.NewVaccinationService(tx).SyncLotUsage(&vaccination, true); err != nil {
+			return err
+		}
+		return tx.Delete(&vaccination).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete vaccination"})
 		return
 	}
+	notifyLotUsage(userID, items)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Vaccination deleted successfully"})
 }
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"birdseye-backend/pkg/db"
+	"log"
+	"math"
+)
+
+// BreedStandard is one week of a breed's published laying curve
+type BreedStandard struct {
+	ID            uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Breed         string  `json:"breed" gorm:"type:varchar(100);not null;uniqueIndex:idx_breed_week"`
+	AgeWeeks      int     `json:"age_weeks" gorm:"not null;uniqueIndex:idx_breed_week"`
+	HenDayPercent float64 `json:"hen_day_percent" gorm:"not null"`
+	HenHousedEggs float64 `json:"hen_housed_eggs" gorm:"not null"` // cumulative eggs per hen housed
+}
+
+// LayingPerformanceWeek compares a flock's actual production with its breed standard for one week of age
+type LayingPerformanceWeek struct {
+	AgeWeeks              int      `json:"age_weeks"`
+	WeekStart             string   `json:"week_start"`
+	WeekEnd               string   `json:"week_end"`
+	EggsCollected         int      `json:"eggs_collected"`
+	HenDays               int      `json:"hen_days"`
+	HenDayPercent         float64  `json:"hen_day_percent"`
+	HenHousedEggs         float64  `json:"hen_housed_eggs"` // cumulative eggs per hen housed to the end of the week
+	StandardHenDayPercent *float64 `json:"standard_hen_day_percent"`
+	StandardHenHousedEggs *float64 `json:"standard_hen_housed_eggs"`
+	HenDayVariance        *float64 `json:"hen_day_variance"` // actual minus standard, in percentage points
+	Underperforming       bool     `json:"underperforming"`
+}
+
+// LayingPerformance is the actual vs standard laying curve for a flock
+type LayingPerformance struct {
+	FlockID       uint                    `json:"flock_id"`
+	Breed         string                  `json:"breed"`
+	StandardBreed string                  `json:"standard_breed,omitempty"` // empty when no standard matches the flock's breed
+	Weeks         []LayingPerformanceWeek `json:"weeks"`
+}
+
+// breedCurvePoints holds key points (week of age -> hen-day %) from breed management guides.
+// Weeks in between are interpolated when seeding.
+var breedCurvePoints = map[string][][2]float64{
+	"ISA Brown": {
+		{18, 10}, {19, 30}, {20, 60}, {21, 80}, {22, 90}, {23, 93}, {24, 94}, {26, 95},
+		{30, 95}, {40, 93}, {50, 90}, {60, 86}, {70, 82}, {80, 78},
+	},
+	"Lohmann Brown": {
+		{18, 8}, {19, 25}, {20, 55}, {21, 78}, {22, 88}, {23, 92}, {25, 94},
+		{30, 94}, {40, 92}, {50, 89}, {60, 85}, {70, 81}, {80, 77},
+	},
+	"Kuroiler": {
+		{20, 5}, {22, 20}, {24, 40}, {26, 55}, {28, 60}, {32, 62},
+		{40, 58}, {50, 52}, {60, 45}, {72, 38},
+	},
+}
+
+// BreedAliases map other common spellings of a breed, in lower case, to the breed of its standard
+var BreedAliases = map[string]string{
+	"isa":           "ISA Brown",
+	"isa-brown":     "ISA Brown",
+	"isabrown":      "ISA Brown",
+	"lohmann":       "Lohmann Brown",
+	"lohmann-brown": "Lohmann Brown",
+	"lohman brown":  "Lohmann Brown",
+	"kuroilers":     "Kuroiler",
+}
+
+// SeedBreedStandards inserts the built-in laying curves for breeds that have none yet
+func SeedBreedStandards() error {
+	for breed, points := range breedCurvePoints {
+		var count int64
+		if err := db.DB.Model(&BreedStandard{}).Where("breed = ?", breed).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		var rows []BreedStandard
+		cumulative := 0.0
+		for i := 0; i < len(points)-1; i++ {
+			from, to := points[i], points[i+1]
+			for week := int(from[0]); week < int(to[0]) || (i == len(points)-2 && week == int(to[0])); week++ {
+				henDay := from[1] + (to[1]-from[1])*(float64(week)-from[0])/(to[0]-from[0])
+				henDay = math.Round(henDay*10) / 10
+				cumulative += henDay * 7 / 100
+				rows = append(rows, BreedStandard{
+					Breed:         breed,
+					AgeWeeks:      week,
+					HenDayPercent: henDay,
+					HenHousedEggs: math.Round(cumulative*10) / 10,
+				})
+			}
+		}
+
+		if err := db.DB.Create(&rows).Error; err != nil {
+			return err
+		}
+		log.Printf("Seeded %d breed standard weeks for %s", len(rows), breed)
+	}
+	return nil
+}
@@ -15,6 +15,41 @@ type Budget struct {
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
-	
+	// Optional expense category the budget covers; nil budgets cover all of the flock's spend
+	CategoryID *uint `json:"category_id,omitempty" gorm:"index"`
+	// Highest alert threshold (80 or 100 percent) already notified for this budget
+	AlertLevel int `json:"alert_level" gorm:"not null;default:0"`
+
 	Flock Flock `json:"flock" gorm:"foreignKey:FlockID"`
 }
+
+// Budget alert thresholds, as percentages of the budget spent
+const (
+	BudgetWarningPercent  = 80
+	BudgetExceededPercent = 100
+)
+
+// BudgetVariance compares a flock's budget for a month with what it actually spent. Actual is purchase
+// based: stock is counted when it is bought, not when it is used, so it can differ from the usage based
+// expenses in FlocksFinancialData and the profit and loss statement.
+type BudgetVariance struct {
+	FlockID     uint                     `json:"flock_id"`
+	FlockName   string                   `json:"flock_name"`
+	Month       int                      `json:"month"`
+	Year        int                      `json:"year"`
+	Budget      float64                  `json:"budget"`
+	Actual      float64                  `json:"actual"`       // expenses dated in the month, stock purchases included
+	Variance    float64                  `json:"variance"`     // budget less actual, negative when overspent
+	PercentUsed float64                  `json:"percent_used"` // 0 when there is no budget
+	Categories  []BudgetCategoryVariance `json:"categories,omitempty"`
+}
+
+// BudgetCategoryVariance is one expense category's share of a BudgetVariance
+type BudgetCategoryVariance struct {
+	CategoryID  uint    `json:"category_id"`
+	Category    string  `json:"category"`
+	Budget      float64 `json:"budget"`
+	Actual      float64 `json:"actual"`
+	Variance    float64 `json:"variance"`
+	PercentUsed float64 `json:"percent_used"`
+}
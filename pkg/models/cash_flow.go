@@ -0,0 +1,39 @@
+package models
+
+// Cash flow period lengths
+const (
+	CashFlowWeekly  = "week" // Monday to Sunday
+	CashFlowMonthly = "month"
+)
+
+// CashFlowPeriod is the cash received and paid out in one week or month. Revenue is what was earned in
+// the period, sales net of credit notes, whether or not it has been collected.
+type CashFlowPeriod struct {
+	Start       string  `json:"start"`
+	End         string  `json:"end"`
+	Projected   bool    `json:"projected"`
+	OpeningCash float64 `json:"opening_cash"`
+	Receipts    float64 `json:"receipts"` // sale payments; projected periods use credit sales falling due
+	Refunds     float64 `json:"refunds"`  // credit notes paid back on overpaid sales
+	Expenses    float64 `json:"expenses"` // projected periods use recurring expenses
+	Inflows     float64 `json:"inflows"`
+	Outflows    float64 `json:"outflows"`
+	NetCashFlow float64 `json:"net_cash_flow"`
+	ClosingCash float64 `json:"closing_cash"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// CashFlow is a cash flow statement by week or month. Opening cash is the net of every receipt and
+// payment recorded before the start date. Projection continues from today's closing cash.
+type CashFlow struct {
+	Interval    string           `json:"interval"`
+	Start       string           `json:"start"`
+	End         string           `json:"end"`
+	OpeningCash float64          `json:"opening_cash"`
+	Inflows     float64          `json:"inflows"`
+	Outflows    float64          `json:"outflows"`
+	ClosingCash float64          `json:"closing_cash"`
+	Revenue     float64          `json:"revenue"`
+	Periods     []CashFlowPeriod `json:"periods"`
+	Projection  []CashFlowPeriod `json:"projection"` // empty unless the statement runs to today
+}
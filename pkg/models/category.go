@@ -0,0 +1,174 @@
+package models
+
+import (
+	"birdseye-backend/pkg/db"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Category kinds
+const (
+	CategoryKindExpense = "expense"
+	CategoryKindSale    = "sale"
+)
+
+// OtherCategory is the system category that collects values with no better match when free-text
+// categories are migrated
+const OtherCategory = "Other"
+
+// systemCategories are the default top-level categories shared by every user
+var systemCategories = map[string][]string{
+	CategoryKindExpense: {"Feed", "Vaccines", "Labour", "Utilities", PurchaseExpenseCategory, OtherCategory},
+	CategoryKindSale:    {EggSalesCategory, "Bird Sales", "Manure", OtherCategory},
+}
+
+// categoryAliases map common free-text spellings to system categories when existing records are migrated
+var categoryAliases = map[string]string{
+	"labor":        "Labour",
+	"wages":        "Labour",
+	"salaries":     "Labour",
+	"vaccine":      "Vaccines",
+	"vaccination":  "Vaccines",
+	"vaccinations": "Vaccines",
+	"utility":      "Utilities",
+	"electricity":  "Utilities",
+	"water":        "Utilities",
+	"feeds":        "Feed",
+	"egg sale":     EggSalesCategory,
+	"eggs":         EggSalesCategory,
+	"bird sale":    "Bird Sales",
+	"birds":        "Bird Sales",
+	"broilers":     "Bird Sales",
+	"culls":        "Bird Sales",
+}
+
+// Category is a managed expense or sale category. System categories have no UserID and are shared by
+// every user; users add their own subcategories beneath them.
+type Category struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    *uint     `json:"user_id,omitempty" gorm:"index"` // nil for system categories
+	Kind      string    `json:"kind" gorm:"type:varchar(20);not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null"`
+	ParentID  *uint     `json:"parent_id,omitempty" gorm:"index"` // set for subcategories
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Subcategories []Category `json:"subcategories,omitempty" gorm:"foreignKey:ParentID"`
+}
+
+// CategoryRef is the category of an expense, sale or recurring expense: a system category and optionally
+// one of the user's subcategories beneath it. Names are stored with the IDs so records read as before.
+type CategoryRef struct {
+	CategoryID    uint   `json:"category_id" gorm:"index;not null;default:0"`
+	Category      string `json:"category" gorm:"type:varchar(50);not null"`
+	SubcategoryID *uint  `json:"subcategory_id,omitempty" gorm:"index"`
+	Subcategory   string `json:"subcategory,omitempty" gorm:"type:varchar(50)"`
+}
+
+// IsZero reports whether no category was given
+func (r CategoryRef) IsZero() bool {
+	return r.CategoryID == 0 && r.Category == "" && r.SubcategoryID == nil && r.Subcategory == ""
+}
+
+// SeedCategories creates any missing system categories
+func SeedCategories() error {
+	for kind, names := range systemCategories {
+		for _, name := range names {
+			var count int64
+			if err := db.DB.Model(&Category{}).Where("user_id IS NULL AND parent_id IS NULL AND kind = ? AND name = ?", kind, name).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if err := db.DB.Create(&Category{Kind: kind, Name: name}).Error; err != nil {
+				return err
+			}
+			log.Printf("Seeded %s category %s", kind, name)
+		}
+	}
+	return nil
+}
+
+// MigrateCategoryReferences links existing expenses, sales and recurring expenses to managed categories.
+// Free-text values matching a system category, ignoring case or through a common alias, take that category;
+// any other value becomes a subcategory of Other for its user.
+func MigrateCategoryReferences() error {
+	tables := []struct {
+		model interface{}
+		kind  string
+	}{
+		{&Expense{}, CategoryKindExpense},
+		{&RecurringExpense{}, CategoryKindExpense},
+		{&Sale{}, CategoryKindSale},
+	}
+
+	for _, table := range tables {
+		var values []struct {
+			UserID   uint
+			Category string
+		}
+		if err := db.DB.Model(table.model).Select("DISTINCT user_id, category").
+			Where("category_id IS NULL OR category_id = 0").Scan(&values).Error; err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			ref, err := migratedCategory(value.UserID, table.kind, value.Category)
+			if err != nil {
+				return err
+			}
+			if err := db.DB.Model(table.model).
+				Where("user_id = ? AND category = ? AND (category_id IS NULL OR category_id = 0)", value.UserID, value.Category).
+				UpdateColumns(map[string]interface{}{
+					"category_id":    ref.CategoryID,
+					"category":       ref.Category,
+					"subcategory_id": ref.SubcategoryID,
+					"subcategory":    ref.Subcategory,
+				}).Error; err != nil {
+				return err
+			}
+			log.Printf("Migrated %s category '%s' for user %d to %s", table.kind, value.Category, value.UserID, ref.Category)
+		}
+	}
+	return nil
+}
+
+// migratedCategory finds the managed category for a free-text value, creating a subcategory of Other when none matches
+func migratedCategory(userID uint, kind, value string) (CategoryRef, error) {
+	name := strings.TrimSpace(value)
+	if alias, ok := categoryAliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+
+	var system Category
+	err := db.DB.Where("user_id IS NULL AND parent_id IS NULL AND kind = ? AND LOWER(name) = ?", kind, strings.ToLower(name)).
+		First(&system).Error
+	if err == nil {
+		return CategoryRef{CategoryID: system.ID, Category: system.Name}, nil
+	}
+
+	var other Category
+	if err := db.DB.Where("user_id IS NULL AND parent_id IS NULL AND kind = ? AND name = ?", kind, OtherCategory).
+		First(&other).Error; err != nil {
+		return CategoryRef{}, fmt.Errorf("system category %s is missing: %w", OtherCategory, err)
+	}
+	if name == "" {
+		return CategoryRef{CategoryID: other.ID, Category: other.Name}, nil
+	}
+	if len(name) > 50 {
+		name = name[:50]
+	}
+
+	var sub Category
+	err = db.DB.Where("user_id = ? AND parent_id = ? AND LOWER(name) = ?", userID, other.ID, strings.ToLower(name)).First(&sub).Error
+	if err != nil {
+		sub = Category{UserID: &userID, Kind: kind, Name: name, ParentID: &other.ID}
+		if err := db.DB.Create(&sub).Error; err != nil {
+			return CategoryRef{}, err
+		}
+	}
+	return CategoryRef{CategoryID: other.ID, Category: other.Name, SubcategoryID: &sub.ID, Subcategory: sub.Name}, nil
+}
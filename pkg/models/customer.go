@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Sale statuses. Sales with payments recorded have their status kept in step with the amount paid.
+const (
+	SaleStatusPending = "pending..."
+	SaleStatusPartial = "partial"
+	SaleStatusPaid    = "paid"
+)
+
+// Payment methods accepted for sale payments
+const (
+	PaymentMethodCash  = "cash"
+	PaymentMethodMpesa = "mpesa"
+	PaymentMethodBank  = "bank"
+)
+
+// IsValidPaymentMethod reports whether method is a supported sale payment method
+func IsValidPaymentMethod(method string) bool {
+	return method == PaymentMethodCash || method == PaymentMethodMpesa || method == PaymentMethodBank
+}
+
+// Customer is a buyer the farm sells to, often on credit, such as a shop paying weekly
+type Customer struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint      `json:"user_id" gorm:"index;not null"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	PhoneNumber string    `json:"phone_number" gorm:"type:varchar(50)"`
+	Email       string    `json:"email" gorm:"type:varchar(255)"`
+	Address     string    `json:"address" gorm:"type:varchar(255)"`
+	CreditDays  int       `json:"credit_days" gorm:"not null;default:0"` // payment terms; credit sales fall due this many days after the sale
+	Notes       string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Dynamic field for responses
+	Outstanding float64 `json:"outstanding" gorm:"-"`
+}
+
+// SalePayment is one instalment paid against a sale
+type SalePayment struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	SaleID    uint      `json:"sale_id" gorm:"index;not null"`
+	Amount    float64   `json:"amount" gorm:"not null"`
+	Method    string    `json:"method" gorm:"type:varchar(20);not null"`
+	Reference string    `json:"reference" gorm:"type:varchar(100)"` // e.g. M-Pesa transaction code or bank slip number
+	Date      time.Time `json:"date" gorm:"not null;index"`
+	Notes     string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// AgingBuckets splits outstanding balances by how many days have passed since the sale
+type AgingBuckets struct {
+	Days0To30  float64 `json:"days_0_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"over_90"`
+	Total      float64 `json:"total"`
+}
+
+// Add places an amount in the bucket for a balance that is ageDays old
+func (b *AgingBuckets) Add(ageDays int, amount float64) {
+	switch {
+	case ageDays <= 30:
+		b.Days0To30 += amount
+	case ageDays <= 60:
+		b.Days31To60 += amount
+	case ageDays <= 90:
+		b.Days61To90 += amount
+	default:
+		b.Over90 += amount
+	}
+	b.Total += amount
+}
+
+// CustomerAging is one customer's outstanding balance by age. Sales without a customer are grouped under CustomerID 0.
+type CustomerAging struct {
+	CustomerID   uint   `json:"customer_id"`
+	CustomerName string `json:"customer_name"`
+	PhoneNumber  string `json:"phone_number,omitempty"`
+	OpenSales    int    `json:"open_sales"`
+	AgingBuckets
+}
+
+// AgedReceivables is the aged receivables report at a date
+type AgedReceivables struct {
+	AsOf      time.Time       `json:"as_of"`
+	Totals    AgingBuckets    `json:"totals"`
+	Customers []CustomerAging `json:"customers"`
+}
@@ -17,6 +17,13 @@ type EggProduction struct {
 	DateProduced  time.Time `json:"date_produced" gorm:"not null;type:date"`
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Optional breakdown by grade; when present it drives EggsCollected and PricePerUnit
+	Grades []EggGradeLine `json:"grades" gorm:"foreignKey:EggProductionID;constraint:OnDelete:CASCADE"`
+
+	// Optional crates/trays/loose eggs entry, converted to EggsCollected on save
+	EggQuantityInput
+	EggsDisplay string `json:"eggs_display,omitempty" gorm:"-"` // EggsCollected in the requested unit
 }
 
 // EggAdjustment represents non-sale changes in egg count like giveaways or breakages
@@ -31,4 +38,8 @@ type EggAdjustment struct {
     DateAdjusted    time.Time  `json:"date_adjusted" gorm:"not null;type:date"`
     CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
     UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+    // Optional crates/trays/loose eggs entry, converted to Quantity on save
+    EggQuantityInput
+    QuantityDisplay string     `json:"quantity_display,omitempty" gorm:"-"` // Quantity in the requested unit
 }
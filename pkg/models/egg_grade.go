@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Egg grades by size and quality
+const (
+	EggGradeJumbo   = "jumbo"
+	EggGradeLarge   = "large"
+	EggGradeMedium  = "medium"
+	EggGradeSmall   = "small"
+	EggGradeCracked = "cracked"
+	EggGradeDirty   = "dirty"
+)
+
+// EggGrades lists the supported grades in display order
+var EggGrades = []string{EggGradeJumbo, EggGradeLarge, EggGradeMedium, EggGradeSmall, EggGradeCracked, EggGradeDirty}
+
+// IsValidEggGrade reports whether grade is one of EggGrades
+func IsValidEggGrade(grade string) bool {
+	for _, g := range EggGrades {
+		if g == grade {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidGradeLine is returned for a grade line with an unknown grade or a negative quantity or price
+var ErrInvalidGradeLine = errors.New("invalid grade line")
+
+// validateGradeLine checks one grade line's grade, quantity and price
+func validateGradeLine(grade string, quantity int, price float64) error {
+	if !IsValidEggGrade(grade) {
+		return fmt.Errorf("%w: unknown egg grade '%s'", ErrInvalidGradeLine, grade)
+	}
+	if quantity < 0 || price < 0 {
+		return fmt.Errorf("%w: quantity and price for grade '%s' cannot be negative", ErrInvalidGradeLine, grade)
+	}
+	return nil
+}
+
+// EggGradeLine is the count and price of one grade within an egg production record
+type EggGradeLine struct {
+	ID              uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	EggProductionID uint    `json:"egg_production_id" gorm:"index;not null"`
+	Grade           string  `json:"grade" gorm:"type:varchar(20);not null"`
+	Quantity        int     `json:"quantity" gorm:"not null"`
+	PricePerUnit    float64 `json:"price_per_unit" gorm:"not null;default:0"`
+}
+
+// SaleGradeLine is the quantity and price of one egg grade sold within a sale
+type SaleGradeLine struct {
+	ID        uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	SaleID    uint    `json:"sale_id" gorm:"index;not null"`
+	Grade     string  `json:"grade" gorm:"type:varchar(20);not null"`
+	Quantity  int     `json:"quantity" gorm:"not null"`
+	UnitPrice float64 `json:"unit_price" gorm:"not null"`
+	Amount    float64 `json:"amount" gorm:"not null"`
+}
+
+// ValidateGrades checks a production record's grade lines before it is saved
+func (e *EggProduction) ValidateGrades() error {
+	for _, line := range e.Grades {
+		if err := validateGradeLine(line.Grade, line.Quantity, line.PricePerUnit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateGrades checks a sale's grade lines before it is saved
+func (s *Sale) ValidateGrades() error {
+	for _, line := range s.Grades {
+		if err := validateGradeLine(line.Grade, line.Quantity, line.UnitPrice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BeforeSave totals a graded production record from its grade lines.
+// Ungraded records keep their single PricePerUnit, with EggsCollected taken from any crates/trays entry.
+func (e *EggProduction) BeforeSave(tx *gorm.DB) error {
+	if len(e.Grades) == 0 {
+		eggs, ok, err := e.EggQuantityInput.Eggs()
+		if err != nil {
+			return err
+		}
+		if ok {
+			e.EggsCollected = eggs
+		}
+		e.TotalRevenue = float64(e.EggsCollected) * e.PricePerUnit
+		return nil
+	}
+
+	if err := e.ValidateGrades(); err != nil {
+		return err
+	}
+	eggs, revenue := 0, 0.0
+	for _, line := range e.Grades {
+		eggs += line.Quantity
+		revenue += float64(line.Quantity) * line.PricePerUnit
+	}
+
+	e.EggsCollected = eggs
+	e.TotalRevenue = revenue
+	if eggs > 0 {
+		e.PricePerUnit = revenue / float64(eggs) // weighted average across grades
+	}
+	return nil
+}
+
+// BeforeSave totals a graded sale from its grade lines.
+// Ungraded sales keep their single UnitPrice and Amount, with Quantity taken from any crates/trays entry.
+func (s *Sale) BeforeSave(tx *gorm.DB) error {
+	if len(s.Grades) == 0 {
+		eggs, ok, err := s.EggQuantityInput.Eggs()
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.Quantity = eggs
+			if s.UnitPrice > 0 {
+				s.Amount = float64(eggs) * s.UnitPrice
+			}
+		}
+		return nil
+	}
+
+	if err := s.ValidateGrades(); err != nil {
+		return err
+	}
+	quantity, amount := 0, 0.0
+	for i := range s.Grades {
+		line := &s.Grades[i]
+		line.Amount = float64(line.Quantity) * line.UnitPrice
+		quantity += line.Quantity
+		amount += line.Amount
+	}
+
+	s.Quantity = quantity
+	s.Amount = amount
+	if quantity > 0 {
+		s.UnitPrice = amount / float64(quantity)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+// EggSalesCategory is the sale category that draws eggs from the store
+const EggSalesCategory = "Egg Sales"
+
+// EggStockDay is one day of the egg stock ledger
+type EggStockDay struct {
+	Date     string `json:"date"`
+	Opening  int    `json:"opening"`
+	Produced int    `json:"produced"`
+	Adjusted int    `json:"adjusted"` // breakages, giveaways etc.; negative values are eggs added back
+	Sold     int    `json:"sold"`
+	Returned int    `json:"returned"` // eggs brought back by sales returns, including any later written off
+	Inflows  int    `json:"inflows"`
+	Outflows int    `json:"outflows"`
+	Closing  int    `json:"closing"`
+}
+
+// EggStockLedger is the daily egg stock ledger for one flock, or all flocks when FlockID is 0
+type EggStockLedger struct {
+	FlockID   uint          `json:"flock_id"`
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Opening   int           `json:"opening"`
+	Closing   int           `json:"closing"`
+	Days      []EggStockDay `json:"days"`
+}
+
+// FlockEggStock is the eggs currently in store for one flock
+type FlockEggStock struct {
+	FlockID   uint   `json:"flock_id"`
+	FlockName string `json:"flock_name"`
+	Eggs      int    `json:"eggs"`
+	Display   string `json:"display"` // Eggs in the requested unit
+}
+
+// EggStockOnHand is the stock-on-hand widget payload
+type EggStockOnHand struct {
+	AsOf          string          `json:"as_of"`
+	TotalEggs     int             `json:"total_eggs"`
+	TotalDisplay  string          `json:"total_display"` // TotalEggs in the requested unit
+	ProducedToday int             `json:"produced_today"`
+	SoldToday     int             `json:"sold_today"`
+	Flocks        []FlockEggStock `json:"flocks"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Egg units of measure. Eggs are always stored as single eggs.
+const (
+	EggUnitEggs   = "eggs"
+	EggUnitTrays  = "trays"
+	EggUnitCrates = "crates"
+
+	EggsPerTray   = 30
+	TraysPerCrate = 12
+	EggsPerCrate  = EggsPerTray * TraysPerCrate
+)
+
+// IsValidEggUnit reports whether unit is a supported egg unit
+func IsValidEggUnit(unit string) bool {
+	return unit == EggUnitEggs || unit == EggUnitTrays || unit == EggUnitCrates
+}
+
+// EggQuantityInput lets egg counts be entered as crates, trays and loose eggs.
+// When any field is set the record's egg count is replaced by the total in eggs.
+type EggQuantityInput struct {
+	Crates    *int `json:"crates,omitempty" gorm:"-"`
+	Trays     *int `json:"trays,omitempty" gorm:"-"`
+	LooseEggs *int `json:"loose_eggs,omitempty" gorm:"-"`
+}
+
+// Eggs returns the entered quantity in eggs and whether any unit field was set
+func (q EggQuantityInput) Eggs() (int, bool, error) {
+	if q.Crates == nil && q.Trays == nil && q.LooseEggs == nil {
+		return 0, false, nil
+	}
+
+	total := 0
+	for _, part := range []struct {
+		value *int
+		size  int
+	}{{q.Crates, EggsPerCrate}, {q.Trays, EggsPerTray}, {q.LooseEggs, 1}} {
+		if part.value == nil {
+			continue
+		}
+		if *part.value < 0 {
+			return 0, true, errors.New("crates, trays and loose_eggs cannot be negative")
+		}
+		total += *part.value * part.size
+	}
+	return total, true, nil
+}
+
+// FormatEggCount renders an egg count in the given unit, e.g. "3 trays + 12 eggs"
+func FormatEggCount(eggs int, unit string) string {
+	sign := ""
+	if eggs < 0 {
+		sign, eggs = "-", -eggs
+	}
+
+	var parts []string
+	switch unit {
+	case EggUnitCrates:
+		if crates := eggs / EggsPerCrate; crates > 0 {
+			parts = append(parts, pluralize(crates, "crate"))
+			eggs %= EggsPerCrate
+		}
+		fallthrough
+	case EggUnitTrays:
+		if trays := eggs / EggsPerTray; trays > 0 {
+			parts = append(parts, pluralize(trays, "tray"))
+			eggs %= EggsPerTray
+		}
+	}
+	if eggs > 0 || len(parts) == 0 {
+		parts = append(parts, pluralize(eggs, "egg"))
+	}
+
+	return sign + strings.Join(parts, " + ")
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// BeforeSave converts crates/trays/loose eggs into the stored adjustment quantity
+func (a *EggAdjustment) BeforeSave(tx *gorm.DB) error {
+	eggs, ok, err := a.EggQuantityInput.Eggs()
+	if err != nil {
+		return err
+	}
+	if ok {
+		a.Quantity = eggs
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import "testing"
+
+func intPtr(n int) *int { return &n }
+
+func TestEggQuantityInputEggs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   EggQuantityInput
+		eggs    int
+		set     bool
+		wantErr bool
+	}{
+		{name: "nothing entered", input: EggQuantityInput{}},
+		{name: "loose eggs only", input: EggQuantityInput{LooseEggs: intPtr(17)}, eggs: 17, set: true},
+		{name: "trays", input: EggQuantityInput{Trays: intPtr(3)}, eggs: 90, set: true},
+		{name: "crates", input: EggQuantityInput{Crates: intPtr(2)}, eggs: 720, set: true},
+		{
+			name:  "crates, trays and loose eggs",
+			input: EggQuantityInput{Crates: intPtr(1), Trays: intPtr(2), LooseEggs: intPtr(5)},
+			eggs:  EggsPerCrate + 2*EggsPerTray + 5,
+			set:   true,
+		},
+		{name: "explicit zero", input: EggQuantityInput{Trays: intPtr(0)}, eggs: 0, set: true},
+		{name: "negative trays", input: EggQuantityInput{Trays: intPtr(-1)}, set: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eggs, set, err := tt.input.Eggs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eggs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if set != tt.set {
+				t.Errorf("Eggs() set = %v, want %v", set, tt.set)
+			}
+			if !tt.wantErr && eggs != tt.eggs {
+				t.Errorf("Eggs() = %d, want %d", eggs, tt.eggs)
+			}
+		})
+	}
+}
+
+func TestFormatEggCount(t *testing.T) {
+	tests := []struct {
+		eggs int
+		unit string
+		want string
+	}{
+		{0, EggUnitEggs, "0 eggs"},
+		{1, EggUnitEggs, "1 egg"},
+		{400, EggUnitEggs, "400 eggs"},
+		{30, EggUnitTrays, "1 tray"},
+		{102, EggUnitTrays, "3 trays + 12 eggs"},
+		{12, EggUnitTrays, "12 eggs"},
+		{0, EggUnitTrays, "0 eggs"},
+		{360, EggUnitCrates, "1 crate"},
+		{751, EggUnitCrates, "2 crates + 1 tray + 1 egg"},
+		{45, EggUnitCrates, "1 tray + 15 eggs"},
+		{-102, EggUnitTrays, "-3 trays + 12 eggs"},
+		{102, "dozens", "102 eggs"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatEggCount(tt.eggs, tt.unit); got != tt.want {
+			t.Errorf("FormatEggCount(%d, %q) = %q, want %q", tt.eggs, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -8,14 +8,29 @@ import (
 type Expense struct {
 	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	UserID      uint      `json:"user_id" gorm:"index;not null"`
-	FlockID     uint      `json:"flock_id" gorm:"not null;index"` // Foreign key reference to Flock
+	FlockID     *uint     `json:"flock_id" gorm:"index"` // Foreign key reference to Flock, nil for expenses allocated across flocks
 	Date        time.Time `json:"date" gorm:"not null;type:date"`
 	Description string    `json:"description" gorm:"type:varchar(255);not null"`
 	Amount      float64   `json:"amount" gorm:"not null"`
-	Category    string    `json:"category" gorm:"type:varchar(50);not null"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
+	// Managed category, with an optional user subcategory
+	CategoryRef
+
+	// Set when the expense comes from a received purchase order; written only on create
+	SupplierID      *uint `json:"supplier_id,omitempty" gorm:"index;<-:create"`
+	PurchaseOrderID *uint `json:"purchase_order_id,omitempty" gorm:"index;<-:create"`
+	InventoryItemID *uint `json:"inventory_item_id,omitempty" gorm:"index;<-:create"`
+
+	// Set when the expense was posted from a recurring expense template; written only on create
+	RecurringExpenseID *uint `json:"recurring_expense_id,omitempty" gorm:"index;<-:create"`
+
+	// Set for farm-level expenses split across flocks; the split is stored in Allocations
+	AllocationMethod string              `json:"allocation_method,omitempty" gorm:"type:varchar(20);not null;default:''"`
+	Allocations      []ExpenseAllocation `json:"allocations,omitempty" gorm:"foreignKey:ExpenseID"`
+
 	// Relationships
-	Flock Flock `json:"flock" gorm:"foreignKey:FlockID"`
+	Flock       Flock               `json:"flock" gorm:"foreignKey:FlockID"`
+	Attachments []ExpenseAttachment `json:"attachments,omitempty" gorm:"foreignKey:ExpenseID"`
 }
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Rules for allocating a farm-level expense across flocks
+const (
+	AllocationEqual      = "equal"      // the same share for every flock
+	AllocationBirdCount  = "bird_count" // by birds alive on the expense date
+	AllocationPercentage = "percentage" // by percentages given with the expense
+	AllocationFeedUsage  = "feed_usage" // by feed logged in the expense's month
+)
+
+// ExpenseAllocation is one flock's share of a farm-level expense. Basis is what the share was
+// computed from: 1 per flock for equal splits, birds, kg of feed, or the percentage given.
+type ExpenseAllocation struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ExpenseID  uint      `json:"expense_id" gorm:"index;not null"`
+	UserID     uint      `json:"user_id" gorm:"index;not null"`
+	FlockID    uint      `json:"flock_id" gorm:"index;not null"`
+	Basis      float64   `json:"basis" gorm:"not null"`
+	Percentage float64   `json:"percentage" gorm:"not null"`
+	Amount     float64   `json:"amount" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
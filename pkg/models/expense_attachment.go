@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Content types accepted for expense attachments
+const (
+	AttachmentJPEG = "image/jpeg"
+	AttachmentPNG  = "image/png"
+	AttachmentWebP = "image/webp"
+	AttachmentPDF  = "application/pdf"
+)
+
+// MaxAttachmentSize is the largest expense attachment accepted, in bytes
+const MaxAttachmentSize = 10 << 20
+
+// ExpenseAttachment is a receipt or invoice image or PDF uploaded against an expense.
+// The file itself lives in storage under StorageKey and is only served to the owning user.
+type ExpenseAttachment struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uint      `json:"user_id" gorm:"index;not null"`
+	ExpenseID    uint      `json:"expense_id" gorm:"index;not null"`
+	FileName     string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	ContentType  string    `json:"content_type" gorm:"type:varchar(50);not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	StorageKey   string    `json:"-" gorm:"type:varchar(255);not null"`
+	ThumbnailKey string    `json:"-" gorm:"type:varchar(255)"` // empty when no thumbnail could be made
+	HasThumbnail bool      `json:"has_thumbnail" gorm:"-"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// IsImage reports whether the attachment is an image rather than a PDF
+func (a *ExpenseAttachment) IsImage() bool {
+	return a.ContentType != AttachmentPDF
+}
+
+// AfterFind flags attachments that have a thumbnail
+func (a *ExpenseAttachment) AfterFind(tx *gorm.DB) error {
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// FeedLog records feed given to a flock on a given day
+type FeedLog struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          uint      `json:"user_id" gorm:"index;not null"`
+	FlockID         uint      `json:"flock_id" gorm:"index;not null"`
+	Date            time.Time `json:"date" gorm:"not null;type:date"`
+	FeedType        string    `json:"feed_type" gorm:"type:varchar(100);not null"` // e.g. layers mash, growers pellets
+	QuantityKg      float64   `json:"quantity_kg" gorm:"not null"`
+	InventoryItemID *uint     `json:"inventory_item_id" gorm:"index"` // optional, feed is deducted from this item
+	AvgBirdWeightKg *float64  `json:"avg_bird_weight_kg,omitempty"`   // optional weigh-in taken with the log
+	Notes           string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// FeedAnalytics summarises feed usage and efficiency for a flock over a date range
+type FeedAnalytics struct {
+	FlockID            uint               `json:"flock_id"`
+	StartDate          string             `json:"start_date"`
+	EndDate            string             `json:"end_date"`
+	Days               int                `json:"days"`
+	TotalFeedKg        float64            `json:"total_feed_kg"`
+	FeedByType         map[string]float64 `json:"feed_by_type"`
+	BirdDays           int                `json:"bird_days"`
+	FeedPerBirdPerDayG float64            `json:"feed_per_bird_per_day_g"`
+	EggsCollected      int                `json:"eggs_collected"`
+	EggWeightKg        float64            `json:"egg_weight_kg"`
+	EggMassKg          float64            `json:"egg_mass_kg"`
+	FCREggs            float64            `json:"fcr_eggs"` // kg feed per kg eggs, 0 when no eggs
+	LiveweightGainKg   float64            `json:"liveweight_gain_kg"`
+	FCRLiveweight      float64            `json:"fcr_liveweight"` // kg feed per kg liveweight gained, 0 without weigh-ins
+}
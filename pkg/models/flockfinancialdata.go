@@ -1,14 +1,14 @@
 package models
 
+import "birdseye-backend/pkg/db"
 
-
-// FlockFinancialData stores monthly financial data for a flock
+// FlockFinancialData stores monthly financial data for a flock, one row per flock and month
 type FlocksFinancialData struct {
     ID         uint    `json:"id" gorm:"primaryKey;autoIncrement"`
-    FlockID    uint    `json:"flock_id" gorm:"index;not null"`
+    FlockID    uint    `json:"flock_id" gorm:"index;uniqueIndex:idx_flock_financial_month,priority:1;not null"`
     UserID     uint    `json:"user_id" gorm:"index;not null"`
-    Month      int     `json:"month" gorm:"not null"`
-    Year       int     `json:"year" gorm:"not null"`
+    Month      int     `json:"month" gorm:"uniqueIndex:idx_flock_financial_month,priority:3;not null"`
+    Year       int     `json:"year" gorm:"uniqueIndex:idx_flock_financial_month,priority:2;not null"`
     Revenue    float64 `json:"revenue" gorm:"not null"`
     EggSales   float64 `json:"egg_sales" gorm:"not null"`
     Expenses   float64 `json:"expenses" gorm:"not null"`
@@ -16,3 +16,14 @@ type FlocksFinancialData struct {
     Budget     float64 `json:"budget" gorm:"not null"`
 }
 
+
+// DedupeFlocksFinancialData keeps only the newest row of each flock and month, so the unique
+// index on them can be created. It must run before the table is auto-migrated.
+func DedupeFlocksFinancialData() error {
+    if !db.DB.Migrator().HasTable(&FlocksFinancialData{}) {
+        return nil
+    }
+    return db.DB.Exec(`DELETE older FROM flocks_financial_data older
+        JOIN flocks_financial_data newer ON newer.flock_id = older.flock_id
+            AND newer.year = older.year AND newer.month = older.month AND newer.id > older.id`).Error
+}
@@ -10,6 +10,7 @@ type InventoryItem struct {
     CostPerUnit float64 `gorm:"not null" json:"cost_per_unit"`
     UserID      uint    `gorm:"not null" json:"user_id"`
     FlockID     uint    `gorm:"not null;index" json:"flock_id"` // Foreign key reference to Flock
+    LowStockAlerted bool `gorm:"default:false" json:"low_stock_alerted"` // Set once an alert is sent, cleared when restocked above ReorderLevel
 
     // Relationship
     Flock       *Flock  `gorm:"foreignKey:FlockID;constraint:OnDelete:CASCADE;" json:"flock"` // Define relationship with Flock
@@ -23,3 +24,10 @@ type InventoryItem struct {
 func (InventoryItem) TableName() string {
     return "inventory_items"
 }
+
+// LowStockItem is an inventory item at or below its reorder level with a projected stock-out
+type LowStockItem struct {
+    InventoryItem
+    AvgDailyUsage  float64  `json:"avg_daily_usage"`  // units used or wasted per day over the usage window
+    DaysToStockout *float64 `json:"days_to_stockout"` // nil when there has been no recent usage
+}
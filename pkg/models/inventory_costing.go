@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Inventory costing methods
+const (
+	CostingFIFO            = "fifo"
+	CostingWeightedAverage = "weighted_average"
+
+	DefaultCostingMethod = CostingWeightedAverage
+)
+
+// IsValidCostingMethod reports whether method is a supported costing method
+func IsValidCostingMethod(method string) bool {
+	return method == CostingFIFO || method == CostingWeightedAverage
+}
+
+// ItemValuation is the stock on hand and its cost for one inventory item at a date
+type ItemValuation struct {
+	InventoryItemID uint    `json:"inventory_item_id"`
+	ItemName        string  `json:"item_name"`
+	FlockID         uint    `json:"flock_id"`
+	Quantity        int     `json:"quantity"`
+	UnitCost        float64 `json:"unit_cost"` // value divided by quantity
+	Value           float64 `json:"value"`
+}
+
+// InventoryValuation is the value of a user's stock at a date under their costing method
+type InventoryValuation struct {
+	AsOf          time.Time       `json:"as_of"`
+	CostingMethod string          `json:"costing_method"`
+	TotalValue    float64         `json:"total_value"`
+	Items         []ItemValuation `json:"items"`
+}
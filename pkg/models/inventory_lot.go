@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// DefaultExpiryWarningDays is how far ahead lots are flagged as expiring when the user has not chosen otherwise
+const DefaultExpiryWarningDays = 30
+
+// InventoryLot is a batch of an inventory item, such as a vaccine or drug, with its own expiry date.
+// A lot's Quantity is the sum of the movements posted against it.
+type InventoryLot struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          uint      `json:"user_id" gorm:"index;not null"`
+	InventoryItemID uint      `json:"inventory_item_id" gorm:"not null;uniqueIndex:idx_item_batch"`
+	BatchNumber     string    `json:"batch_number" gorm:"type:varchar(100);not null;uniqueIndex:idx_item_batch"`
+	ExpiryDate      time.Time `json:"expiry_date" gorm:"type:date;not null;index"`
+	ReceivedDate    time.Time `json:"received_date" gorm:"type:date;not null"`
+	Quantity        int       `json:"quantity" gorm:"not null;default:0"`
+	UnitCost        float64   `json:"unit_cost" gorm:"not null;default:0"`
+	ExpiryAlerted   bool      `json:"expiry_alerted" gorm:"default:false"` // Set once an expiry warning is sent
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Dynamic fields for responses
+	ItemName        string `json:"item_name,omitempty" gorm:"-"`
+	DaysUntilExpiry int    `json:"days_until_expiry" gorm:"-"`
+}
+
+// IsExpired reports whether the lot's expiry date is before the given day
+func (l *InventoryLot) IsExpired(on time.Time) bool {
+	y, m, d := on.Date()
+	return l.ExpiryDate.Before(time.Date(y, m, d, 0, 0, 0, 0, l.ExpiryDate.Location()))
+}
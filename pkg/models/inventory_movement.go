@@ -0,0 +1,79 @@
+package models
+
+import (
+	"birdseye-backend/pkg/db"
+	"log"
+	"time"
+)
+
+// Inventory movement types
+const (
+	MovementPurchase   = "purchase"
+	MovementUsage      = "usage"
+	MovementWastage    = "wastage"
+	MovementTransfer   = "transfer"
+	MovementCorrection = "correction"
+)
+
+// OpeningBalanceReference marks the correction that records an item's stock from before movements were tracked
+const OpeningBalanceReference = "Opening balance"
+
+// MovementTypes lists the supported inventory movement types
+var MovementTypes = []string{MovementPurchase, MovementUsage, MovementWastage, MovementTransfer, MovementCorrection}
+
+// IsValidMovementType reports whether t is one of MovementTypes
+func IsValidMovementType(t string) bool {
+	for _, mt := range MovementTypes {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// InventoryMovement is one change to an inventory item's stock. An item's Quantity is the sum of its movements.
+type InventoryMovement struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          uint      `json:"user_id" gorm:"index;not null"`
+	InventoryItemID uint      `json:"inventory_item_id" gorm:"index;not null"`
+	Type            string    `json:"type" gorm:"type:varchar(20);not null"`
+	QuantityDelta   int       `json:"quantity_delta" gorm:"not null"` // positive for stock in, negative for stock out
+	UnitCost        float64   `json:"unit_cost" gorm:"not null;default:0"`
+	Reference       string    `json:"reference" gorm:"type:varchar(100)"` // e.g. invoice number or feed_log:12
+	Notes           string    `json:"notes,omitempty" gorm:"type:text"`
+	FlockID         *uint     `json:"flock_id,omitempty" gorm:"index"` // flock the stock was used on, if any
+	LotID           *uint     `json:"lot_id,omitempty" gorm:"index"`   // lot the stock came from or went into, if lot tracked
+	Date            time.Time `json:"date" gorm:"not null;index"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Dynamic fields for responses and reports
+	ItemName string  `json:"item_name,omitempty" gorm:"-"`
+	Cost     float64 `json:"cost,omitempty" gorm:"-"` // cost of stock issued under the user's costing method
+}
+
+// MigrateInventoryOpeningBalances records an opening correction for items whose
+// quantity was set before movements were tracked, so the ledger matches the stored balance
+func MigrateInventoryOpeningBalances() error {
+	var items []InventoryItem
+	if err := db.DB.Where("quantity <> 0 AND id NOT IN (?)",
+		db.DB.Model(&InventoryMovement{}).Select("inventory_item_id")).Find(&items).Error; err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		movement := InventoryMovement{
+			UserID:          item.UserID,
+			InventoryItemID: item.ID,
+			Type:            MovementCorrection,
+			QuantityDelta:   item.Quantity,
+			UnitCost:        item.CostPerUnit,
+			Reference:       OpeningBalanceReference,
+			Date:            time.Now(),
+		}
+		if err := db.DB.Create(&movement).Error; err != nil {
+			return err
+		}
+		log.Printf("Recorded opening balance of %d for inventory item %d", item.Quantity, item.ID)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"birdseye-backend/pkg/db"
+	"log"
+	"time"
+)
+
+// Account types. Assets and expenses carry debit balances; liabilities, equity and revenue carry credit balances.
+const (
+	AccountAsset     = "asset"
+	AccountLiability = "liability"
+	AccountEquity    = "equity"
+	AccountRevenue   = "revenue"
+	AccountExpense   = "expense"
+)
+
+// Codes of the system accounts that entries are posted to
+const (
+	AccountCash                 = "1000"
+	AccountReceivable           = "1100"
+	AccountInventory            = "1200"
+	AccountOpeningBalanceEquity = "3000"
+	AccountSales                = "4000"
+	AccountSalesReturns         = "4100" // contra revenue, carries a debit balance
+	AccountOperatingExpenses    = "5000"
+	AccountStockUsed            = "5100"
+	AccountInventoryAdjustments = "5200"
+)
+
+// Journal entry sources. Every entry is posted from, and reposted with, the record it comes from.
+const (
+	SourceSale          = "sale"           // the sale with its payments and credit notes
+	SourceExpense       = "expense"        // including stock bought on a purchase order
+	SourceInventoryItem = "inventory_item" // stock movements, costed under the user's costing method
+)
+
+// systemAccounts is the chart of accounts shared by every user
+var systemAccounts = []Account{
+	{Code: AccountCash, Name: "Cash", Type: AccountAsset},
+	{Code: AccountReceivable, Name: "Accounts Receivable", Type: AccountAsset},
+	{Code: AccountInventory, Name: "Inventory", Type: AccountAsset},
+	{Code: AccountOpeningBalanceEquity, Name: "Opening Balance Equity", Type: AccountEquity},
+	{Code: AccountSales, Name: "Sales", Type: AccountRevenue},
+	{Code: AccountSalesReturns, Name: "Sales Returns", Type: AccountRevenue},
+	{Code: AccountOperatingExpenses, Name: "Operating Expenses", Type: AccountExpense},
+	{Code: AccountStockUsed, Name: StockUsedLine, Type: AccountExpense},
+	{Code: AccountInventoryAdjustments, Name: "Inventory Adjustments", Type: AccountExpense},
+}
+
+// Account is an account in the chart of accounts
+type Account struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code      string    `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	Type      string    `json:"type" gorm:"type:varchar(20);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// DebitNormal reports whether the account's balance is its debits less its credits
+func (a Account) DebitNormal() bool {
+	return a.Type == AccountAsset || a.Type == AccountExpense || a.Code == AccountSalesReturns
+}
+
+// JournalEntry is a balanced set of debits and credits posted on one date
+type JournalEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint      `json:"user_id" gorm:"index:idx_journal_source;index;not null"`
+	Date        time.Time `json:"date" gorm:"not null;index"`
+	Description string    `json:"description" gorm:"type:varchar(255);not null"`
+	SourceType  string    `json:"source_type" gorm:"type:varchar(30);index:idx_journal_source;not null"`
+	SourceID    uint      `json:"source_id" gorm:"index:idx_journal_source;not null"`
+	Reference   string    `json:"reference,omitempty" gorm:"type:varchar(100)"` // e.g. sale ref, credit note or movement
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Lines []JournalLine `json:"lines" gorm:"foreignKey:JournalEntryID;constraint:OnDelete:CASCADE"`
+}
+
+// JournalLine debits or credits one account. Flock and category are kept for reporting.
+type JournalLine struct {
+	ID             uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	JournalEntryID uint    `json:"journal_entry_id" gorm:"index;not null"`
+	AccountID      uint    `json:"account_id" gorm:"index;not null"`
+	FlockID        *uint   `json:"flock_id,omitempty" gorm:"index"`
+	CategoryID     uint    `json:"category_id" gorm:"not null;default:0"`
+	Category       string  `json:"category,omitempty" gorm:"type:varchar(50)"`
+	Debit          float64 `json:"debit" gorm:"not null;default:0"`
+	Credit         float64 `json:"credit" gorm:"not null;default:0"`
+
+	Account *Account `json:"account,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// SeedAccounts creates any missing system accounts
+func SeedAccounts() error {
+	for _, account := range systemAccounts {
+		var count int64
+		if err := db.DB.Model(&Account{}).Where("code = ?", account.Code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		account := account
+		if err := db.DB.Create(&account).Error; err != nil {
+			return err
+		}
+		log.Printf("Seeded account %s %s", account.Code, account.Name)
+	}
+	return nil
+}
+
+// AccountBalance is an account's debits, credits and balance on its normal side
+type AccountBalance struct {
+	AccountID uint    `json:"account_id"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Debit     float64 `json:"debit"`
+	Credit    float64 `json:"credit"`
+	Balance   float64 `json:"balance"`
+}
+
+// TrialBalance lists every account's totals at a date. Debits equal credits when the ledger balances.
+type TrialBalance struct {
+	AsOf     string           `json:"as_of"`
+	Accounts []AccountBalance `json:"accounts"`
+	Debit    float64          `json:"debit"`
+	Credit   float64          `json:"credit"`
+	Balanced bool             `json:"balanced"`
+}
+
+// BalanceSheet is the ledger's assets, liabilities and equity at a date. Retained earnings are revenue
+// less expenses posted up to the date.
+type BalanceSheet struct {
+	AsOf             string           `json:"as_of"`
+	Assets           []AccountBalance `json:"assets"`
+	Liabilities      []AccountBalance `json:"liabilities"`
+	Equity           []AccountBalance `json:"equity"`
+	RetainedEarnings float64          `json:"retained_earnings"`
+	TotalAssets      float64          `json:"total_assets"`
+	TotalLiabilities float64          `json:"total_liabilities"`
+	TotalEquity      float64          `json:"total_equity"` // including retained earnings
+}
+
+// LedgerProfitAndLossLine is one account and category's total in a ledger profit and loss statement
+type LedgerProfitAndLossLine struct {
+	Code       string  `json:"code"`
+	Account    string  `json:"account"`
+	CategoryID uint    `json:"category_id"`
+	Category   string  `json:"category,omitempty"`
+	Amount     float64 `json:"amount"` // sales returns are negative
+}
+
+// LedgerProfitAndLoss is a profit and loss statement read from the ledger's revenue and expense accounts
+type LedgerProfitAndLoss struct {
+	FlockID  *uint                     `json:"flock_id,omitempty"` // nil for the whole farm
+	Start    string                    `json:"start"`
+	End      string                    `json:"end"`
+	Revenue  []LedgerProfitAndLossLine `json:"revenue"`
+	Expenses []LedgerProfitAndLossLine `json:"expenses"`
+	Total    ProfitAndLossPeriod       `json:"total"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// Mortality causes accepted on a MortalityEvent
+const (
+	MortalityCauseDisease    = "disease"
+	MortalityCausePredator   = "predator"
+	MortalityCauseCulling    = "culling"
+	MortalityCauseHeatStress = "heat_stress"
+	MortalityCauseUnknown    = "unknown"
+)
+
+// MortalityCauses lists every valid mortality cause
+var MortalityCauses = []string{
+	MortalityCauseDisease,
+	MortalityCausePredator,
+	MortalityCauseCulling,
+	MortalityCauseHeatStress,
+	MortalityCauseUnknown,
+}
+
+// MortalityEvent records birds lost from a flock on a given day
+type MortalityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	FlockID   uint      `json:"flock_id" gorm:"index;not null"`
+	Date      time.Time `json:"date" gorm:"not null;type:date"`
+	Count     int       `json:"count" gorm:"not null"`
+	Cause     string    `json:"cause" gorm:"type:varchar(50);not null;default:'unknown'"`
+	Notes     string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// MortalityPoint is one day on a flock's mortality curve
+type MortalityPoint struct {
+	Date             string  `json:"date"`
+	Deaths           int     `json:"deaths"`
+	CumulativeDeaths int     `json:"cumulative_deaths"`
+	DailyRate        float64 `json:"daily_rate"`      // % of birds alive at the start of the day
+	CumulativeRate   float64 `json:"cumulative_rate"` // % of the initial bird count
+}
+
+// IsValidMortalityCause reports whether cause is one of MortalityCauses
+func IsValidMortalityCause(cause string) bool {
+	for _, c := range MortalityCauses {
+		if c == cause {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EggPriceProduct is the price list product used to price egg production records, and egg sales
+// whose own product has no price
+const EggPriceProduct = "Eggs"
+
+// PriceListItem is the unit price of a product, optionally for one egg grade or one customer, over a date range.
+// Egg prices are per egg. Where several items apply, a customer's own price beats the general price
+// and a grade's price beats the product's.
+type PriceListItem struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID        uint       `json:"user_id" gorm:"index;not null"`
+	Product       string     `json:"product" gorm:"type:varchar(100);not null;index"`
+	Grade         string     `json:"grade" gorm:"type:varchar(20);not null;default:''"` // empty for every grade
+	CustomerID    *uint      `json:"customer_id,omitempty" gorm:"index"`                // nil for all customers
+	UnitPrice     float64    `json:"unit_price" gorm:"not null"`
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"type:date;not null"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" gorm:"type:date"` // nil while the price is current
+	Notes         string     `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Customer *Customer `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+}
+
+// BeforeSave validates the item
+func (p *PriceListItem) BeforeSave(tx *gorm.DB) error {
+	p.Product = strings.TrimSpace(p.Product)
+	if p.Product == "" {
+		return errors.New("product is required")
+	}
+	if p.Grade != "" && !IsValidEggGrade(p.Grade) {
+		return fmt.Errorf("invalid egg grade '%s'", p.Grade)
+	}
+	if p.UnitPrice < 0 {
+		return errors.New("unit price cannot be negative")
+	}
+	if p.EffectiveFrom.IsZero() {
+		return errors.New("effective_from is required")
+	}
+	if p.EffectiveTo != nil && p.EffectiveTo.Before(p.EffectiveFrom) {
+		return errors.New("effective_to must not be before effective_from")
+	}
+	return nil
+}
+
+// ResolvedPrice is the price list item that applies to a product on a date
+type ResolvedPrice struct {
+	Product    string  `json:"product"`
+	Grade      string  `json:"grade,omitempty"`
+	CustomerID *uint   `json:"customer_id,omitempty"`
+	Date       string  `json:"date"`
+	UnitPrice  float64 `json:"unit_price"`
+	ItemID     uint    `json:"price_list_item_id"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// StockUsedLine labels the profit and loss cost line for stock used from inventory. Stock purchases are
+// held in inventory, so they are costed as they are used rather than under their expense category.
+const StockUsedLine = "Stock Used"
+
+// ProfitAndLossPeriod is the date range and totals of one period of a profit and loss statement
+type ProfitAndLossPeriod struct {
+	Start     string  `json:"start"`
+	End       string  `json:"end"`
+	Revenue   float64 `json:"revenue"`
+	Costs     float64 `json:"costs"`
+	NetProfit float64 `json:"net_profit"`
+}
+
+// ProfitAndLossLine is one category's revenue or costs in the period and the two comparison periods.
+// Changes are percentages of the comparison amount, nil when that amount is zero.
+type ProfitAndLossLine struct {
+	CategoryID     uint     `json:"category_id"` // 0 for lines without a category, such as stock used
+	Category       string   `json:"category"`
+	Amount         float64  `json:"amount"`
+	PreviousPeriod float64  `json:"previous_period"`
+	LastYear       float64  `json:"last_year"`
+	PreviousChange *float64 `json:"previous_change,omitempty"`
+	LastYearChange *float64 `json:"last_year_change,omitempty"`
+}
+
+// ProfitAndLoss is a profit and loss statement for a period, compared with the period before it and
+// the same period a year earlier. Revenue is net of credit notes.
+type ProfitAndLoss struct {
+	FlockID        *uint               `json:"flock_id,omitempty"` // nil for the whole farm
+	Current        ProfitAndLossPeriod `json:"current"`
+	PreviousPeriod ProfitAndLossPeriod `json:"previous_period"`
+	LastYear       ProfitAndLossPeriod `json:"last_year"`
+	Revenue        []ProfitAndLossLine `json:"revenue"`
+	Costs          []ProfitAndLossLine `json:"costs"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recurring expense frequencies. Custom schedules repeat every IntervalDays days.
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyCustom  = "custom"
+)
+
+// RecurringExpense is a template for an expense that repeats, such as labour, rent or electricity.
+// The scheduler posts an Expense for every occurrence up to today, starting from NextDate.
+type RecurringExpense struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uint       `json:"user_id" gorm:"index;not null"`
+	FlockID      uint       `json:"flock_id" gorm:"index;not null"`
+	Description  string     `json:"description" gorm:"type:varchar(255);not null"`
+	Amount       float64    `json:"amount" gorm:"not null"`
+	Frequency    string     `json:"frequency" gorm:"type:varchar(20);not null"`
+	IntervalDays int        `json:"interval_days,omitempty" gorm:"not null;default:0"` // custom frequency only
+	StartDate    time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate      *time.Time `json:"end_date,omitempty" gorm:"type:date"` // nil to repeat indefinitely
+	NextDate     time.Time  `json:"next_date" gorm:"type:date;not null;index"`
+	Paused       bool       `json:"paused" gorm:"not null;default:false"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Managed category, with an optional user subcategory, copied to the expenses it posts
+	CategoryRef
+
+	Exceptions []RecurringExpenseException `json:"exceptions,omitempty" gorm:"foreignKey:RecurringExpenseID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeSave validates the template
+func (r *RecurringExpense) BeforeSave(tx *gorm.DB) error {
+	if r.Description == "" {
+		return errors.New("description is required")
+	}
+	if r.Amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		r.IntervalDays = 0
+	case FrequencyCustom:
+		if r.IntervalDays <= 0 {
+			return errors.New("interval_days must be greater than zero for a custom frequency")
+		}
+	default:
+		return errors.New("frequency must be daily, weekly, monthly or custom")
+	}
+	if r.StartDate.IsZero() {
+		return errors.New("start_date is required")
+	}
+	if r.EndDate != nil && r.EndDate.Before(r.StartDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// RecurringExpenseException skips one occurrence of a recurring expense or changes what is posted for it
+type RecurringExpenseException struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	RecurringExpenseID uint      `json:"recurring_expense_id" gorm:"uniqueIndex:idx_recurring_date;not null"`
+	Date               time.Time `json:"date" gorm:"type:date;uniqueIndex:idx_recurring_date;not null"`
+	Skip               bool      `json:"skip" gorm:"not null;default:false"`
+	Amount             *float64  `json:"amount,omitempty"`                               // nil to post the template amount
+	Description        string    `json:"description,omitempty" gorm:"type:varchar(255)"` // empty to post the template description
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RecurringOccurrence is an upcoming occurrence of a recurring expense with any exception applied
+type RecurringOccurrence struct {
+	Date        string  `json:"date"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Skipped     bool    `json:"skipped"`
+	Edited      bool    `json:"edited"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// What happens to eggs that come back on a return
+const (
+	ReturnRestock  = "restock"   // back into egg stock for resale
+	ReturnWriteOff = "write_off" // discarded, recorded as an EggAdjustment
+)
+
+// SalesReturnAdjustmentReason is the EggAdjustment reason used when returned eggs are written off
+const SalesReturnAdjustmentReason = "Sales return write-off"
+
+// SaleReturn is a credit note against a recorded sale for goods returned or rejected by the buyer.
+// The original sale is left unchanged; its returned quantity and amount are kept in step with its returns.
+type SaleReturn struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID          uint      `json:"user_id" gorm:"index;not null"`
+	SaleID          uint      `json:"sale_id" gorm:"index;not null"`
+	FlockID         uint      `json:"flock_id" gorm:"index;not null"` // copied from the sale
+	CreditNoteNo    string    `json:"credit_note_no" gorm:"type:varchar(50);unique;not null"`
+	Quantity        int       `json:"quantity" gorm:"not null;default:0"` // may be zero for a price-only credit
+	Amount          float64   `json:"amount" gorm:"not null"`
+	Disposition     string    `json:"disposition" gorm:"type:varchar(20)"` // restock or write_off; egg sales only
+	EggAdjustmentID *uint     `json:"egg_adjustment_id,omitempty" gorm:"index"`
+	Reason          string    `json:"reason" gorm:"type:varchar(255);not null"`
+	Date            time.Time `json:"date" gorm:"not null;index"`
+	Notes           string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Sale *Sale `json:"sale,omitempty" gorm:"foreignKey:SaleID"`
+}
+
+// BeforeCreate assigns the credit note number
+func (r *SaleReturn) BeforeCreate(tx *gorm.DB) error {
+	r.CreditNoteNo = fmt.Sprintf("CN-%d-%s-%04d", r.UserID, time.Now().Format("20060102150405"), time.Now().Nanosecond()%10000)
+	return nil
+}
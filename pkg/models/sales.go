@@ -13,7 +13,6 @@ type Sale struct {
 	FlockID     uint      `json:"flock_id" gorm:"index;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` 
 	RefNo       string    `json:"ref_no" gorm:"type:varchar(50);unique;not null"`
 	Product     string    `json:"product" gorm:"type:varchar(100);not null"`
-	Category    string    `json:"category" gorm:"type:varchar(50);not null"` 
 	Description string    `json:"description" gorm:"type:varchar(255);not null"`
 	Quantity    int       `json:"quantity" gorm:"not null"`
 	UnitPrice   float64   `json:"unit_price" gorm:"not null"`
@@ -24,8 +23,30 @@ type Sale struct {
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
+	// Managed category, with an optional user subcategory
+	CategoryRef
+
+	// Credit sales: the buyer, when payment falls due, and the total of payments recorded so far
+	CustomerID *uint         `json:"customer_id,omitempty" gorm:"index"`
+	DueDate    *time.Time    `json:"due_date,omitempty" gorm:"type:date"`
+	AmountPaid float64       `json:"amount_paid" gorm:"not null;default:0"`
+	Balance    float64       `json:"balance" gorm:"-"` // Amount less returns and AmountPaid, filled for responses
+	Customer   *Customer     `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	Payments   []SalePayment `json:"payments,omitempty" gorm:"foreignKey:SaleID;constraint:OnDelete:CASCADE"`
+
+	// Totals of the credit notes raised against the sale
+	QuantityReturned int     `json:"quantity_returned" gorm:"not null;default:0"`
+	AmountReturned   float64 `json:"amount_returned" gorm:"not null;default:0"`
+
 	// Relationship
 	Flock Flock `json:"flock" gorm:"foreignKey:FlockID"` 
+
+	// Optional breakdown of egg sales by grade; when present it drives Quantity, UnitPrice and Amount
+	Grades []SaleGradeLine `json:"grades" gorm:"foreignKey:SaleID;constraint:OnDelete:CASCADE"`
+
+	// Optional crates/trays/loose eggs entry for egg sales, converted to Quantity on save
+	EggQuantityInput
+	QuantityDisplay string `json:"quantity_display,omitempty" gorm:"-"` // Quantity in the requested unit, egg sales only
 }
 
 // GenerateRefNo generates a unique reference number for sales
@@ -39,7 +60,21 @@ func GenerateRefNo(userID uint) string {
 func (s *Sale) BeforeCreate(tx *gorm.DB) (err error) {
 	s.RefNo = GenerateRefNo(s.UserID)
 	if s.Status == "" {
-		s.Status = "pending..." // Default status
+		s.Status = SaleStatusPending // Default status
 	}
 	return
 }
+
+// NetAmount is the sale amount less credit notes raised against it
+func (s *Sale) NetAmount() float64 {
+	return s.Amount - s.AmountReturned
+}
+
+// AfterFind fills the outstanding balance. Sales marked paid without instalments are settled in full.
+func (s *Sale) AfterFind(tx *gorm.DB) error {
+	s.Balance = 0
+	if s.Status != SaleStatusPaid && s.NetAmount()-s.AmountPaid > 0 {
+		s.Balance = s.NetAmount() - s.AmountPaid
+	}
+	return nil
+}
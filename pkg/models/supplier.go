@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Purchase order statuses
+const (
+	PurchaseOrderDraft     = "draft"
+	PurchaseOrderOrdered   = "ordered"
+	PurchaseOrderReceived  = "received"
+	PurchaseOrderCancelled = "cancelled"
+)
+
+// PurchaseExpenseCategory is the expense category used for received purchase order lines without one
+const PurchaseExpenseCategory = "Inventory Purchases"
+
+// Supplier is a vendor the farm buys feed, vaccines, litter and other stock from
+type Supplier struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID        uint      `json:"user_id" gorm:"index;not null"`
+	Name          string    `json:"name" gorm:"type:varchar(255);not null"`
+	ContactPerson string    `json:"contact_person" gorm:"type:varchar(255)"`
+	PhoneNumber   string    `json:"phone_number" gorm:"type:varchar(50)"`
+	Email         string    `json:"email" gorm:"type:varchar(255)"`
+	Address       string    `json:"address" gorm:"type:varchar(255)"`
+	Notes         string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// PurchaseOrder is an order placed with a supplier. Receiving it posts stock and expenses.
+type PurchaseOrder struct {
+	ID           uint                `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uint                `json:"user_id" gorm:"index;not null"`
+	SupplierID   uint                `json:"supplier_id" gorm:"index;not null"`
+	OrderNo      string              `json:"order_no" gorm:"type:varchar(50);unique;not null"`
+	Status       string              `json:"status" gorm:"type:varchar(20);not null;default:'draft'"`
+	OrderDate    time.Time           `json:"order_date" gorm:"not null;type:date"`
+	ExpectedDate *time.Time          `json:"expected_date" gorm:"type:date"`
+	ReceivedDate *time.Time          `json:"received_date" gorm:"type:date"`
+	Total        float64             `json:"total" gorm:"not null;default:0"`
+	Notes        string              `json:"notes,omitempty" gorm:"type:text"`
+	Lines        []PurchaseOrderLine `json:"lines" gorm:"foreignKey:PurchaseOrderID;constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationship
+	Supplier *Supplier `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+}
+
+// PurchaseOrderLine is one inventory item ordered on a purchase order
+type PurchaseOrderLine struct {
+	ID              uint    `json:"id" gorm:"primaryKey;autoIncrement"`
+	PurchaseOrderID uint    `json:"purchase_order_id" gorm:"index;not null"`
+	InventoryItemID uint    `json:"inventory_item_id" gorm:"index;not null"`
+	Quantity        int     `json:"quantity" gorm:"not null"`
+	UnitCost        float64 `json:"unit_cost" gorm:"not null"`
+	Total           float64 `json:"total" gorm:"not null"`
+	ExpenseCategory string  `json:"expense_category" gorm:"type:varchar(50)"`
+
+	// Optional lot details; lines with a batch number are received into that lot
+	BatchNumber string     `json:"batch_number,omitempty" gorm:"type:varchar(100)"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty" gorm:"type:date"`
+}
+
+// SupplierSpend is the amount spent with one supplier over a period
+type SupplierSpend struct {
+	SupplierID   uint    `json:"supplier_id"`
+	SupplierName string  `json:"supplier_name"`
+	Orders       int     `json:"orders"`
+	TotalSpend   float64 `json:"total_spend"`
+}
+
+// BeforeCreate assigns an order number
+func (po *PurchaseOrder) BeforeCreate(tx *gorm.DB) error {
+	if po.OrderNo == "" {
+		po.OrderNo = fmt.Sprintf("PO-%d-%s", po.UserID, time.Now().Format("20060102150405.000"))
+	}
+	if po.Status == "" {
+		po.Status = PurchaseOrderDraft
+	}
+	return nil
+}
+
+// BeforeSave totals the order from its lines
+func (po *PurchaseOrder) BeforeSave(tx *gorm.DB) error {
+	po.Total = 0
+	for i := range po.Lines {
+		line := &po.Lines[i]
+		if line.Quantity <= 0 {
+			return fmt.Errorf("line %d: quantity must be greater than zero", i+1)
+		}
+		if line.UnitCost < 0 {
+			return fmt.Errorf("line %d: unit cost cannot be negative", i+1)
+		}
+		if line.BatchNumber != "" && line.ExpiryDate == nil {
+			return fmt.Errorf("line %d: expiry date is required for batch %s", i+1, line.BatchNumber)
+		}
+		line.Total = float64(line.Quantity) * line.UnitCost
+		po.Total += line.Total
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+// UnitEconomicsPeriod is what it cost a flock, or the farm, to produce over a month or a whole range.
+// Ratios are 0 when there is nothing to divide by.
+type UnitEconomicsPeriod struct {
+	Period            string  `json:"period"` // YYYY-MM for a month, "start to end" for a range
+	Costs             float64 `json:"costs"`
+	StockUsed         float64 `json:"stock_used"` // cost of feed and other stock used, part of Costs
+	Revenue           float64 `json:"revenue"`
+	EggSales          float64 `json:"egg_sales"`
+	OtherRevenue      float64 `json:"other_revenue"` // revenue other than egg sales, such as birds and manure
+	EggsCollected     int     `json:"eggs_collected"`
+	EggsSold          int     `json:"eggs_sold"`
+	FeedKg            float64 `json:"feed_kg"`
+	AverageBirds      float64 `json:"average_birds"` // average number of birds alive per day
+	CostPerEgg        float64 `json:"cost_per_egg"`
+	CostPerDozen      float64 `json:"cost_per_dozen"`
+	CostPerBird       float64 `json:"cost_per_bird"` // costs per average live bird
+	BreakEvenPerEgg   float64 `json:"break_even_per_egg"`
+	BreakEvenPerDozen float64 `json:"break_even_per_dozen"`
+	AvgEggPrice       float64 `json:"avg_egg_price"` // egg sales per egg sold
+}
+
+// UnitEconomics is a flock's unit costs and break-even egg price for a date range, month by month.
+// The break-even price is what each egg collected must sell for to cover costs not met by other revenue.
+type UnitEconomics struct {
+	FlockID   uint                  `json:"flock_id"` // 0 for the whole farm
+	FlockName string                `json:"flock_name"`
+	StartDate string                `json:"start_date"`
+	EndDate   string                `json:"end_date"`
+	Total     UnitEconomicsPeriod   `json:"total"`
+	Months    []UnitEconomicsPeriod `json:"months"`
+}
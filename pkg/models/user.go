@@ -62,6 +62,10 @@ type User struct {
 	OTPExpiresAt   *time.Time   `json:"-"`
 	NeedsSubscriptionRenewal bool `gorm:"-" json:"needs_subscription_renewal"`
 	EmailVerified  bool         `gorm:"default:false" json:"email_verified"`
+	EggUnit        string       `gorm:"type:varchar(10);default:'eggs'" json:"egg_unit"` // eggs, trays or crates
+	LowStockEmails bool         `gorm:"default:true" json:"low_stock_emails"` // email low-stock and expiry alerts as well as in-app notifications
+	ExpiryWarningDays int       `gorm:"default:30" json:"expiry_warning_days"` // warn about inventory lots expiring within this many days
+	CostingMethod  string       `gorm:"type:varchar(20);default:'weighted_average'" json:"costing_method"` // fifo or weighted_average
 
 	Subscription Subscription `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"subscription"`
 	BillingInfo  BillingInfo  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"billing_info"`
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 	
 )
@@ -17,5 +18,18 @@ type Vaccination struct {
 	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	Period              string    `json:"period"` // e.g., "monthly", "yearly"
+	InventoryLotID      *uint     `json:"inventory_lot_id,omitempty" gorm:"index"` // vaccine lot used, for traceability
+	DosesUsed           int       `json:"doses_used" gorm:"default:0"`              // doses drawn from the lot when done
+
+	// Relationship
+	InventoryLot        *InventoryLot `json:"inventory_lot,omitempty" gorm:"foreignKey:InventoryLotID"`
 }
 
+// IsDone reports whether the vaccination's status says it has been given
+func (v *Vaccination) IsDone() bool {
+	switch strings.ToLower(strings.TrimSpace(v.Status)) {
+	case "done", "completed", "administered":
+		return true
+	}
+	return false
+}
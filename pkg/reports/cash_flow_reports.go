@@ -0,0 +1,109 @@
+package reports
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+
+	"gorm.io/gorm"
+)
+
+type CashFlowPeriodSummary struct {
+	Period      string
+	OpeningCash string
+	Receipts    string
+	Refunds     string
+	Expenses    string
+	NetCashFlow string
+	ClosingCash string
+	Revenue     string
+}
+
+type CashFlowReportData struct {
+	Title      string
+	DateRange  string
+	Interval   string
+	User       string
+	Email      string
+	Contact    string
+	Summary    string
+	Periods    []CashFlowPeriodSummary
+	Totals     CashFlowPeriodSummary
+	Projection []CashFlowPeriodSummary
+}
+
+// GenerateCashFlowReport generates a PDF cash flow statement by week or month, with the projection
+// that follows it when the period runs to today
+func GenerateCashFlowReport(db *gorm.DB, userID uint, startDate, endDate time.Time, interval string, horizon int) (string, error) {
+	log.Println("Starting cash flow report generation...")
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve user details: %w", err)
+	}
+
+	statement, err := services.NewCashFlowService(db).GetCashFlow(userID, startDate, endDate, interval, horizon)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cash flow: %w", err)
+	}
+
+	label := "Monthly"
+	if statement.Interval == models.CashFlowWeekly {
+		label = "Weekly"
+	}
+
+	reportData := CashFlowReportData{
+		Title:     "Cash Flow Statement",
+		DateRange: fmt.Sprintf("%s to %s", statement.Start, statement.End),
+		Interval:  label,
+		User:      user.Username,
+		Email:     user.Email,
+		Contact:   user.PhoneNumber,
+		Summary: fmt.Sprintf("Opening cash of %s, %s received and %s paid out, closing at %s. Revenue earned in the period was %s.",
+			formatCurrency(statement.OpeningCash), formatCurrency(statement.Inflows), formatCurrency(statement.Outflows),
+			formatCurrency(statement.ClosingCash), formatCurrency(statement.Revenue)),
+		Periods:    cashFlowSummaries(statement.Periods),
+		Projection: cashFlowSummaries(statement.Projection),
+	}
+
+	totals := CashFlowPeriodSummary{
+		Period:      "Total",
+		OpeningCash: formatCurrency(statement.OpeningCash),
+		NetCashFlow: formatCurrency(statement.Inflows - statement.Outflows),
+		ClosingCash: formatCurrency(statement.ClosingCash),
+		Revenue:     formatCurrency(statement.Revenue),
+	}
+	var receipts, refunds, expenses float64
+	for _, period := range statement.Periods {
+		receipts += period.Receipts
+		refunds += period.Refunds
+		expenses += period.Expenses
+	}
+	totals.Receipts = formatCurrency(receipts)
+	totals.Refunds = formatCurrency(refunds)
+	totals.Expenses = formatCurrency(expenses)
+	reportData.Totals = totals
+
+	return renderReport(db, userID, "Cash Flow", "cash_flow_report", "cash_flow_report_template.html",
+		reportData, startDate, endDate)
+}
+
+func cashFlowSummaries(periods []models.CashFlowPeriod) []CashFlowPeriodSummary {
+	var summaries []CashFlowPeriodSummary
+	for _, period := range periods {
+		summaries = append(summaries, CashFlowPeriodSummary{
+			Period:      fmt.Sprintf("%s to %s", period.Start, period.End),
+			OpeningCash: formatCurrency(period.OpeningCash),
+			Receipts:    formatCurrency(period.Receipts),
+			Refunds:     formatCurrency(period.Refunds),
+			Expenses:    formatCurrency(period.Expenses),
+			NetCashFlow: formatCurrency(period.NetCashFlow),
+			ClosingCash: formatCurrency(period.ClosingCash),
+			Revenue:     formatCurrency(period.Revenue),
+		})
+	}
+	return summaries
+}
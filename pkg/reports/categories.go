@@ -0,0 +1,39 @@
+package reports
+
+import (
+	"birdseye-backend/pkg/models"
+	"sort"
+)
+
+// categoryTotals sums report amounts by category ID rather than by name
+type categoryTotals struct {
+	names  map[uint]string
+	totals map[uint]float64
+}
+
+func newCategoryTotals() *categoryTotals {
+	return &categoryTotals{names: make(map[uint]string), totals: make(map[uint]float64)}
+}
+
+func (t *categoryTotals) add(ref models.CategoryRef, amount float64) {
+	t.names[ref.CategoryID] = ref.Category
+	t.totals[ref.CategoryID] += amount
+}
+
+// ids returns the category IDs seen, in the taxonomy's order
+func (t *categoryTotals) ids() []uint {
+	ids := make([]uint, 0, len(t.totals))
+	for id := range t.totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// categoryLabel shows a category with its subcategory, if any
+func categoryLabel(ref models.CategoryRef) string {
+	if ref.Subcategory == "" {
+		return ref.Category
+	}
+	return ref.Category + " / " + ref.Subcategory
+}
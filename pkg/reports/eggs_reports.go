@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"birdseye-backend/pkg/models"
@@ -19,29 +20,41 @@ import (
 type EggProductionSummary struct {
 	FlockName    string
 	TotalEggs    int
+	EggsDisplay  string
 	TotalRevenue string
 }
 
 type FormattedEggProduction struct {
 	FlockName     string
 	EggsCollected int
+	EggsDisplay   string
+	Grades        string
 	PricePerUnit  string
 	TotalRevenue  string
 	FormattedDate string
 }
 
+type EggGradeSummary struct {
+	Grade        string
+	TotalEggs    int
+	EggsDisplay  string
+	TotalRevenue string
+}
+
 type EggProductionReportData struct {
-	Title          string
-	DateRange      string
-	User           string
-	Email          string
-	Contact        string
-	Summary        string
-	EggProductions []FormattedEggProduction
-	FlockSummaries []EggProductionSummary
-	TotalEggs      int
-	TotalRevenue   string
-	ChartImagePath string
+	Title            string
+	DateRange        string
+	User             string
+	Email            string
+	Contact          string
+	Summary          string
+	EggProductions   []FormattedEggProduction
+	FlockSummaries   []EggProductionSummary
+	GradeSummaries   []EggGradeSummary
+	TotalEggs        int
+	TotalEggsDisplay string
+	TotalRevenue     string
+	ChartImagePath   string
 }
 
 func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate time.Time) (string, error) {
@@ -63,6 +76,7 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 
 	// Struct to hold query results
 	var productions []struct {
+		ID            uint
 		FlockName     string
 		EggsCollected int
 		PricePerUnit  float64
@@ -72,7 +86,7 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 
 	// Perform the JOIN to fetch flock names
 	if err := db.Table("egg_productions").
-		Select("flocks.name AS flock_name, egg_productions.eggs_collected, egg_productions.price_per_unit, egg_productions.date_produced, (egg_productions.eggs_collected * egg_productions.price_per_unit) AS total_revenue").
+		Select("egg_productions.id, flocks.name AS flock_name, egg_productions.eggs_collected, egg_productions.price_per_unit, egg_productions.date_produced, (egg_productions.eggs_collected * egg_productions.price_per_unit) AS total_revenue").
 		Joins("JOIN flocks ON flocks.id = egg_productions.flock_id").
 		Where("egg_productions.user_id = ? AND egg_productions.date_produced BETWEEN ? AND ?", userID, startDate, endDate).
 		Scan(&productions).Error; err != nil {
@@ -82,8 +96,38 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 
 	log.Printf("Total records fetched: %d", len(productions))
 
+	// Grade breakdowns for graded records
+	productionIDs := make([]uint, 0, len(productions))
+	for _, production := range productions {
+		productionIDs = append(productionIDs, production.ID)
+	}
+	var gradeLines []models.EggGradeLine
+	if len(productionIDs) > 0 {
+		if err := db.Where("egg_production_id IN ?", productionIDs).Find(&gradeLines).Error; err != nil {
+			log.Println("Error fetching egg grade lines:", err)
+			return "", fmt.Errorf("failed to fetch egg grade lines: %w", err)
+		}
+	}
+	linesByProduction := make(map[uint][]models.EggGradeLine)
+	gradeEggs := make(map[string]int)
+	gradeRevenue := make(map[string]float64)
+	for _, line := range gradeLines {
+		linesByProduction[line.EggProductionID] = append(linesByProduction[line.EggProductionID], line)
+		gradeEggs[line.Grade] += line.Quantity
+		gradeRevenue[line.Grade] += float64(line.Quantity) * line.PricePerUnit
+	}
+
 	var formattedProductions []FormattedEggProduction
 	for _, production := range productions {
+		var grades []string
+		for _, line := range linesByProduction[production.ID] {
+			grades = append(grades, fmt.Sprintf("%s: %d @ %s", line.Grade, line.Quantity, formatCurrency(line.PricePerUnit)))
+		}
+		gradeText := "Ungraded"
+		if len(grades) > 0 {
+			gradeText = strings.Join(grades, ", ")
+		}
+
 		totalEggs += production.EggsCollected
 		totalRevenue += production.TotalRevenue
 		flockTotals[production.FlockName] += production.EggsCollected
@@ -92,6 +136,8 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 		formattedProductions = append(formattedProductions, FormattedEggProduction{
 			FlockName:     production.FlockName,
 			EggsCollected: production.EggsCollected,
+			EggsDisplay:   models.FormatEggCount(production.EggsCollected, user.EggUnit),
+			Grades:        gradeText,
 			PricePerUnit:  formatCurrency(production.PricePerUnit),
 			TotalRevenue:  formatCurrency(production.TotalRevenue),
 			FormattedDate: production.DateProduced.Format("Jan 2, 2006"),
@@ -106,6 +152,7 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 		flockSummaries = append(flockSummaries, EggProductionSummary{
 			FlockName:    flock,
 			TotalEggs:    eggs,
+			EggsDisplay:  models.FormatEggCount(eggs, user.EggUnit),
 			TotalRevenue: formatCurrency(revenueTotals[flock]),
 		})
 		found := false
@@ -124,6 +171,18 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 		}
 	}
 
+	var gradeSummaries []EggGradeSummary
+	for _, grade := range models.EggGrades {
+		if eggs, ok := gradeEggs[grade]; ok {
+			gradeSummaries = append(gradeSummaries, EggGradeSummary{
+				Grade:        grade,
+				TotalEggs:    eggs,
+				EggsDisplay:  models.FormatEggCount(eggs, user.EggUnit),
+				TotalRevenue: formatCurrency(gradeRevenue[grade]),
+			})
+		}
+	}
+
 	log.Printf("Total flocks: %d", len(flockTotals))
 
 	log.Printf("Total chart values: %d", len(chartValues))
@@ -140,17 +199,19 @@ func GenerateEggProductionReport(db *gorm.DB, userID uint, startDate, endDate ti
 	}
 
 	reportData := EggProductionReportData{
-		Title:          "Egg Production Report",
-		DateRange:      fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
-		User:           user.Username,
-		Email:          user.Email,
-		Contact:        user.PhoneNumber,
-		Summary:        fmt.Sprintf("Total eggs collected: %d", totalEggs),
-		EggProductions: formattedProductions,
-		FlockSummaries: flockSummaries,
-		TotalEggs:      totalEggs,
-		TotalRevenue:   formatCurrency(totalRevenue),
-		ChartImagePath: chartImagePath,
+		Title:            "Egg Production Report",
+		DateRange:        fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		User:             user.Username,
+		Email:            user.Email,
+		Contact:          user.PhoneNumber,
+		Summary:          fmt.Sprintf("Total eggs collected: %s", models.FormatEggCount(totalEggs, user.EggUnit)),
+		EggProductions:   formattedProductions,
+		FlockSummaries:   flockSummaries,
+		GradeSummaries:   gradeSummaries,
+		TotalEggs:        totalEggs,
+		TotalEggsDisplay: models.FormatEggCount(totalEggs, user.EggUnit),
+		TotalRevenue:     formatCurrency(totalRevenue),
+		ChartImagePath:   chartImagePath,
 	}
 
 	baseDir, _ := os.Getwd()
@@ -2,8 +2,10 @@ package reports
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -13,6 +15,7 @@ import (
 	"github.com/wcharczuk/go-chart/v2"
 	"gorm.io/gorm"
 	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/storage"
 	"github.com/dustin/go-humanize"
 )
 
@@ -26,6 +29,14 @@ type FormattedExpense struct {
 	Description     string
 	FormattedAmount string
 	FormattedDate   string // Store formatted date as a string
+	Attachments     []ExpenseReportAttachment
+}
+
+// ExpenseReportAttachment links a receipt from the report, with an inline thumbnail for images
+type ExpenseReportAttachment struct {
+	FileName  string
+	URL       string
+	Thumbnail template.URL // data URI, empty when the attachment has no thumbnail
 }
 
 type ExpenseReportData struct {
@@ -45,7 +56,7 @@ func GenerateExpenseReport(db *gorm.DB, userID uint, startDate, endDate time.Tim
 
 	// Fetch expenses and calculate totals per category.
 	var expenses []models.Expense
-	var categoryTotals = make(map[string]float64)
+	categoryTotals := newCategoryTotals()
 	var totalAmount float64
 
 	log.Println("Fetching user details...")
@@ -56,7 +67,7 @@ func GenerateExpenseReport(db *gorm.DB, userID uint, startDate, endDate time.Tim
 	}
 
 	log.Println("Fetching expenses from the database...")
-	if err := db.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Find(&expenses).Error; err != nil {
+	if err := db.Preload("Attachments").Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Find(&expenses).Error; err != nil {
 		log.Println("Error fetching expenses:", err)
 		return "", fmt.Errorf("failed to fetch expenses: %w", err)
 	}
@@ -65,13 +76,14 @@ func GenerateExpenseReport(db *gorm.DB, userID uint, startDate, endDate time.Tim
 	var formattedExpenses []FormattedExpense
 	for _, expense := range expenses {
 		totalAmount += expense.Amount
-		categoryTotals[expense.Category] += expense.Amount
+		categoryTotals.add(expense.CategoryRef, expense.Amount)
 	
 		formattedExpenses = append(formattedExpenses, FormattedExpense{
-			Category:        expense.Category,
+			Category:        categoryLabel(expense.CategoryRef),
 			Description:     expense.Description,
 			FormattedAmount: formatCurrency(expense.Amount),
 			FormattedDate:   expense.Date.Format("Jan 2, 2006"),
+			Attachments:     expenseReportAttachments(expense.Attachments),
 		})
 	}
 
@@ -79,7 +91,8 @@ func GenerateExpenseReport(db *gorm.DB, userID uint, startDate, endDate time.Tim
 	log.Println("Summarizing expense categories...")
 	var categorySummary []ExpenseCategorySummary
 	var chartValues []chart.Value
-	for category, total := range categoryTotals {
+	for _, id := range categoryTotals.ids() {
+		category, total := categoryTotals.names[id], categoryTotals.totals[id]
 		categorySummary = append(categorySummary, ExpenseCategorySummary{
 			Category: category,
 			Total:    formatCurrency(total),
@@ -179,6 +192,37 @@ func GenerateExpenseReport(db *gorm.DB, userID uint, startDate, endDate time.Tim
 	return pdfFilePath, nil
 }
 
+// expenseReportAttachments links an expense's attachments to the API and embeds their thumbnails
+func expenseReportAttachments(attachments []models.ExpenseAttachment) []ExpenseReportAttachment {
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/birdseye_backend"
+	}
+
+	var formatted []ExpenseReportAttachment
+	for _, attachment := range attachments {
+		item := ExpenseReportAttachment{
+			FileName: attachment.FileName,
+			URL:      fmt.Sprintf("%s/expenses/%d/attachments/%d", baseURL, attachment.ExpenseID, attachment.ID),
+		}
+		if attachment.ThumbnailKey != "" {
+			if file, err := storage.Default().Open(attachment.ThumbnailKey); err != nil {
+				log.Println("Error opening attachment thumbnail:", err)
+			} else {
+				thumbnail, err := io.ReadAll(file)
+				file.Close()
+				if err != nil {
+					log.Println("Error reading attachment thumbnail:", err)
+				} else {
+					item.Thumbnail = template.URL("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(thumbnail))
+				}
+			}
+		}
+		formatted = append(formatted, item)
+	}
+	return formatted
+}
+
 func generateExpenseChart(values []chart.Value) (string, error) {
     log.Println("Rendering expense chart...")
 
@@ -13,6 +13,7 @@ import (
 	
 	"gorm.io/gorm"
 	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
 )
 
 type FinancialCategorySummary struct {
@@ -45,6 +46,7 @@ type FinancialReportData struct {
 	TotalExpenses   string
 	TotalNetRevenue string
 	ChartImagePath  string
+	UnitEconomics   []UnitEconomicsSummary
 }
 
 func getMonthName(month int) string {
@@ -60,7 +62,7 @@ func getMonthName(month int) string {
 
 func getReportDateRange(month, year int) (time.Time, time.Time) {
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 0, 21) // First 21 days of the month
+	endDate := startDate.AddDate(0, 1, -1) // Last day of the month
 	return startDate, endDate
 }
 
@@ -114,13 +116,36 @@ func GenerateFinancialReport(db *gorm.DB, userID uint) (string, error) {
 
 
 
-		if startDate.IsZero() || data.Month < int(startDate.Month()) {
-			startDate, endDate = getReportDateRange(data.Month, data.Year)
+		monthStart, monthEnd := getReportDateRange(data.Month, data.Year)
+		if startDate.IsZero() || monthStart.Before(startDate) {
+			startDate = monthStart
+		}
+		if monthEnd.After(endDate) {
+			endDate = monthEnd
+		}
+	}
+
+	log.Println("Computing unit economics...")
+	yearStart := time.Date(currentYear, time.January, 1, 0, 0, 0, 0, time.Local)
+	var unitEconomics []UnitEconomicsSummary
+	var chartImagePath string
+	economics, err := services.NewUnitEconomicsService(db).GetUnitEconomics(userID, yearStart, time.Now())
+	if err != nil {
+		log.Println("Error computing unit economics:", err)
+	} else if len(economics) > 0 {
+		farm := economics[0]
+		for _, month := range farm.Months {
+			date, _ := time.ParseInLocation("2006-01", month.Period, time.Local)
+			unitEconomics = append(unitEconomics, unitEconomicsSummary(date.Format("January"), month))
+		}
+		unitEconomics = append(unitEconomics, unitEconomicsSummary("Year to date", farm.Total))
+
+		log.Println("Generating unit cost chart...")
+		if chartImagePath, err = generateUnitCostChart(userID, farm.Months); err != nil {
+			log.Println("Error generating unit cost chart:", err)
 		}
 	}
 
-	log.Println("Generating financial chart...")
-	
 
 
 	reportData := FinancialReportData{
@@ -136,6 +161,8 @@ func GenerateFinancialReport(db *gorm.DB, userID uint) (string, error) {
 		TotalEggSales:   formatCurrency(totalEggSales),
 		TotalExpenses:   formatCurrency(totalExpenses),
 		TotalNetRevenue: formatCurrency(totalNetRevenue),
+		ChartImagePath:  chartImagePath,
+		UnitEconomics:   unitEconomics,
 		
 	}
 
@@ -11,17 +11,21 @@ import (
 	"time"
 	 "math"
 	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
 
 
 	"gorm.io/gorm"
 )
 
 type FlockSummary struct {
-	Name          string
-	BirdCount     int
-	MortalityRate float64
-	Revenue       string
-	Expenses      string
+	Name               string
+	BirdCount          int
+	MortalityRate      float64
+	Revenue            string
+	Expenses           string
+	FeedKg             float64
+	FeedPerBirdPerDayG float64
+	FCREggs            float64
 }
 
 type FlockReportData struct {
@@ -35,6 +39,8 @@ type FlockReportData struct {
 	TotalBirds     int
 	ChartImagePath string
 	AvgMortalityRate float64
+	TotalFeedKg      float64
+	UnitEconomics    []UnitEconomicsSummary
 }
 func GenerateFlockReport(db *gorm.DB, userID uint, startDate, endDate time.Time) (string, error) {
 	log.Println("Starting flock report generation...")
@@ -62,20 +68,43 @@ func GenerateFlockReport(db *gorm.DB, userID uint, startDate, endDate time.Time)
 		return "", fmt.Errorf("no flocks found for user")
 	}
 
-	var totalMortalityRate float64
+	feedService := services.NewFeedService(db)
+	unitEconomicsService := services.NewUnitEconomicsService(db)
+	var unitEconomics []UnitEconomicsSummary
+
+	var totalMortalityRate, totalFeedKg float64
 	for _, flock := range flocks {
 		if flock.BirdCount == 0 {
 			log.Printf("Skipping flock %s with zero birds", flock.Name)
 			continue
 		}
 
+		// Feed usage and FCR over the report's date range
+		feed, err := feedService.GetFeedAnalytics(&flock, startDate, endDate, services.DefaultEggWeightKg)
+		if err != nil {
+			log.Printf("Error computing feed analytics for flock %s: %v", flock.Name, err)
+			feed = &models.FeedAnalytics{}
+		}
+		totalFeedKg += feed.TotalFeedKg
+
+		// Cost per egg and bird and the break-even egg price over the report's date range
+		economics, err := unitEconomicsService.GetFlockUnitEconomics(userID, flock.ID, startDate, endDate)
+		if err != nil {
+			log.Printf("Error computing unit economics for flock %s: %v", flock.Name, err)
+		} else {
+			unitEconomics = append(unitEconomics, unitEconomicsSummary(flock.Name, economics.Total))
+		}
+
 		totalBirds += flock.BirdCount
 		flockSummaries = append(flockSummaries, FlockSummary{
-			Name:          flock.Name,
-			BirdCount:     flock.BirdCount,
-			MortalityRate: flock.MortalityRate,
-			Revenue:       formatCurrency(flock.Revenue),
-			Expenses:      formatCurrency(flock.Expenses),
+			Name:               flock.Name,
+			BirdCount:          flock.BirdCount,
+			MortalityRate:      flock.MortalityRate,
+			Revenue:            formatCurrency(flock.Revenue),
+			Expenses:           formatCurrency(flock.Expenses),
+			FeedKg:             feed.TotalFeedKg,
+			FeedPerBirdPerDayG: feed.FeedPerBirdPerDayG,
+			FCREggs:            feed.FCREggs,
 		})
 
 		totalMortalityRate += flock.MortalityRate
@@ -105,6 +134,8 @@ func GenerateFlockReport(db *gorm.DB, userID uint, startDate, endDate time.Time)
 		Flocks:          flockSummaries,
 		TotalBirds:      totalBirds,
 		AvgMortalityRate: avgMortalityRate,
+		TotalFeedKg:      math.Round(totalFeedKg*100) / 100,
+		UnitEconomics:    unitEconomics,
 	}
 
 	// Template Processing
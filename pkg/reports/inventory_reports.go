@@ -8,11 +8,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/wcharczuk/go-chart/v2"
 	"gorm.io/gorm"
 	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
 )
 
 type InventorySummary struct {
@@ -21,6 +23,15 @@ type InventorySummary struct {
 	TotalCost string
 }
 
+type InventoryMovementRow struct {
+	FormattedDate string
+	ItemName      string
+	Type          string
+	QuantityDelta int
+	UnitCost      string
+	Reference     string
+}
+
 type InventoryReportData struct {
 	Title          string
 	DateRange      string
@@ -31,6 +42,8 @@ type InventoryReportData struct {
 	InventoryItems []InventorySummary
 	TotalValue     string
 	ChartImagePath string
+	MovementRange  string
+	Movements      []InventoryMovementRow
 }
 
 func GenerateInventoryReport(db *gorm.DB, userID uint, startDate, endDate time.Time) (string, error) {
@@ -52,11 +65,22 @@ func GenerateInventoryReport(db *gorm.DB, userID uint, startDate, endDate time.T
 		return "", fmt.Errorf("failed to fetch inventory items: %w", err)
 	}
 
+	// Value stock from its purchase movements under the user's costing method
+	valuation, err := services.NewInventoryService(db).GetValuation(userID, time.Now())
+	if err != nil {
+		log.Println("Error valuing inventory:", err)
+		return "", fmt.Errorf("failed to value inventory: %w", err)
+	}
+	itemValues := make(map[uint]float64)
+	for _, v := range valuation.Items {
+		itemValues[v.InventoryItemID] = v.Value
+	}
+
 	var formattedInventory []InventorySummary
 	var chartValues []chart.Value
 
 	for _, item := range inventoryItems {
-		itemTotalCost := float64(item.Quantity) * item.CostPerUnit
+		itemTotalCost := itemValues[item.ID]
 		totalValue += itemTotalCost
 
 		formattedInventory = append(formattedInventory, InventorySummary{
@@ -72,6 +96,31 @@ func GenerateInventoryReport(db *gorm.DB, userID uint, startDate, endDate time.T
 		
 	}
 
+	log.Println("Fetching inventory movements within the report range...")
+	itemNames := make(map[uint]string)
+	for _, item := range inventoryItems {
+		itemNames[item.ID] = item.ItemName
+	}
+
+	var movements []models.InventoryMovement
+	if err := db.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
+		Order("date ASC, id ASC").Find(&movements).Error; err != nil {
+		log.Println("Error fetching inventory movements:", err)
+		return "", fmt.Errorf("failed to fetch inventory movements: %w", err)
+	}
+
+	var movementRows []InventoryMovementRow
+	for _, movement := range movements {
+		movementRows = append(movementRows, InventoryMovementRow{
+			FormattedDate: movement.Date.Format("Jan 2, 2006"),
+			ItemName:      itemNames[movement.InventoryItemID],
+			Type:          movement.Type,
+			QuantityDelta: movement.QuantityDelta,
+			UnitCost:      formatCurrency(movement.UnitCost),
+			Reference:     movement.Reference,
+		})
+	}
+
 	log.Println("Generating inventory chart...")
 	chartImagePath, err := generateInventoryChart(chartValues)
 	if err != nil {
@@ -85,10 +134,12 @@ func GenerateInventoryReport(db *gorm.DB, userID uint, startDate, endDate time.T
 		User:           user.Username,
 		Email:          user.Email,
 		Contact:        user.PhoneNumber,
-		Summary:        fmt.Sprintf("Total inventory value: %s", formatCurrency(totalValue)),
+		Summary:        fmt.Sprintf("Total inventory value: %s (%s costing)", formatCurrency(totalValue), strings.ReplaceAll(valuation.CostingMethod, "_", " ")),
 		InventoryItems: formattedInventory,
 		TotalValue:     formatCurrency(totalValue),
 		ChartImagePath: chartImagePath,
+		MovementRange:  fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		Movements:      movementRows,
 	}
 
 	baseDir, _ := os.Getwd()
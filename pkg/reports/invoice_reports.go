@@ -0,0 +1,250 @@
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+
+	"gorm.io/gorm"
+)
+
+// Invoice document types. A document with nothing left to pay is issued as a receipt.
+const (
+	DocumentInvoice = "Invoice"
+	DocumentReceipt = "Receipt"
+)
+
+// Errors returned when the sales requested cannot go on one invoice
+var (
+	ErrInvoiceNoSales        = errors.New("no sales selected for the invoice")
+	ErrInvoiceSaleNotFound   = errors.New("sale not found")
+	ErrInvoiceMixedCustomers = errors.New("sales on one invoice must belong to the same customer")
+)
+
+var paymentMethodLabels = map[string]string{
+	models.PaymentMethodCash:  "Cash",
+	models.PaymentMethodMpesa: "M-Pesa",
+	models.PaymentMethodBank:  "Bank",
+}
+
+type InvoiceLine struct {
+	RefNo       string
+	Date        string
+	Description string
+	Quantity    string
+	UnitPrice   string
+	Amount      string
+}
+
+type InvoicePayment struct {
+	RefNo     string
+	Date      string
+	Method    string
+	Reference string
+	Amount    string
+}
+
+type InvoiceData struct {
+	Title        string
+	DocumentNo   string
+	IssueDate    string
+	DueDate      string
+	Status       string
+	FarmName     string
+	FarmEmail    string
+	FarmContact  string
+	CustomerName string
+	CustomerInfo []string
+	Lines        []InvoiceLine
+	Payments     []InvoicePayment
+	Total        string
+	Credited     string
+	Paid         string
+	BalanceDue   string
+
+	// Used when emailing the document
+	CustomerEmail string
+	Balance       float64
+}
+
+// GenerateSalesInvoice generates an invoice, or a receipt once fully paid, for one sale or for several sales
+// to the same customer. It returns the PDF's path and the data it was rendered from.
+func GenerateSalesInvoice(db *gorm.DB, userID uint, saleIDs []uint) (string, *InvoiceData, error) {
+	log.Println("Starting sales invoice generation...")
+
+	saleIDs = uniqueIDs(saleIDs)
+	if len(saleIDs) == 0 {
+		return "", nil, ErrInvoiceNoSales
+	}
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to retrieve user details: %w", err)
+	}
+
+	var sales []models.Sale
+	if err := db.Preload("Grades").Preload("Customer").
+		Preload("Payments", func(tx *gorm.DB) *gorm.DB { return tx.Order("date ASC, id ASC") }).
+		Where("id IN ? AND user_id = ?", saleIDs, userID).Order("date ASC, id ASC").Find(&sales).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to fetch sales: %w", err)
+	}
+	if len(sales) != len(saleIDs) {
+		return "", nil, ErrInvoiceSaleNotFound
+	}
+
+	customer := sales[0].Customer
+	for _, sale := range sales[1:] {
+		if sale.Customer == nil || customer == nil || sale.Customer.ID != customer.ID {
+			return "", nil, ErrInvoiceMixedCustomers
+		}
+	}
+
+	data := &InvoiceData{
+		IssueDate:    time.Now().Format("2006-01-02"),
+		FarmName:     user.Username,
+		FarmEmail:    user.Email,
+		FarmContact:  user.PhoneNumber,
+		CustomerName: "Cash customer",
+	}
+	if customer != nil {
+		data.CustomerName = customer.Name
+		data.CustomerEmail = customer.Email
+		for _, info := range []string{customer.PhoneNumber, customer.Email, customer.Address} {
+			if info != "" {
+				data.CustomerInfo = append(data.CustomerInfo, info)
+			}
+		}
+	}
+
+	eggSalesID, err := services.EggSalesCategoryID(db)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var total, credited, paid, balance float64
+	var dueDate *time.Time
+	for _, sale := range sales {
+		data.Lines = append(data.Lines, invoiceLines(sale, user.EggUnit, eggSalesID)...)
+		for _, p := range sale.Payments {
+			data.Payments = append(data.Payments, InvoicePayment{
+				RefNo:     sale.RefNo,
+				Date:      p.Date.Format("2006-01-02"),
+				Method:    paymentMethodLabel(p.Method),
+				Reference: p.Reference,
+				Amount:    formatCurrency(p.Amount),
+			})
+		}
+
+		total += sale.Amount
+		credited += sale.AmountReturned
+		paid += sale.NetAmount() - sale.Balance
+		balance += sale.Balance
+		if sale.Balance > 0 && sale.DueDate != nil && (dueDate == nil || sale.DueDate.Before(*dueDate)) {
+			dueDate = sale.DueDate
+		}
+	}
+
+	data.Title = DocumentInvoice
+	if balance <= 0.005 {
+		data.Title = DocumentReceipt
+		balance = 0
+	}
+	data.Status = invoiceStatus(paid, balance)
+	if dueDate != nil {
+		data.DueDate = dueDate.Format("2006-01-02")
+	}
+	data.Total = formatCurrency(total)
+	if credited > 0 {
+		data.Credited = formatCurrency(credited)
+	}
+	data.Paid = formatCurrency(paid)
+	data.BalanceDue = formatCurrency(balance)
+	data.Balance = balance
+
+	data.DocumentNo = sales[0].RefNo
+	if len(sales) > 1 {
+		data.DocumentNo = fmt.Sprintf("INV-%d-%d-%s", userID, customer.ID, time.Now().Format("20060102150405"))
+	}
+
+	filePrefix := strings.ToLower(data.Title) + "_" + strings.ReplaceAll(data.DocumentNo, "-", "_")
+	pdfPath, err := renderReport(db, userID, data.Title, filePrefix, "invoice_template.html",
+		data, sales[0].Date, sales[len(sales)-1].Date)
+	if err != nil {
+		return "", nil, err
+	}
+	return pdfPath, data, nil
+}
+
+// invoiceLines lists a sale's grade lines, or the sale itself when it is not broken down by grade
+func invoiceLines(sale models.Sale, eggUnit string, eggSalesID uint) []InvoiceLine {
+	quantity := func(q int) string {
+		if sale.CategoryID == eggSalesID {
+			return models.FormatEggCount(q, eggUnit)
+		}
+		return fmt.Sprintf("%d", q)
+	}
+
+	date := sale.Date.Format("2006-01-02")
+	if len(sale.Grades) == 0 {
+		description := sale.Product
+		if sale.Description != "" {
+			description = fmt.Sprintf("%s – %s", sale.Product, sale.Description)
+		}
+		return []InvoiceLine{{
+			RefNo:       sale.RefNo,
+			Date:        date,
+			Description: description,
+			Quantity:    quantity(sale.Quantity),
+			UnitPrice:   formatCurrency(sale.UnitPrice),
+			Amount:      formatCurrency(sale.Amount),
+		}}
+	}
+
+	var lines []InvoiceLine
+	for _, g := range sale.Grades {
+		lines = append(lines, InvoiceLine{
+			RefNo:       sale.RefNo,
+			Date:        date,
+			Description: fmt.Sprintf("%s – %s eggs", sale.Product, g.Grade),
+			Quantity:    quantity(g.Quantity),
+			UnitPrice:   formatCurrency(g.UnitPrice),
+			Amount:      formatCurrency(g.Amount),
+		})
+	}
+	return lines
+}
+
+func invoiceStatus(paid, balance float64) string {
+	switch {
+	case balance <= 0:
+		return "Paid"
+	case paid > 0:
+		return "Partially paid"
+	default:
+		return "Unpaid"
+	}
+}
+
+func paymentMethodLabel(method string) string {
+	if label, ok := paymentMethodLabels[method]; ok {
+		return label
+	}
+	return method
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var unique []uint
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
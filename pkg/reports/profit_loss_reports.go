@@ -0,0 +1,129 @@
+package reports
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+
+	"gorm.io/gorm"
+)
+
+type ProfitAndLossLineSummary struct {
+	Category       string
+	Amount         string
+	PreviousPeriod string
+	PreviousChange string
+	LastYear       string
+	LastYearChange string
+}
+
+type ProfitAndLossReportData struct {
+	Title         string
+	DateRange     string
+	PreviousRange string
+	LastYearRange string
+	Scope         string
+	User          string
+	Email         string
+	Contact       string
+	Summary       string
+	Revenue       []ProfitAndLossLineSummary
+	Costs         []ProfitAndLossLineSummary
+	Totals        []ProfitAndLossLineSummary
+}
+
+// GenerateProfitAndLossReport generates a PDF profit and loss statement for the period, for one flock or
+// the whole farm when flockID is 0, compared with the previous period and the same period last year
+func GenerateProfitAndLossReport(db *gorm.DB, userID, flockID uint, startDate, endDate time.Time) (string, error) {
+	log.Println("Starting profit and loss report generation...")
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve user details: %w", err)
+	}
+
+	statement, err := services.NewProfitAndLossService(db).GetProfitAndLoss(userID, flockID, startDate, endDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute profit and loss: %w", err)
+	}
+
+	scope := "All flocks"
+	if flockID != 0 {
+		var flock models.Flock
+		if err := db.Select("id, name").First(&flock, flockID).Error; err == nil {
+			scope = flock.Name
+		}
+	}
+
+	current, previous, lastYear := statement.Current, statement.PreviousPeriod, statement.LastYear
+	reportData := ProfitAndLossReportData{
+		Title:         "Profit and Loss Statement",
+		DateRange:     fmt.Sprintf("%s to %s", current.Start, current.End),
+		PreviousRange: fmt.Sprintf("%s to %s", previous.Start, previous.End),
+		LastYearRange: fmt.Sprintf("%s to %s", lastYear.Start, lastYear.End),
+		Scope:         scope,
+		User:          user.Username,
+		Email:         user.Email,
+		Contact:       user.PhoneNumber,
+		Summary: fmt.Sprintf("Revenue of %s against costs of %s, a net profit of %s",
+			formatCurrency(current.Revenue), formatCurrency(current.Costs), formatCurrency(current.NetProfit)),
+		Revenue: profitAndLossSummaries(statement.Revenue),
+		Costs:   profitAndLossSummaries(statement.Costs),
+		Totals: []ProfitAndLossLineSummary{
+			profitAndLossTotal("Total Revenue", current.Revenue, previous.Revenue, lastYear.Revenue),
+			profitAndLossTotal("Total Costs", current.Costs, previous.Costs, lastYear.Costs),
+			profitAndLossTotal("Net Profit", current.NetProfit, previous.NetProfit, lastYear.NetProfit),
+		},
+	}
+
+	return renderReport(db, userID, "Profit and Loss", "profit_loss_report", "profit_loss_report_template.html",
+		reportData, startDate, endDate)
+}
+
+func profitAndLossSummaries(lines []models.ProfitAndLossLine) []ProfitAndLossLineSummary {
+	var summaries []ProfitAndLossLineSummary
+	for _, line := range lines {
+		summaries = append(summaries, ProfitAndLossLineSummary{
+			Category:       line.Category,
+			Amount:         formatCurrency(line.Amount),
+			PreviousPeriod: formatCurrency(line.PreviousPeriod),
+			PreviousChange: formatChange(line.PreviousChange),
+			LastYear:       formatCurrency(line.LastYear),
+			LastYearChange: formatChange(line.LastYearChange),
+		})
+	}
+	return summaries
+}
+
+func profitAndLossTotal(label string, amount, previous, lastYear float64) ProfitAndLossLineSummary {
+	return ProfitAndLossLineSummary{
+		Category:       label,
+		Amount:         formatCurrency(amount),
+		PreviousPeriod: formatCurrency(previous),
+		PreviousChange: formatChange(changeFrom(amount, previous)),
+		LastYear:       formatCurrency(lastYear),
+		LastYearChange: formatChange(changeFrom(amount, lastYear)),
+	}
+}
+
+// changeFrom is the change from base to amount as a percentage of base, or nil when base is zero
+func changeFrom(amount, base float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	change := (amount - base) / base * 100
+	if base < 0 {
+		change = -change
+	}
+	return &change
+}
+
+func formatChange(change *float64) string {
+	if change == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", *change)
+}
@@ -0,0 +1,68 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"birdseye-backend/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// renderReport executes an HTML template from pkg/reports/templates, converts it to a PDF
+// with weasyprint and records it as a Report of reportType. It returns the PDF's path.
+func renderReport(db *gorm.DB, userID uint, reportType, filePrefix, templateName string, data interface{}, startDate, endDate time.Time) (string, error) {
+	baseDir, _ := os.Getwd()
+	templatePath := filepath.Join(baseDir, "pkg/reports/templates", templateName)
+	tmpl, err := template.ParseFiles(templatePath)
+	if err != nil {
+		log.Println("Error loading template:", err)
+		return "", fmt.Errorf("failed to load template: %w", err)
+	}
+
+	var htmlBuffer bytes.Buffer
+	if err := tmpl.Execute(&htmlBuffer, data); err != nil {
+		log.Println("Error executing template:", err)
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	outputDir := filepath.Join(baseDir, "pkg/reports/generated")
+	_ = os.MkdirAll(outputDir, os.ModePerm)
+	reportFilename := fmt.Sprintf("%s_%d.pdf", filePrefix, time.Now().Unix())
+	pdfFilePath := filepath.Join(outputDir, reportFilename)
+	relativePath := filepath.Join("pkg/reports/generated", reportFilename)
+
+	cmd := exec.Command("weasyprint", "-", pdfFilePath)
+	cmd.Stdin = bytes.NewReader(htmlBuffer.Bytes())
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Println("Error generating PDF:", err, "Details:", stderr.String())
+		return "", fmt.Errorf("failed to generate PDF: %v\nDetails: %s", err, stderr.String())
+	}
+
+	report := models.Report{
+		ReportType:  reportType,
+		GeneratedAt: time.Now(),
+		UserID:      userID,
+		Name:        reportFilename,
+		Content:     relativePath,
+		StartDate:   startDate,
+		EndDate:     endDate,
+	}
+	if err := db.Create(&report).Error; err != nil {
+		log.Println("Error saving report to database:", err)
+		return "", fmt.Errorf("failed to save report to database: %w", err)
+	}
+
+	log.Printf("%s report generated successfully: %s", reportType, pdfFilePath)
+	return pdfFilePath, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/wcharczuk/go-chart/v2"
 	"gorm.io/gorm"
 	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
 
 )
 
@@ -26,13 +27,23 @@ type FormattedSale struct {
 	Product         string
 	Category        string
 	Description     string
-	Quantity        int
+	Quantity        string
 	UnitPrice       string
 	FormattedAmount string
 	FormattedDate   string
 }
 
 
+type FormattedReturn struct {
+	CreditNoteNo    string
+	SaleRefNo       string
+	Product         string
+	Quantity        string
+	Reason          string
+	FormattedAmount string
+	FormattedDate   string
+}
+
 type SalesReportData struct {
 	Title           string
 	DateRange       string
@@ -43,6 +54,9 @@ type SalesReportData struct {
 	Sales           []FormattedSale
 	CategorySummary []SalesCategorySummary
 	TotalAmount     string
+	Returns         []FormattedReturn
+	TotalReturns    string
+	NetAmount       string
 	ChartImagePath  string
 }
 
@@ -66,8 +80,18 @@ func GenerateSalesReport(db *gorm.DB, userID uint, startDate, endDate time.Time)
 		return "", fmt.Errorf("failed to fetch sales: %w", err)
 	}
 
+	eggSalesID, err := services.EggSalesCategoryID(db)
+	if err != nil {
+		return "", err
+	}
+
 	var formattedSales []FormattedSale
 	for _, sale := range sales {
+		quantity := fmt.Sprintf("%d", sale.Quantity)
+		if sale.CategoryID == eggSalesID {
+			quantity = models.FormatEggCount(sale.Quantity, user.EggUnit)
+		}
+
 		totalAmount += sale.Amount
 		dateKey := sale.Date.Format("2006-01-02") // Format as YYYY-MM-DD
 		salesByDate[dateKey] += sale.Amount // Aggregate sales by date
@@ -75,15 +99,65 @@ func GenerateSalesReport(db *gorm.DB, userID uint, startDate, endDate time.Time)
 		formattedSales = append(formattedSales, FormattedSale{
 			RefNo:           sale.RefNo,
 			Product:         sale.Product,
-			Category:        sale.Category,
+			Category:        categoryLabel(sale.CategoryRef),
 			Description:     sale.Description,
-			Quantity:        sale.Quantity,
+			Quantity:        quantity,
 			UnitPrice:       formatCurrency(sale.UnitPrice),
 			FormattedAmount: formatCurrency(sale.Amount),
 			FormattedDate:   sale.Date.Format("Jan 2, 2006"),
 		})
 	}
 
+	log.Println("Fetching sales returns from the database...")
+	var returns []models.SaleReturn
+	if err := db.Preload("Sale").Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
+		Order("date").Find(&returns).Error; err != nil {
+		log.Println("Error fetching sales returns:", err)
+		return "", fmt.Errorf("failed to fetch sales returns: %w", err)
+	}
+
+	salesByCategory := newCategoryTotals()
+	for _, sale := range sales {
+		salesByCategory.add(sale.CategoryRef, sale.Amount)
+	}
+
+	// Net out credit notes raised in the period
+	var totalReturns float64
+	var formattedReturns []FormattedReturn
+	for _, ret := range returns {
+		product, saleRefNo, category := "", "", models.CategoryRef{}
+		quantity := fmt.Sprintf("%d", ret.Quantity)
+		if ret.Sale != nil {
+			product, saleRefNo, category = ret.Sale.Product, ret.Sale.RefNo, ret.Sale.CategoryRef
+			if category.CategoryID == eggSalesID {
+				quantity = models.FormatEggCount(ret.Quantity, user.EggUnit)
+			}
+		}
+
+		totalReturns += ret.Amount
+		salesByDate[ret.Date.Format("2006-01-02")] -= ret.Amount
+		salesByCategory.add(category, -ret.Amount)
+
+		formattedReturns = append(formattedReturns, FormattedReturn{
+			CreditNoteNo:    ret.CreditNoteNo,
+			SaleRefNo:       saleRefNo,
+			Product:         product,
+			Quantity:        quantity,
+			Reason:          ret.Reason,
+			FormattedAmount: formatCurrency(ret.Amount),
+			FormattedDate:   ret.Date.Format("Jan 2, 2006"),
+		})
+	}
+
+	var categorySummary []SalesCategorySummary
+	for _, id := range salesByCategory.ids() {
+		categorySummary = append(categorySummary, SalesCategorySummary{
+			Category: salesByCategory.names[id],
+			Total:    formatCurrency(salesByCategory.totals[id]),
+		})
+	}
+	netAmount := totalAmount - totalReturns
+
 	log.Println("Generating sales trend chart...")
 	chartImagePath, err := generateSalesTrendChart(salesByDate, startDate, endDate)
 	if err != nil {
@@ -97,9 +171,13 @@ func GenerateSalesReport(db *gorm.DB, userID uint, startDate, endDate time.Time)
 		User:            user.Username,
 		Email:           user.Email,
 		Contact:         user.PhoneNumber,
-		Summary:         fmt.Sprintf("Total sales recorded: %s", formatCurrency(totalAmount)),
+		Summary:         fmt.Sprintf("Total sales recorded: %s, less returns of %s, for net sales of %s", formatCurrency(totalAmount), formatCurrency(totalReturns), formatCurrency(netAmount)),
 		Sales:           formattedSales,
+		CategorySummary: categorySummary,
 		TotalAmount:     formatCurrency(totalAmount),
+		Returns:         formattedReturns,
+		TotalReturns:    formatCurrency(totalReturns),
+		NetAmount:       formatCurrency(netAmount),
 		ChartImagePath:  chartImagePath,
 	}
 
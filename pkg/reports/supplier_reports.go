@@ -0,0 +1,116 @@
+package reports
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services"
+
+	"gorm.io/gorm"
+)
+
+type SupplierSpendSummary struct {
+	Name       string
+	Orders     int
+	TotalSpend string
+	Share      float64
+}
+
+type SupplierOrderSummary struct {
+	OrderNo      string
+	Supplier     string
+	ReceivedDate string
+	Total        string
+}
+
+type SupplierSpendReportData struct {
+	Title       string
+	DateRange   string
+	User        string
+	Email       string
+	Contact     string
+	Summary     string
+	Suppliers   []SupplierSpendSummary
+	Orders      []SupplierOrderSummary
+	TotalOrders int
+	TotalSpend  string
+}
+
+// GenerateSupplierSpendReport generates a PDF of spend per supplier and the purchase orders received in the period
+func GenerateSupplierSpendReport(db *gorm.DB, userID uint, startDate, endDate time.Time) (string, error) {
+	log.Println("Starting supplier spend report generation...")
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve user details: %w", err)
+	}
+
+	spend, err := services.NewSupplierService(db).GetSupplierSpend(userID, startDate, endDate)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute supplier spend: %w", err)
+	}
+	if len(spend) == 0 {
+		return "", fmt.Errorf("no supplier spend found in the selected period")
+	}
+
+	var total float64
+	var totalOrders int
+	for _, s := range spend {
+		total += s.TotalSpend
+		totalOrders += s.Orders
+	}
+
+	var suppliers []SupplierSpendSummary
+	for _, s := range spend {
+		share := 0.0
+		if total > 0 {
+			share = math.Round(s.TotalSpend/total*1000) / 10
+		}
+		suppliers = append(suppliers, SupplierSpendSummary{
+			Name:       s.SupplierName,
+			Orders:     s.Orders,
+			TotalSpend: formatCurrency(s.TotalSpend),
+			Share:      share,
+		})
+	}
+
+	var received []models.PurchaseOrder
+	if err := db.Preload("Supplier").
+		Where("user_id = ? AND status = ? AND received_date BETWEEN ? AND ?", userID, models.PurchaseOrderReceived, startDate, endDate).
+		Order("received_date").Find(&received).Error; err != nil {
+		return "", fmt.Errorf("failed to fetch purchase orders: %w", err)
+	}
+
+	var orders []SupplierOrderSummary
+	for _, po := range received {
+		supplierName := ""
+		if po.Supplier != nil {
+			supplierName = po.Supplier.Name
+		}
+		orders = append(orders, SupplierOrderSummary{
+			OrderNo:      po.OrderNo,
+			Supplier:     supplierName,
+			ReceivedDate: po.ReceivedDate.Format("2006-01-02"),
+			Total:        formatCurrency(po.Total),
+		})
+	}
+
+	reportData := SupplierSpendReportData{
+		Title:       "Supplier Spend Report",
+		DateRange:   fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		User:        user.Username,
+		Email:       user.Email,
+		Contact:     user.PhoneNumber,
+		Summary:     fmt.Sprintf("Total spend of %s across %d suppliers", formatCurrency(total), len(spend)),
+		Suppliers:   suppliers,
+		Orders:      orders,
+		TotalOrders: totalOrders,
+		TotalSpend:  formatCurrency(total),
+	}
+
+	return renderReport(db, userID, "Supplier Spend", "supplier_spend_report", "supplier_spend_report_template.html",
+		reportData, startDate, endDate)
+}
@@ -0,0 +1,126 @@
+package reports
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"birdseye-backend/pkg/models"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// UnitEconomicsSummary is a row of unit costs and the break-even egg price, formatted for a report
+type UnitEconomicsSummary struct {
+	Label             string
+	EggsCollected     int
+	AverageBirds      float64
+	Costs             string
+	CostPerEgg        string
+	CostPerDozen      string
+	CostPerBird       string
+	BreakEvenPerEgg   string
+	BreakEvenPerDozen string
+	AvgEggPrice       string
+}
+
+func unitEconomicsSummary(label string, period models.UnitEconomicsPeriod) UnitEconomicsSummary {
+	return UnitEconomicsSummary{
+		Label:             label,
+		EggsCollected:     period.EggsCollected,
+		AverageBirds:      period.AverageBirds,
+		Costs:             formatCurrency(period.Costs),
+		CostPerEgg:        formatCurrency(period.CostPerEgg),
+		CostPerDozen:      formatCurrency(period.CostPerDozen),
+		CostPerBird:       formatCurrency(period.CostPerBird),
+		BreakEvenPerEgg:   formatCurrency(period.BreakEvenPerEgg),
+		BreakEvenPerDozen: formatCurrency(period.BreakEvenPerDozen),
+		AvgEggPrice:       formatCurrency(period.AvgEggPrice),
+	}
+}
+
+// generateUnitCostChart draws the monthly cost per egg against the break-even and average selling prices.
+// It returns an empty path when there are fewer than two months to draw a trend from.
+func generateUnitCostChart(userID uint, months []models.UnitEconomicsPeriod) (string, error) {
+	if len(months) < 2 {
+		return "", nil
+	}
+	log.Println("Rendering unit cost chart...")
+
+	baseDir, _ := os.Getwd()
+	outputDir := filepath.Join(baseDir, "pkg/reports/generated")
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	chartImagePath := filepath.Join(outputDir, fmt.Sprintf("unit_cost_chart_%d.png", userID))
+
+	var xValues []time.Time
+	var costs, breakEven, prices []float64
+	for _, month := range months {
+		date, err := time.ParseInLocation("2006-01", month.Period, time.Local)
+		if err != nil {
+			return "", fmt.Errorf("invalid month %q: %w", month.Period, err)
+		}
+		xValues = append(xValues, date)
+		costs = append(costs, month.CostPerEgg)
+		breakEven = append(breakEven, month.BreakEvenPerEgg)
+		prices = append(prices, month.AvgEggPrice)
+	}
+
+	graph := chart.Chart{
+		Title: "Cost per Egg by Month",
+		TitleStyle: chart.Style{
+			FontSize:  14,
+			FontColor: chart.ColorBlack,
+		},
+		Width:  800,
+		Height: 500,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:  40,
+				Left: 20,
+			},
+		},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				Name:    "Cost per Egg",
+				XValues: xValues,
+				YValues: costs,
+				Style:   chart.Style{StrokeColor: chart.ColorBlue, StrokeWidth: 2},
+			},
+			chart.TimeSeries{
+				Name:    "Break-even per Egg",
+				XValues: xValues,
+				YValues: breakEven,
+				Style:   chart.Style{StrokeColor: chart.ColorRed, StrokeWidth: 2},
+			},
+			chart.TimeSeries{
+				Name:    "Average Selling Price",
+				XValues: xValues,
+				YValues: prices,
+				Style:   chart.Style{StrokeColor: chart.ColorGreen, StrokeWidth: 2},
+			},
+		},
+		XAxis: chart.XAxis{
+			Name:           "Month",
+			ValueFormatter: chart.TimeValueFormatterWithFormat("Jan 2006"),
+		},
+		YAxis: chart.YAxis{
+			Name: "KES per Egg",
+		},
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	file, err := os.Create(chartImagePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create chart file: %w", err)
+	}
+	defer file.Close()
+
+	if err := graph.Render(chart.PNG, file); err != nil {
+		return "", fmt.Errorf("failed to render chart: %w", err)
+	}
+	return chartImagePath, nil
+}
@@ -465,6 +465,68 @@ func UpdateUserProfile(userID uint, username, email, phonenumber string) (*model
 	return &user, nil
 }
 
+// PreferencesUpdate holds the display and alert preferences a user can change.
+// Empty or nil values leave the current preference unchanged.
+type PreferencesUpdate struct {
+	EggUnit           string `json:"egg_unit"`
+	LowStockEmails    *bool  `json:"low_stock_emails"`
+	ExpiryWarningDays *int   `json:"expiry_warning_days"`
+	CostingMethod     string `json:"costing_method"`
+}
+
+// UpdateUserPreferences updates the user's display and alert preferences
+func UpdateUserPreferences(userID uint, prefs PreferencesUpdate) (*models.User, error) {
+	if prefs.EggUnit != "" && !models.IsValidEggUnit(prefs.EggUnit) {
+		return nil, fmt.Errorf("invalid egg unit '%s'", prefs.EggUnit)
+	}
+	if prefs.ExpiryWarningDays != nil && *prefs.ExpiryWarningDays < 1 {
+		return nil, fmt.Errorf("expiry warning days must be at least 1")
+	}
+	if prefs.CostingMethod != "" && !models.IsValidCostingMethod(prefs.CostingMethod) {
+		return nil, fmt.Errorf("invalid costing method '%s'", prefs.CostingMethod)
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	updates := map[string]interface{}{}
+	if prefs.EggUnit != "" {
+		updates["egg_unit"] = prefs.EggUnit
+		user.EggUnit = prefs.EggUnit
+	}
+	if prefs.LowStockEmails != nil {
+		updates["low_stock_emails"] = *prefs.LowStockEmails
+		user.LowStockEmails = *prefs.LowStockEmails
+	}
+	if prefs.ExpiryWarningDays != nil {
+		updates["expiry_warning_days"] = *prefs.ExpiryWarningDays
+		user.ExpiryWarningDays = *prefs.ExpiryWarningDays
+	}
+	costingChanged := prefs.CostingMethod != "" && prefs.CostingMethod != user.CostingMethod
+	if prefs.CostingMethod != "" {
+		updates["costing_method"] = prefs.CostingMethod
+		user.CostingMethod = prefs.CostingMethod
+	}
+	if len(updates) == 0 {
+		return &user, nil
+	}
+
+	if err := db.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("error updating preferences: %w", err)
+	}
+
+	// Stock used is costed under the costing method, so the ledger's stock entries are posted again
+	if costingChanged {
+		if err := NewLedgerService(db.DB).RepostInventory(user.ID); err != nil {
+			log.Printf("Error reposting inventory ledger for user %d: %v", user.ID, err)
+		}
+	}
+
+	return &user, nil
+}
+
 // UpdateUserProfilePicture updates user's profile picture path
 func UpdateUserProfilePicture(userID uint, profilePicturePath string) (*models.User, error) { // userID is now uint
 	var user models.User
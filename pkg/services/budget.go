@@ -0,0 +1,445 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BudgetService compares flock budgets with actual spend and alerts users as budgets run out
+type BudgetService struct {
+	DB *gorm.DB
+}
+
+// NewBudgetService initializes a new service instance
+func NewBudgetService(db *gorm.DB) *BudgetService {
+	return &BudgetService{DB: db}
+}
+
+// budgetKey identifies one flock's budget month
+type budgetKey struct {
+	FlockID uint
+	Year    int
+	Month   int
+}
+
+// flockSpend is what a flock spent in a month, in total and by expense category
+type flockSpend struct {
+	Total      float64
+	ByCategory map[uint]float64
+	Names      map[uint]string
+}
+
+// GetVariance returns budget, actual spend, variance and percent used for each flock and month of a year,
+// or of one month when month is set. Actual spend is every expense dated in the month, including the
+// flock's share of farm-level expenses and stock purchases rather than the cost of stock used. A flock
+// without an overall budget is budgeted at the sum of its category budgets. flockID limits the result
+// to one flock and byCategory adds a breakdown by category.
+func (s *BudgetService) GetVariance(userID uint, year, month int, flockID uint, byCategory bool) ([]models.BudgetVariance, error) {
+	start, end := budgetPeriod(year, month)
+
+	query := s.DB.Where("user_id = ? AND year = ?", userID, year)
+	if month != 0 {
+		query = query.Where("month = ?", month)
+	}
+	if flockID != 0 {
+		query = query.Where("flock_id = ?", flockID)
+	}
+	var budgets []models.Budget
+	if err := query.Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+
+	spend, err := s.spend(userID, flockID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetsByKey := make(map[budgetKey][]models.Budget)
+	for _, budget := range budgets {
+		key := budgetKey{FlockID: budget.FlockID, Year: budget.Year, Month: budget.Month}
+		budgetsByKey[key] = append(budgetsByKey[key], budget)
+	}
+	keys := make([]budgetKey, 0, len(budgetsByKey)+len(spend))
+	for key := range budgetsByKey {
+		keys = append(keys, key)
+	}
+	for key := range spend {
+		if _, ok := budgetsByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Year != keys[j].Year {
+			return keys[i].Year < keys[j].Year
+		}
+		if keys[i].Month != keys[j].Month {
+			return keys[i].Month < keys[j].Month
+		}
+		return keys[i].FlockID < keys[j].FlockID
+	})
+
+	flockNames, err := s.flockNames(userID)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames, err := s.budgetCategoryNames(budgets)
+	if err != nil {
+		return nil, err
+	}
+
+	variances := make([]models.BudgetVariance, 0, len(keys))
+	for _, key := range keys {
+		actual := spend[key]
+		if actual == nil {
+			actual = &flockSpend{ByCategory: map[uint]float64{}, Names: map[uint]string{}}
+		}
+
+		var overall, categoryTotal float64
+		hasOverall := false
+		categoryBudgets := make(map[uint]float64)
+		for _, budget := range budgetsByKey[key] {
+			if budget.CategoryID == nil {
+				overall += budget.Amount
+				hasOverall = true
+				continue
+			}
+			categoryBudgets[*budget.CategoryID] += budget.Amount
+			categoryTotal += budget.Amount
+		}
+		if !hasOverall {
+			overall = categoryTotal
+		}
+
+		variance := models.BudgetVariance{
+			FlockID:     key.FlockID,
+			FlockName:   flockNames[key.FlockID],
+			Month:       key.Month,
+			Year:        key.Year,
+			Budget:      roundTo(overall, 2),
+			Actual:      roundTo(actual.Total, 2),
+			Variance:    roundTo(overall-actual.Total, 2),
+			PercentUsed: percentUsed(actual.Total, overall),
+		}
+
+		if byCategory {
+			ids := make([]uint, 0, len(actual.ByCategory)+len(categoryBudgets))
+			for id := range actual.ByCategory {
+				ids = append(ids, id)
+			}
+			for id := range categoryBudgets {
+				if _, ok := actual.ByCategory[id]; !ok {
+					ids = append(ids, id)
+				}
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+			for _, id := range ids {
+				name := actual.Names[id]
+				if name == "" {
+					name = categoryNames[id]
+				}
+				variance.Categories = append(variance.Categories, models.BudgetCategoryVariance{
+					CategoryID:  id,
+					Category:    name,
+					Budget:      roundTo(categoryBudgets[id], 2),
+					Actual:      roundTo(actual.ByCategory[id], 2),
+					Variance:    roundTo(categoryBudgets[id]-actual.ByCategory[id], 2),
+					PercentUsed: percentUsed(actual.ByCategory[id], categoryBudgets[id]),
+				})
+			}
+		}
+		variances = append(variances, variance)
+	}
+	return variances, nil
+}
+
+// CheckExpenseAlerts checks the budgets touched by the given expenses, before and after a change,
+// against the alert thresholds. Failures are logged since the expenses are already saved.
+func (s *BudgetService) CheckExpenseAlerts(expenses ...models.Expense) {
+	checked := make(map[budgetKey]bool)
+	for _, expense := range expenses {
+		var flockIDs []uint
+		if expense.FlockID != nil {
+			flockIDs = append(flockIDs, *expense.FlockID)
+		}
+		for _, allocation := range expense.Allocations {
+			flockIDs = append(flockIDs, allocation.FlockID)
+		}
+
+		for _, flockID := range flockIDs {
+			key := budgetKey{FlockID: flockID, Year: expense.Date.Year(), Month: int(expense.Date.Month())}
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+			if err := s.CheckAlerts(expense.UserID, flockID, key.Year, key.Month); err != nil {
+				log.Printf("Error checking budget alerts for flock %d: %v", flockID, err)
+			}
+		}
+	}
+}
+
+// CheckAlerts notifies the user when a flock's spend for a month crosses 80% or 100% of one of its budgets.
+// Each threshold is notified once; if spend falls back below it, crossing it again notifies again.
+func (s *BudgetService) CheckAlerts(userID, flockID uint, year, month int) error {
+	var budgets []models.Budget
+	if err := s.DB.Where("user_id = ? AND flock_id = ? AND year = ? AND month = ?", userID, flockID, year, month).
+		Find(&budgets).Error; err != nil {
+		return err
+	}
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	start, end := budgetPeriod(year, month)
+	spend, err := s.spend(userID, flockID, start, end)
+	if err != nil {
+		return err
+	}
+	actual := spend[budgetKey{FlockID: flockID, Year: year, Month: month}]
+	if actual == nil {
+		actual = &flockSpend{ByCategory: map[uint]float64{}, Names: map[uint]string{}}
+	}
+	categoryNames, err := s.budgetCategoryNames(budgets)
+	if err != nil {
+		return err
+	}
+
+	for _, budget := range budgets {
+		spent, scope := actual.Total, ""
+		if budget.CategoryID != nil {
+			spent = actual.ByCategory[*budget.CategoryID]
+			scope = categoryNames[*budget.CategoryID] + " "
+		}
+
+		level := 0
+		if budget.Amount > 0 {
+			switch used := spent / budget.Amount * 100; {
+			case used >= models.BudgetExceededPercent:
+				level = models.BudgetExceededPercent
+			case used >= models.BudgetWarningPercent:
+				level = models.BudgetWarningPercent
+			}
+		}
+		if level == budget.AlertLevel {
+			continue
+		}
+		// Move the level only if nobody else has, so concurrent writers alert once
+		result := s.DB.Model(&models.Budget{}).
+			Where("id = ? AND alert_level = ?", budget.ID, budget.AlertLevel).
+			UpdateColumn("alert_level", level)
+		if result.Error != nil {
+			return result.Error
+		}
+		if level < budget.AlertLevel || result.RowsAffected == 0 {
+			continue
+		}
+
+		var flock models.Flock
+		if err := s.DB.Select("id, name").First(&flock, flockID).Error; err != nil {
+			return err
+		}
+		period := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local).Format("January 2006")
+		title, message := "Budget Warning", fmt.Sprintf("%s has used %.0f%% of its %s %sbudget (KES %.2f of KES %.2f).",
+			flock.Name, percentUsed(spent, budget.Amount), period, scope, spent, budget.Amount)
+		if level == models.BudgetExceededPercent {
+			title, message = "Budget Exceeded", fmt.Sprintf("%s has exceeded its %s %sbudget: KES %.2f spent of KES %.2f.",
+				flock.Name, period, scope, spent, budget.Amount)
+		}
+
+		notification := models.Notification{
+			UserID: userID,
+			Title:  title,
+			Body:   message,
+			Type:   "warning",
+			URL:    "/expenses",
+		}
+		if level == models.BudgetExceededPercent {
+			notification.Type = "error"
+		}
+		if err := NewNotificationService(s.DB).CreateNotification(&notification); err != nil {
+			log.Printf("Error saving budget notification for budget %d: %v", budget.ID, err)
+		}
+		broadcast.SendNotification(userID, title, message, "/expenses")
+	}
+	return nil
+}
+
+// GetMonthTotals returns the user's overall flock budgets and actual spend for a month
+func (s *BudgetService) GetMonthTotals(userID uint, year, month int) (budget, actual float64, err error) {
+	variances, err := s.GetVariance(userID, year, month, 0, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, variance := range variances {
+		budget += variance.Budget
+		actual += variance.Actual
+	}
+	return roundTo(budget, 2), roundTo(actual, 2), nil
+}
+
+// GetFlockBudget returns a flock's budget for a month: its overall budget, or the sum of its
+// category budgets when it has none
+func (s *BudgetService) GetFlockBudget(userID, flockID uint, year, month int) (float64, error) {
+	var budgets []models.Budget
+	if err := s.DB.Where("user_id = ? AND flock_id = ? AND year = ? AND month = ?", userID, flockID, year, month).
+		Find(&budgets).Error; err != nil {
+		return 0, err
+	}
+
+	var overall, categoryTotal float64
+	hasOverall := false
+	for _, budget := range budgets {
+		if budget.CategoryID == nil {
+			overall += budget.Amount
+			hasOverall = true
+		} else {
+			categoryTotal += budget.Amount
+		}
+	}
+	if !hasOverall {
+		overall = categoryTotal
+	}
+	return roundTo(overall, 2), nil
+}
+
+// SetFlockBudget creates or updates a flock's overall budget for a month
+func (s *BudgetService) SetFlockBudget(userID, flockID uint, year, month int, amount float64) (*models.Budget, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("invalid month %d", month)
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("budget cannot be negative")
+	}
+	var flock models.Flock
+	if err := s.DB.Where("id = ? AND user_id = ?", flockID, userID).First(&flock).Error; err != nil {
+		return nil, fmt.Errorf("flock not found")
+	}
+
+	var budget models.Budget
+	err := s.DB.Where("user_id = ? AND flock_id = ? AND year = ? AND month = ? AND category_id IS NULL",
+		userID, flockID, year, month).First(&budget).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	budget.UserID = int(userID)
+	budget.FlockID = flockID
+	budget.Year = year
+	budget.Month = month
+	budget.Amount = amount
+	if err := s.DB.Omit("Flock").Save(&budget).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.CheckAlerts(userID, flockID, year, month); err != nil {
+		log.Printf("Error checking budget alerts for flock %d: %v", flockID, err)
+	}
+	QueueFinancialRecompute(userID, flockID, time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local))
+	return &budget, nil
+}
+
+// spend totals each flock's expenses by month between start and end, including shares of farm-level expenses.
+// Stock is counted as purchased, since the cost of stock used has no expense category to budget against.
+func (s *BudgetService) spend(userID, flockID uint, start, end time.Time) (map[budgetKey]*flockSpend, error) {
+	type row struct {
+		FlockID    uint
+		Date       time.Time
+		Amount     float64
+		CategoryID uint
+		Category   string
+	}
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	var direct []row
+	query := s.DB.Model(&models.Expense{}).Select("flock_id, date, amount, category_id, category").
+		Where("user_id = ? AND flock_id IS NOT NULL AND date BETWEEN ? AND ?", userID, from, to)
+	if flockID != 0 {
+		query = query.Where("flock_id = ?", flockID)
+	}
+	if err := query.Scan(&direct).Error; err != nil {
+		return nil, err
+	}
+
+	var shared []row
+	query = s.DB.Model(&models.ExpenseAllocation{}).
+		Select("expense_allocations.flock_id, expenses.date, expense_allocations.amount, expenses.category_id, expenses.category").
+		Joins("JOIN expenses ON expenses.id = expense_allocations.expense_id").
+		Where("expense_allocations.user_id = ? AND expenses.date BETWEEN ? AND ?", userID, from, to)
+	if flockID != 0 {
+		query = query.Where("expense_allocations.flock_id = ?", flockID)
+	}
+	if err := query.Scan(&shared).Error; err != nil {
+		return nil, err
+	}
+
+	spend := make(map[budgetKey]*flockSpend)
+	for _, r := range append(direct, shared...) {
+		key := budgetKey{FlockID: r.FlockID, Year: r.Date.Year(), Month: int(r.Date.Month())}
+		entry := spend[key]
+		if entry == nil {
+			entry = &flockSpend{ByCategory: map[uint]float64{}, Names: map[uint]string{}}
+			spend[key] = entry
+		}
+		entry.Total += r.Amount
+		entry.ByCategory[r.CategoryID] += r.Amount
+		entry.Names[r.CategoryID] = r.Category
+	}
+	return spend, nil
+}
+
+func (s *BudgetService) flockNames(userID uint) (map[uint]string, error) {
+	var flocks []models.Flock
+	if err := s.DB.Select("id, name").Where("user_id = ?", userID).Find(&flocks).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(flocks))
+	for _, flock := range flocks {
+		names[flock.ID] = flock.Name
+	}
+	return names, nil
+}
+
+// budgetCategoryNames names the categories that budgets are set for
+func (s *BudgetService) budgetCategoryNames(budgets []models.Budget) (map[uint]string, error) {
+	var ids []uint
+	for _, budget := range budgets {
+		if budget.CategoryID != nil {
+			ids = append(ids, *budget.CategoryID)
+		}
+	}
+	names := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	var categories []models.Category
+	if err := s.DB.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
+
+// budgetPeriod returns the first and last day of a budget month, or of the whole year when month is 0
+func budgetPeriod(year, month int) (time.Time, time.Time) {
+	if month == 0 {
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local), time.Date(year, time.December, 31, 0, 0, 0, 0, time.Local)
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 1, -1)
+}
+
+func percentUsed(actual, budget float64) float64 {
+	if budget <= 0 {
+		return 0
+	}
+	return roundTo(actual/budget*100, 2)
+}
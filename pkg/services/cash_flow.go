@@ -0,0 +1,303 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CashFlowService builds cash flow statements from the dates money was received and paid out
+type CashFlowService struct {
+	DB *gorm.DB
+}
+
+// NewCashFlowService initializes a new service instance
+func NewCashFlowService(db *gorm.DB) *CashFlowService {
+	return &CashFlowService{DB: db}
+}
+
+// cashDays holds amounts by day, keyed YYYY-MM-DD
+type cashDays struct {
+	Receipts map[string]float64
+	Refunds  map[string]float64
+	Expenses map[string]float64
+	Revenue  map[string]float64
+}
+
+func newCashDays() cashDays {
+	return cashDays{
+		Receipts: map[string]float64{},
+		Refunds:  map[string]float64{},
+		Expenses: map[string]float64{},
+		Revenue:  map[string]float64{},
+	}
+}
+
+// GetCashFlow returns the user's cash flow by week or month between start and end, with the end date
+// capped at today. Sales count as cash when their payments are received, or on the sale date when they
+// were marked paid without recording instalments; credit notes count as refunds for the part that leaves
+// a sale overpaid. Expenses are paid on their date.
+//
+// When the statement runs to today it is followed by horizon projected periods of recurring expenses
+// and credit sales falling due, with overdue balances expected at the start.
+func (s *CashFlowService) GetCashFlow(userID uint, start, end time.Time, interval string, horizon int) (*models.CashFlow, error) {
+	if interval == "" {
+		interval = models.CashFlowMonthly
+	}
+	if interval != models.CashFlowWeekly && interval != models.CashFlowMonthly {
+		return nil, fmt.Errorf("interval must be %s or %s", models.CashFlowWeekly, models.CashFlowMonthly)
+	}
+	today := truncateDay(time.Now())
+	start, end = truncateDay(start), truncateDay(end)
+	if end.After(today) {
+		end = today
+	}
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	before, err := s.cashByDay(userID, time.Time{}, start.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	opening := 0.0
+	for _, amount := range before.Receipts {
+		opening += amount
+	}
+	for _, amount := range before.Refunds {
+		opening -= amount
+	}
+	for _, amount := range before.Expenses {
+		opening -= amount
+	}
+
+	days, err := s.cashByDay(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &models.CashFlow{
+		Interval:    interval,
+		Start:       start.Format("2006-01-02"),
+		End:         end.Format("2006-01-02"),
+		OpeningCash: roundTo(opening, 2),
+		Projection:  []models.CashFlowPeriod{},
+	}
+	statement.Periods = cashFlowPeriods(start, end, interval, statement.OpeningCash, days, false)
+	statement.ClosingCash = statement.OpeningCash
+	for _, period := range statement.Periods {
+		statement.Inflows += period.Inflows
+		statement.Outflows += period.Outflows
+		statement.Revenue += period.Revenue
+		statement.ClosingCash = period.ClosingCash
+	}
+	statement.Inflows = roundTo(statement.Inflows, 2)
+	statement.Outflows = roundTo(statement.Outflows, 2)
+	statement.Revenue = roundTo(statement.Revenue, 2)
+
+	if end.Equal(today) && horizon > 0 {
+		projectionStart := today.AddDate(0, 0, 1)
+		projectionEnd := projectionStart
+		for i := 0; i < horizon; i++ {
+			projectionEnd = cashFlowPeriodEnd(projectionEnd, interval)
+			if i < horizon-1 {
+				projectionEnd = projectionEnd.AddDate(0, 0, 1)
+			}
+		}
+
+		expected, err := s.projectedByDay(userID, projectionStart, projectionEnd)
+		if err != nil {
+			return nil, err
+		}
+		statement.Projection = cashFlowPeriods(projectionStart, projectionEnd, interval, statement.ClosingCash, expected, true)
+	}
+	return statement, nil
+}
+
+// cashByDay totals receipts, refunds, expenses and revenue by day between from and to. A zero from
+// covers everything up to to.
+func (s *CashFlowService) cashByDay(userID uint, from, to time.Time) (cashDays, error) {
+	days := newCashDays()
+	settled := func(query *gorm.DB) *gorm.DB {
+		return query.Where("sales.status = ? AND sales.amount_paid = 0", models.SaleStatusPaid)
+	}
+
+	sums := []struct {
+		query *gorm.DB
+		table string
+		into  map[string]float64
+		sign  float64
+	}{
+		{s.DB.Model(&models.SalePayment{}).Where("user_id = ?", userID), "sale_payments", days.Receipts, 1},
+		{settled(s.DB.Model(&models.Sale{}).Where("user_id = ?", userID)), "sales", days.Receipts, 1},
+		{s.DB.Model(&models.Expense{}).Where("user_id = ?", userID), "expenses", days.Expenses, 1},
+		{s.DB.Model(&models.Sale{}).Where("user_id = ?", userID), "sales", days.Revenue, 1},
+		{s.DB.Model(&models.SaleReturn{}).Where("user_id = ?", userID), "sale_returns", days.Revenue, -1},
+	}
+	for _, sum := range sums {
+		query := sum.query
+		if !from.IsZero() {
+			query = query.Where(sum.table+".date >= ?", from)
+		}
+
+		var rows []struct {
+			Day    time.Time
+			Amount float64
+		}
+		if err := query.Where(sum.table+".date <= ?", endOfDay(to)).
+			Select(fmt.Sprintf("DATE(%[1]s.date) AS day, SUM(%[1]s.amount) AS amount", sum.table)).
+			Group(fmt.Sprintf("DATE(%s.date)", sum.table)).Scan(&rows).Error; err != nil {
+			return days, err
+		}
+		for _, row := range rows {
+			sum.into[row.Day.Format("2006-01-02")] += sum.sign * row.Amount
+		}
+	}
+
+	if err := s.refundsByDay(userID, from, to, days.Refunds); err != nil {
+		return days, err
+	}
+	return days, nil
+}
+
+// refundsByDay adds the cash refunded with credit notes dated between from and to. Refunds depend on
+// what was paid on the sale before each credit note, so each sale's history is replayed.
+func (s *CashFlowService) refundsByDay(userID uint, from, to time.Time, into map[string]float64) error {
+	query := s.DB.Model(&models.SaleReturn{}).Where("user_id = ? AND date <= ?", userID, endOfDay(to))
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	var saleIDs []uint
+	if err := query.Distinct().Pluck("sale_id", &saleIDs).Error; err != nil {
+		return err
+	}
+	if len(saleIDs) == 0 {
+		return nil
+	}
+
+	var sales []models.Sale
+	if err := s.DB.Preload("Payments").Where("id IN ?", saleIDs).Find(&sales).Error; err != nil {
+		return err
+	}
+	var returns []models.SaleReturn
+	if err := s.DB.Where("sale_id IN ?", saleIDs).Order("date ASC, id ASC").Find(&returns).Error; err != nil {
+		return err
+	}
+	bySale := make(map[uint][]models.SaleReturn, len(sales))
+	for _, ret := range returns {
+		bySale[ret.SaleID] = append(bySale[ret.SaleID], ret)
+	}
+
+	for i := range sales {
+		saleReturns := bySale[sales[i].ID]
+		for j, refund := range saleRefunds(&sales[i], saleReturns) {
+			date := truncateDay(saleReturns[j].Date)
+			if refund == 0 || date.After(to) || (!from.IsZero() && date.Before(from)) {
+				continue
+			}
+			into[date.Format("2006-01-02")] += refund
+		}
+	}
+	return nil
+}
+
+// projectedByDay places active recurring expenses on the days they will post, and the balances of open
+// credit sales on their due dates, between from and to. Occurrences already due but not yet posted, and
+// balances already overdue, fall on from.
+func (s *CashFlowService) projectedByDay(userID uint, from, to time.Time) (cashDays, error) {
+	days := newCashDays()
+
+	var templates []models.RecurringExpense
+	if err := s.DB.Preload("Exceptions").Where("user_id = ? AND paused = ? AND next_date <= ?",
+		userID, false, to.Format("2006-01-02")).Find(&templates).Error; err != nil {
+		return days, err
+	}
+	for i := range templates {
+		template := &templates[i]
+		exceptions := exceptionsByDate(template)
+		for date := truncateDay(template.NextDate); !date.After(to) && withinEnd(template, date); date = nextOccurrence(template, date) {
+			amount := template.Amount
+			if exception, ok := exceptions[date.Format("2006-01-02")]; ok {
+				if exception.Skip {
+					continue
+				}
+				if exception.Amount != nil {
+					amount = *exception.Amount
+				}
+			}
+			day := date
+			if day.Before(from) {
+				day = from
+			}
+			days.Expenses[day.Format("2006-01-02")] += amount
+		}
+	}
+
+	var sales []models.Sale
+	if err := s.DB.Where("user_id = ? AND status <> ? AND due_date <= ?",
+		userID, models.SaleStatusPaid, to.Format("2006-01-02")).Find(&sales).Error; err != nil {
+		return days, err
+	}
+	for _, sale := range sales {
+		if sale.Balance <= 0 {
+			continue
+		}
+		day := truncateDay(*sale.DueDate)
+		if day.Before(from) {
+			day = from
+		}
+		days.Receipts[day.Format("2006-01-02")] += sale.Balance
+	}
+	return days, nil
+}
+
+// cashFlowPeriods splits start to end into weeks or months, the first and last cut to the range,
+// and carries cash forward from opening
+func cashFlowPeriods(start, end time.Time, interval string, opening float64, days cashDays, projected bool) []models.CashFlowPeriod {
+	periods := []models.CashFlowPeriod{}
+	cash := opening
+	for periodStart := start; !periodStart.After(end); {
+		periodEnd := cashFlowPeriodEnd(periodStart, interval)
+		if periodEnd.After(end) {
+			periodEnd = end
+		}
+
+		period := models.CashFlowPeriod{
+			Start:       periodStart.Format("2006-01-02"),
+			End:         periodEnd.Format("2006-01-02"),
+			Projected:   projected,
+			OpeningCash: roundTo(cash, 2),
+		}
+		for day := periodStart; !day.After(periodEnd); day = day.AddDate(0, 0, 1) {
+			key := day.Format("2006-01-02")
+			period.Receipts += days.Receipts[key]
+			period.Refunds += days.Refunds[key]
+			period.Expenses += days.Expenses[key]
+			period.Revenue += days.Revenue[key]
+		}
+		period.Receipts = roundTo(period.Receipts, 2)
+		period.Refunds = roundTo(period.Refunds, 2)
+		period.Expenses = roundTo(period.Expenses, 2)
+		period.Revenue = roundTo(period.Revenue, 2)
+		period.Inflows = period.Receipts
+		period.Outflows = roundTo(period.Refunds+period.Expenses, 2)
+		period.NetCashFlow = roundTo(period.Inflows-period.Outflows, 2)
+		period.ClosingCash = roundTo(period.OpeningCash+period.NetCashFlow, 2)
+		cash = period.ClosingCash
+
+		periods = append(periods, period)
+		periodStart = periodEnd.AddDate(0, 0, 1)
+	}
+	return periods
+}
+
+// cashFlowPeriodEnd returns the last day of the week, ending Sunday, or month containing t
+func cashFlowPeriodEnd(t time.Time, interval string) time.Time {
+	if interval == models.CashFlowWeekly {
+		return t.AddDate(0, 0, 6-(int(t.Weekday())+6)%7)
+	}
+	return firstOfMonth(t).AddDate(0, 1, -1)
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownCategory is returned when an expense, sale or recurring expense names a category the user does not have
+var ErrUnknownCategory = errors.New("unknown category")
+
+// CategoryService manages the expense and sale category taxonomy
+type CategoryService struct {
+	DB *gorm.DB
+}
+
+// NewCategoryService initializes a new service instance
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	return &CategoryService{DB: db}
+}
+
+// GetCategories returns the system categories of a kind, or of both kinds when kind is empty,
+// each with the user's subcategories beneath it
+func (s *CategoryService) GetCategories(userID uint, kind string) ([]models.Category, error) {
+	query := s.DB.Preload("Subcategories", func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_id = ?", userID).Order("name ASC")
+	}).Where("user_id IS NULL AND parent_id IS NULL")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var categories []models.Category
+	err := query.Order("kind ASC, id ASC").Find(&categories).Error
+	return categories, err
+}
+
+// AddSubcategory creates a user subcategory beneath a system category
+func (s *CategoryService) AddSubcategory(userID uint, sub *models.Category) error {
+	sub.Name = strings.TrimSpace(sub.Name)
+	if sub.Name == "" || len(sub.Name) > 50 {
+		return errors.New("name is required and must be at most 50 characters")
+	}
+	if sub.ParentID == nil {
+		return errors.New("parent_id is required")
+	}
+
+	var parent models.Category
+	if err := s.DB.Where("id = ? AND user_id IS NULL AND parent_id IS NULL", *sub.ParentID).First(&parent).Error; err != nil {
+		return errors.New("parent category not found")
+	}
+	if err := s.checkNameFree(userID, parent.ID, 0, sub.Name); err != nil {
+		return err
+	}
+
+	sub.ID = 0
+	sub.UserID = &userID
+	sub.Kind = parent.Kind
+	sub.Subcategories = nil
+	return s.DB.Create(sub).Error
+}
+
+// RenameSubcategory renames a user subcategory and the records filed under it
+func (s *CategoryService) RenameSubcategory(id, userID uint, name string) (*models.Category, error) {
+	sub, err := s.getSubcategory(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 50 {
+		return nil, errors.New("name is required and must be at most 50 characters")
+	}
+	if err := s.checkNameFree(userID, *sub.ParentID, sub.ID, name); err != nil {
+		return nil, err
+	}
+
+	sub.Name = name
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(sub).UpdateColumn("name", name).Error; err != nil {
+			return err
+		}
+		for _, model := range categorizedModels(sub.Kind) {
+			if err := tx.Model(model).Where("subcategory_id = ?", sub.ID).UpdateColumn("subcategory", name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteSubcategory removes a user subcategory. Records filed under it move up to its parent category.
+func (s *CategoryService) DeleteSubcategory(id, userID uint) error {
+	sub, err := s.getSubcategory(id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range categorizedModels(sub.Kind) {
+			if err := tx.Model(model).Where("subcategory_id = ?", sub.ID).UpdateColumns(map[string]interface{}{
+				"subcategory_id": nil,
+				"subcategory":    "",
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(sub).Error
+	})
+}
+
+// Resolve validates a category choice for a user and fills in both its IDs and names.
+// A subcategory ID wins over a category ID, which wins over names. A category name may also be
+// the name of one of the user's subcategories, which files the record under its parent.
+func (s *CategoryService) Resolve(userID uint, kind string, ref models.CategoryRef) (models.CategoryRef, error) {
+	visible := func() *gorm.DB {
+		return s.DB.Where("kind = ? AND (user_id IS NULL OR user_id = ?)", kind, userID)
+	}
+
+	var root, sub *models.Category
+	var chosen models.Category
+	switch {
+	case ref.SubcategoryID != nil && *ref.SubcategoryID != 0:
+		if err := visible().Where("id = ? AND parent_id IS NOT NULL", *ref.SubcategoryID).First(&chosen).Error; err != nil {
+			return ref, fmt.Errorf("%w: subcategory %d", ErrUnknownCategory, *ref.SubcategoryID)
+		}
+	case ref.CategoryID != 0:
+		if err := visible().Where("id = ?", ref.CategoryID).First(&chosen).Error; err != nil {
+			return ref, fmt.Errorf("%w: category %d", ErrUnknownCategory, ref.CategoryID)
+		}
+	default:
+		name := strings.ToLower(strings.TrimSpace(ref.Category))
+		if name == "" {
+			return ref, fmt.Errorf("%w: category is required", ErrUnknownCategory)
+		}
+		if err := visible().Where("LOWER(name) = ?", name).
+			Order("parent_id IS NOT NULL ASC, id ASC").First(&chosen).Error; err != nil {
+			return ref, fmt.Errorf("%w '%s'", ErrUnknownCategory, strings.TrimSpace(ref.Category))
+		}
+	}
+
+	if chosen.ParentID == nil {
+		root = &chosen
+	} else {
+		sub = &chosen
+		var parent models.Category
+		if err := s.DB.First(&parent, *chosen.ParentID).Error; err != nil {
+			return ref, err
+		}
+		root = &parent
+	}
+
+	if name := strings.ToLower(strings.TrimSpace(ref.Subcategory)); sub == nil && name != "" {
+		var named models.Category
+		if err := s.DB.Where("user_id = ? AND parent_id = ? AND LOWER(name) = ?", userID, root.ID, name).
+			First(&named).Error; err != nil {
+			return ref, fmt.Errorf("%w: no subcategory '%s' under %s", ErrUnknownCategory, strings.TrimSpace(ref.Subcategory), root.Name)
+		}
+		sub = &named
+	}
+
+	resolved := models.CategoryRef{CategoryID: root.ID, Category: root.Name}
+	if sub != nil {
+		resolved.SubcategoryID = &sub.ID
+		resolved.Subcategory = sub.Name
+	}
+	return resolved, nil
+}
+
+func (s *CategoryService) getSubcategory(id, userID uint) (*models.Category, error) {
+	var sub models.Category
+	if err := s.DB.Where("id = ? AND user_id = ? AND parent_id IS NOT NULL", id, userID).First(&sub).Error; err != nil {
+		return nil, errors.New("subcategory not found")
+	}
+	return &sub, nil
+}
+
+// checkNameFree rejects a subcategory name already used by another of the user's subcategories under the same parent
+func (s *CategoryService) checkNameFree(userID, parentID, exceptID uint, name string) error {
+	var count int64
+	if err := s.DB.Model(&models.Category{}).
+		Where("user_id = ? AND parent_id = ? AND id <> ? AND LOWER(name) = ?", userID, parentID, exceptID, strings.ToLower(name)).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("a subcategory named '%s' already exists", name)
+	}
+	return nil
+}
+
+// applyCategory resolves a record's category in place
+func applyCategory(tx *gorm.DB, userID uint, kind string, ref *models.CategoryRef) error {
+	resolved, err := NewCategoryService(tx).Resolve(userID, kind, *ref)
+	if err != nil {
+		return err
+	}
+	*ref = resolved
+	return nil
+}
+
+// EggSalesCategoryID returns the ID of the Egg Sales system category, whose sales draw eggs from the store.
+// Resolve it once and compare CategoryID rather than matching category names.
+func EggSalesCategoryID(tx *gorm.DB) (uint, error) {
+	var category models.Category
+	if err := tx.Select("id").Where("user_id IS NULL AND parent_id IS NULL AND kind = ? AND name = ?",
+		models.CategoryKindSale, models.EggSalesCategory).First(&category).Error; err != nil {
+		return 0, fmt.Errorf("egg sales category: %w", err)
+	}
+	return category.ID, nil
+}
+
+// isEggSale reports whether a sale with resolved categories is filed under Egg Sales
+func isEggSale(tx *gorm.DB, sale *models.Sale) (bool, error) {
+	eggSalesID, err := EggSalesCategoryID(tx)
+	if err != nil {
+		return false, err
+	}
+	return sale.CategoryID == eggSalesID, nil
+}
+
+// categorizedModels lists the models filed under categories of a kind
+func categorizedModels(kind string) []interface{} {
+	if kind == models.CategoryKindSale {
+		return []interface{}{&models.Sale{}}
+	}
+	return []interface{}{&models.Expense{}, &models.RecurringExpense{}}
+}
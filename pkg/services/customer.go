@@ -0,0 +1,234 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CustomerService manages customers and their receivables
+type CustomerService struct {
+	DB *gorm.DB
+}
+
+// NewCustomerService initializes a new service instance
+func NewCustomerService(db *gorm.DB) *CustomerService {
+	return &CustomerService{DB: db}
+}
+
+// GetCustomers returns a user's customers ordered by name, each with its outstanding balance
+func (s *CustomerService) GetCustomers(userID uint) ([]models.Customer, error) {
+	var customers []models.Customer
+	if err := s.DB.Where("user_id = ?", userID).Order("name").Find(&customers).Error; err != nil {
+		return nil, err
+	}
+
+	var balances []struct {
+		CustomerID  uint
+		Outstanding float64
+	}
+	if err := s.openSales(userID).Select("customer_id, SUM(amount - amount_returned - amount_paid) AS outstanding").
+		Where("customer_id IS NOT NULL").Group("customer_id").Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+	outstanding := make(map[uint]float64, len(balances))
+	for _, b := range balances {
+		outstanding[b.CustomerID] = b.Outstanding
+	}
+	for i := range customers {
+		customers[i].Outstanding = roundTo(outstanding[customers[i].ID], 2)
+	}
+	return customers, nil
+}
+
+// GetCustomer returns a single customer owned by the user with its outstanding balance
+func (s *CustomerService) GetCustomer(id, userID uint) (*models.Customer, error) {
+	var customer models.Customer
+	if err := s.DB.Where("id = ? AND user_id = ?", id, userID).First(&customer).Error; err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	var outstanding float64
+	if err := s.openSales(userID).Where("customer_id = ?", id).
+		Select("COALESCE(SUM(amount - amount_returned - amount_paid), 0)").Scan(&outstanding).Error; err != nil {
+		return nil, err
+	}
+	customer.Outstanding = roundTo(outstanding, 2)
+	return &customer, nil
+}
+
+// GetOpenSales returns a customer's sales that still have a balance, oldest first
+func (s *CustomerService) GetOpenSales(customerID, userID uint) ([]models.Sale, error) {
+	if _, err := s.GetCustomer(customerID, userID); err != nil {
+		return nil, err
+	}
+
+	var sales []models.Sale
+	err := s.openSales(userID).Preload("Payments").Where("customer_id = ?", customerID).
+		Order("date ASC, id ASC").Find(&sales).Error
+	return sales, err
+}
+
+// AddCustomer creates a customer
+func (s *CustomerService) AddCustomer(customer *models.Customer) error {
+	if customer.Name == "" {
+		return errors.New("customer name is required")
+	}
+	if customer.CreditDays < 0 {
+		return errors.New("credit days cannot be negative")
+	}
+	return s.DB.Create(customer).Error
+}
+
+// UpdateCustomer saves changes to a customer owned by the user
+func (s *CustomerService) UpdateCustomer(customer *models.Customer) error {
+	existing, err := s.GetCustomer(customer.ID, customer.UserID)
+	if err != nil {
+		return err
+	}
+	if customer.Name == "" {
+		return errors.New("customer name is required")
+	}
+	if customer.CreditDays < 0 {
+		return errors.New("credit days cannot be negative")
+	}
+	customer.CreatedAt = existing.CreatedAt
+	if err := s.DB.Save(customer).Error; err != nil {
+		return err
+	}
+	customer.Outstanding = existing.Outstanding
+	return nil
+}
+
+// DeleteCustomer removes a customer that has no sales, along with their price overrides
+func (s *CustomerService) DeleteCustomer(id, userID uint) error {
+	if _, err := s.GetCustomer(id, userID); err != nil {
+		return err
+	}
+
+	var sales int64
+	if err := s.DB.Model(&models.Sale{}).Where("customer_id = ?", id).Count(&sales).Error; err != nil {
+		return err
+	}
+	if sales > 0 {
+		return errors.New("customer has sales and cannot be deleted")
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", id).Delete(&models.PriceListItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Customer{}, id).Error
+	})
+}
+
+// GetAgedReceivables buckets every balance open at the end of asOf by days since the sale, grouped by
+// customer with the largest balances first. Balances count only payments and credit notes dated on or
+// before asOf.
+func (s *CustomerService) GetAgedReceivables(userID uint, asOf time.Time) (*models.AgedReceivables, error) {
+	cutoff := endOfDay(asOf)
+	settledLater := func(model interface{}) *gorm.DB {
+		return s.DB.Model(model).Select("sale_id").Where("user_id = ? AND date > ?", userID, cutoff)
+	}
+
+	// Sales open now, plus those paid or credited after asOf that may still have been open then.
+	// Sales marked paid without payment records were settled when made.
+	var sales []models.Sale
+	if err := s.DB.Preload("Customer").Where("user_id = ? AND date <= ?", userID, cutoff).
+		Where("NOT (status = ? AND amount_paid = 0)", models.SaleStatusPaid).
+		Where(s.DB.Where("status <> ? AND amount - amount_returned - amount_paid > 0.005", models.SaleStatusPaid).
+			Or("id IN (?)", settledLater(&models.SalePayment{})).
+			Or("id IN (?)", settledLater(&models.SaleReturn{}))).
+		Find(&sales).Error; err != nil {
+		return nil, err
+	}
+
+	saleIDs := make([]uint, 0, len(sales))
+	for _, sale := range sales {
+		saleIDs = append(saleIDs, sale.ID)
+	}
+	paid, err := s.totalsBySale(&models.SalePayment{}, saleIDs, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	returned, err := s.totalsBySale(&models.SaleReturn{}, saleIDs, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AgedReceivables{AsOf: truncateDay(asOf), Customers: []models.CustomerAging{}}
+	byCustomer := map[uint]*models.CustomerAging{}
+	for _, sale := range sales {
+		balance := roundTo(sale.Amount-returned[sale.ID]-paid[sale.ID], 2)
+		if balance <= 0.005 {
+			continue
+		}
+
+		var customerID uint
+		if sale.CustomerID != nil {
+			customerID = *sale.CustomerID
+		}
+
+		entry, ok := byCustomer[customerID]
+		if !ok {
+			entry = &models.CustomerAging{CustomerID: customerID, CustomerName: "No customer"}
+			if sale.Customer != nil {
+				entry.CustomerName = sale.Customer.Name
+				entry.PhoneNumber = sale.Customer.PhoneNumber
+			}
+			byCustomer[customerID] = entry
+		}
+
+		age := daysBetween(sale.Date, asOf)
+		entry.Add(age, balance)
+		entry.OpenSales++
+		report.Totals.Add(age, balance)
+	}
+
+	for _, entry := range byCustomer {
+		roundAgingBuckets(&entry.AgingBuckets)
+		report.Customers = append(report.Customers, *entry)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool {
+		return report.Customers[i].Total > report.Customers[j].Total
+	})
+	roundAgingBuckets(&report.Totals)
+
+	return report, nil
+}
+
+// openSales scopes a query to a user's sales that are not marked paid and still have a balance after returns
+// totalsBySale sums the amounts of payments or credit notes (model) on the given sales dated up to cutoff
+func (s *CustomerService) totalsBySale(model interface{}, saleIDs []uint, cutoff time.Time) (map[uint]float64, error) {
+	totals := map[uint]float64{}
+	if len(saleIDs) == 0 {
+		return totals, nil
+	}
+	var rows []struct {
+		SaleID uint
+		Total  float64
+	}
+	if err := s.DB.Model(model).Select("sale_id, SUM(amount) AS total").
+		Where("sale_id IN ? AND date <= ?", saleIDs, cutoff).Group("sale_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		totals[row.SaleID] = row.Total
+	}
+	return totals, nil
+}
+
+func (s *CustomerService) openSales(userID uint) *gorm.DB {
+	return s.DB.Model(&models.Sale{}).
+		Where("user_id = ? AND status <> ? AND amount - amount_returned - amount_paid > 0.005", userID, models.SaleStatusPaid)
+}
+
+func roundAgingBuckets(b *models.AgingBuckets) {
+	b.Days0To30 = roundTo(b.Days0To30, 2)
+	b.Days31To60 = roundTo(b.Days31To60, 2)
+	b.Days61To90 = roundTo(b.Days61To90, 2)
+	b.Over90 = roundTo(b.Over90, 2)
+	b.Total = roundTo(b.Total, 2)
+}
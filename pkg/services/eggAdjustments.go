@@ -8,6 +8,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrAdjustmentFromReturn is returned when changing an adjustment that writes off eggs from a sales return
+var ErrAdjustmentFromReturn = errors.New("adjustment was made by a sales return")
+
 type EggAdjustmentService struct {
 	DB *gorm.DB
 }
@@ -24,9 +27,18 @@ func (s *EggAdjustmentService) GetAdjustmentsByUser(userID uint) ([]models.EggAd
 	return adjustments, err
 }
 
-// AddAdjustment creates a new egg adjustment
+// AddAdjustment creates a new egg adjustment, rejecting it when the flock does not have enough eggs in stock
 func (s *EggAdjustmentService) AddAdjustment(adj *models.EggAdjustment) error {
-	if err := s.DB.Create(adj).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(adj).Error; err != nil {
+			return err
+		}
+		if adj.Quantity <= 0 {
+			return nil
+		}
+		return NewEggStockService(tx).CheckStock(adj.UserID, adj.FlockID, adj.DateAdjusted)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -40,9 +52,26 @@ func (s *EggAdjustmentService) AddAdjustment(adj *models.EggAdjustment) error {
 	return nil
 }
 
-// UpdateAdjustment updates an existing adjustment
+// UpdateAdjustment updates an existing adjustment, rejecting it when the flock does not have enough eggs in stock
 func (s *EggAdjustmentService) UpdateAdjustment(adj *models.EggAdjustment) error {
-	if err := s.DB.Save(adj).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var old models.EggAdjustment
+		if err := tx.Where("id = ? AND user_id = ?", adj.ID, adj.UserID).First(&old).Error; err != nil {
+			return errors.New("adjustment not found")
+		}
+		if err := checkNotReturnWriteOff(tx, old.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Save(adj).Error; err != nil {
+			return err
+		}
+		if !eggOutflowIncreased(old.FlockID, old.DateAdjusted, old.Quantity, adj.FlockID, adj.DateAdjusted, adj.Quantity) {
+			return nil
+		}
+		return NewEggStockService(tx).CheckStock(adj.UserID, adj.FlockID, adj.DateAdjusted)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -56,6 +85,9 @@ func (s *EggAdjustmentService) DeleteAdjustment(id, userID uint) error {
 	if err := s.DB.Where("id = ? AND user_id = ?", id, userID).First(&adj).Error; err != nil {
 		return errors.New("adjustment not found")
 	}
+	if err := checkNotReturnWriteOff(s.DB, adj.ID); err != nil {
+		return err
+	}
 
 	if err := s.DB.Delete(&adj).Error; err != nil {
 		return err
@@ -64,3 +96,16 @@ func (s *EggAdjustmentService) DeleteAdjustment(id, userID uint) error {
 	broadcast.SendEggAdjustmentUpdate(userID, "deleted", adj.ID)
 	return nil
 }
+
+// checkNotReturnWriteOff rejects changes to an adjustment made by a sales return; the return must be voided instead
+func checkNotReturnWriteOff(tx *gorm.DB, adjustmentID uint) error {
+	var ret models.SaleReturn
+	err := tx.Select("id", "credit_note_no").Where("egg_adjustment_id = ?", adjustmentID).First(&ret).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: void credit note %s instead", ErrAdjustmentFromReturn, ret.CreditNoteNo)
+}
@@ -3,11 +3,17 @@ package services
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/broadcast"
 	"gorm.io/gorm"
 )
 
+// UnderperformanceThreshold is how many hen-day percentage points below standard a week may fall before it is flagged
+const UnderperformanceThreshold = 5.0
+
 // EggProductionService provides methods to manage egg production records
 type EggProductionService struct {
 	DB *gorm.DB
@@ -21,12 +27,19 @@ func NewEggProductionService(db *gorm.DB) *EggProductionService {
 // GetEggProductionByUser retrieves egg production records for a specific user
 func (s *EggProductionService) GetEggProductionByUser(userID uint) ([]models.EggProduction, error) {
 	var records []models.EggProduction
-	err := s.DB.Where("user_id = ?", userID).Find(&records).Error
+	err := s.DB.Preload("Grades").Where("user_id = ?", userID).Find(&records).Error
 	return records, err
 }
 
-// AddEggProduction adds a new egg production record, calculates revenue, and sends a WebSocket update and notification
+// AddEggProduction adds a new egg production record, calculates revenue, and sends a WebSocket update and notification.
+// Prices left at zero are filled from the price list.
 func (s *EggProductionService) AddEggProduction(record *models.EggProduction) error {
+	if err := record.ValidateGrades(); err != nil {
+		return err
+	}
+	if err := fillProductionPrices(s.DB, record); err != nil {
+		return err
+	}
 	record.TotalRevenue = float64(record.EggsCollected) * record.PricePerUnit
 	if err := s.DB.Create(record).Error; err != nil {
 		return err
@@ -43,9 +56,23 @@ func (s *EggProductionService) AddEggProduction(record *models.EggProduction) er
 }
 
 // UpdateEggProduction updates an existing egg production record, recalculates revenue, and sends a WebSocket update and notification
+// Grade lines are replaced with record.Grades.
 func (s *EggProductionService) UpdateEggProduction(record *models.EggProduction) error {
+	if err := record.ValidateGrades(); err != nil {
+		return err
+	}
 	record.TotalRevenue = float64(record.EggsCollected) * record.PricePerUnit
-	if err := s.DB.Save(record).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("egg_production_id = ?", record.ID).Delete(&models.EggGradeLine{}).Error; err != nil {
+			return err
+		}
+		for i := range record.Grades {
+			record.Grades[i].ID = 0
+			record.Grades[i].EggProductionID = record.ID
+		}
+		return tx.Save(record).Error
+	})
+	if err != nil {
 		return err
 	}
 
@@ -66,7 +93,13 @@ func (s *EggProductionService) DeleteEggProduction(recordID uint, userID uint) e
 		return errors.New("record not found")
 	}
 
-	if err := s.DB.Delete(&record).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("egg_production_id = ?", record.ID).Delete(&models.EggGradeLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+	if err != nil {
 		return err
 	}
 
@@ -79,3 +112,134 @@ func (s *EggProductionService) DeleteEggProduction(recordID uint, userID uint) e
 
 	return nil
 }
+
+// GetLayingPerformance computes hen-day % and cumulative hen-housed eggs for each week of age
+// and compares them against the breed standard matching flock.Breed.
+// Flock.Age is taken as the flock's age in weeks when it was created.
+func (s *EggProductionService) GetLayingPerformance(flock *models.Flock) (*models.LayingPerformance, error) {
+	performance := &models.LayingPerformance{
+		FlockID: flock.ID,
+		Breed:   flock.Breed,
+		Weeks:   []models.LayingPerformanceWeek{},
+	}
+
+	var records []models.EggProduction
+	if err := s.DB.Where("flock_id = ? AND user_id = ?", flock.ID, flock.UserID).
+		Order("date_produced ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return performance, nil
+	}
+
+	placed := truncateDay(flock.CreatedAt)
+	ageWeeksOn := func(day time.Time) int {
+		return int(flock.Age) + int(math.Floor(truncateDay(day).Sub(placed).Hours()/24/7))
+	}
+	weekStartFor := func(ageWeeks int) time.Time {
+		return placed.AddDate(0, 0, (ageWeeks-int(flock.Age))*7)
+	}
+
+	firstWeek := ageWeeksOn(records[0].DateProduced)
+	lastWeek := ageWeeksOn(time.Now())
+	start, end := weekStartFor(firstWeek), truncateDay(time.Now())
+
+	history, err := NewMortalityService(s.DB).BirdCountHistory(flock, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	eggsByWeek := make(map[int]int)
+	for _, r := range records {
+		eggsByWeek[ageWeeksOn(r.DateProduced)] += r.EggsCollected
+	}
+
+	standards, err := s.findBreedStandard(flock.Breed)
+	if err != nil {
+		return nil, err
+	}
+	standardByWeek := make(map[int]models.BreedStandard)
+	for _, std := range standards {
+		standardByWeek[std.AgeWeeks] = std
+		performance.StandardBreed = std.Breed
+	}
+
+	cumulativeEggs := 0
+	for week := firstWeek; week <= lastWeek; week++ {
+		weekStart := weekStartFor(week)
+		weekEnd := weekStart.AddDate(0, 0, 6)
+		if weekEnd.After(end) {
+			weekEnd = end
+		}
+
+		henDays := 0
+		for day := weekStart; !day.After(weekEnd); day = day.AddDate(0, 0, 1) {
+			henDays += history[day.Format("2006-01-02")]
+		}
+
+		eggs := eggsByWeek[week]
+		cumulativeEggs += eggs
+
+		point := models.LayingPerformanceWeek{
+			AgeWeeks:      week,
+			WeekStart:     weekStart.Format("2006-01-02"),
+			WeekEnd:       weekEnd.Format("2006-01-02"),
+			EggsCollected: eggs,
+			HenDays:       henDays,
+		}
+		if henDays > 0 {
+			point.HenDayPercent = roundTo(float64(eggs)/float64(henDays)*100, 2)
+		}
+		if flock.InitialBirdCount > 0 {
+			point.HenHousedEggs = roundTo(float64(cumulativeEggs)/float64(flock.InitialBirdCount), 2)
+		}
+
+		if std, ok := standardByWeek[week]; ok {
+			henDay, henHoused := std.HenDayPercent, std.HenHousedEggs
+			variance := roundTo(point.HenDayPercent-henDay, 2)
+			point.StandardHenDayPercent = &henDay
+			point.StandardHenHousedEggs = &henHoused
+			point.HenDayVariance = &variance
+			point.Underperforming = variance < -UnderperformanceThreshold
+		}
+
+		performance.Weeks = append(performance.Weeks, point)
+	}
+
+	return performance, nil
+}
+
+// findBreedStandard returns the laying curve whose breed name matches the flock's breed, ignoring case,
+// or through one of models.BreedAliases, e.g. a flock recorded as "Lohmann" uses the "Lohmann Brown"
+// standard. Flocks whose breed matches no standard exactly have none.
+func (s *EggProductionService) findBreedStandard(breed string) ([]models.BreedStandard, error) {
+	breed = strings.ToLower(strings.TrimSpace(breed))
+	if breed == "" {
+		return nil, nil
+	}
+
+	var names []string
+	if err := s.DB.Model(&models.BreedStandard{}).Distinct("breed").Pluck("breed", &names).Error; err != nil {
+		return nil, err
+	}
+
+	match := ""
+	alias := strings.ToLower(models.BreedAliases[breed])
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if lower == breed {
+			match = name
+			break
+		}
+		if alias != "" && lower == alias {
+			match = name
+		}
+	}
+	if match == "" {
+		return nil, nil
+	}
+
+	var standards []models.BreedStandard
+	err := s.DB.Where("breed = ?", match).Order("age_weeks ASC").Find(&standards).Error
+	return standards, err
+}
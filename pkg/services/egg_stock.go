@@ -0,0 +1,232 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInsufficientEggStock is returned when a sale or adjustment would take egg stock below zero
+var ErrInsufficientEggStock = errors.New("insufficient egg stock")
+
+// EggStockService reconciles egg production, adjustments and egg sales into a stock ledger
+type EggStockService struct {
+	DB *gorm.DB
+}
+
+// NewEggStockService initializes a new service instance
+func NewEggStockService(db *gorm.DB) *EggStockService {
+	return &EggStockService{DB: db}
+}
+
+// eggMovements holds egg flows per day, keyed by YYYY-MM-DD
+type eggMovements struct {
+	produced map[string]int
+	adjusted map[string]int
+	sold     map[string]int
+	returned map[string]int
+}
+
+func (m *eggMovements) days() []string {
+	seen := make(map[string]bool)
+	for _, flows := range []map[string]int{m.produced, m.adjusted, m.sold, m.returned} {
+		for day := range flows {
+			seen[day] = true
+		}
+	}
+	days := make([]string, 0, len(seen))
+	for day := range seen {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days
+}
+
+func (m *eggMovements) net(day string) int {
+	return m.produced[day] + m.returned[day] - m.adjusted[day] - m.sold[day]
+}
+
+// loadMovements collects egg flows for a user's flock, or all of the user's flocks when flockID is 0
+func (s *EggStockService) loadMovements(userID, flockID uint) (*eggMovements, error) {
+	m := &eggMovements{
+		produced: make(map[string]int),
+		adjusted: make(map[string]int),
+		sold:     make(map[string]int),
+		returned: make(map[string]int),
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("user_id = ?", userID)
+		if flockID != 0 {
+			db = db.Where("flock_id = ?", flockID)
+		}
+		return db
+	}
+
+	var productions []models.EggProduction
+	if err := s.DB.Scopes(scope).Select("date_produced", "eggs_collected").Find(&productions).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range productions {
+		m.produced[p.DateProduced.Format("2006-01-02")] += p.EggsCollected
+	}
+
+	var adjustments []models.EggAdjustment
+	if err := s.DB.Scopes(scope).Select("date_adjusted", "quantity").Find(&adjustments).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range adjustments {
+		m.adjusted[a.DateAdjusted.Format("2006-01-02")] += a.Quantity
+	}
+
+	eggSalesID, err := EggSalesCategoryID(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	var sales []models.Sale
+	if err := s.DB.Scopes(scope).Where("category_id = ?", eggSalesID).
+		Select("date", "quantity").Find(&sales).Error; err != nil {
+		return nil, err
+	}
+	for _, sale := range sales {
+		m.sold[sale.Date.Format("2006-01-02")] += sale.Quantity
+	}
+
+	// Returned eggs come back into stock; write-offs are taken out again by their adjustment
+	var returns []models.SaleReturn
+	if err := s.DB.Scopes(scope).Where("disposition <> '' AND quantity > 0").
+		Select("date", "quantity").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range returns {
+		m.returned[r.Date.Format("2006-01-02")] += r.Quantity
+	}
+
+	return m, nil
+}
+
+// GetLedger returns opening, inflows, outflows and closing egg stock per day between start and end.
+// A flockID of 0 combines all of the user's flocks.
+func (s *EggStockService) GetLedger(userID, flockID uint, start, end time.Time) (*models.EggStockLedger, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	m, err := s.loadMovements(userID, flockID)
+	if err != nil {
+		return nil, err
+	}
+
+	startKey := start.Format("2006-01-02")
+	opening := 0
+	for _, day := range m.days() {
+		if day < startKey {
+			opening += m.net(day)
+		}
+	}
+
+	ledger := &models.EggStockLedger{
+		FlockID:   flockID,
+		StartDate: startKey,
+		EndDate:   end.Format("2006-01-02"),
+		Opening:   opening,
+		Days:      []models.EggStockDay{},
+	}
+
+	balance := opening
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		entry := models.EggStockDay{
+			Date:     key,
+			Opening:  balance,
+			Produced: m.produced[key],
+			Adjusted: m.adjusted[key],
+			Sold:     m.sold[key],
+			Returned: m.returned[key],
+		}
+		entry.Inflows = entry.Produced + entry.Returned
+		entry.Outflows = entry.Adjusted + entry.Sold
+		if entry.Adjusted < 0 {
+			entry.Inflows -= entry.Adjusted
+			entry.Outflows -= entry.Adjusted
+		}
+		balance += m.net(key)
+		entry.Closing = balance
+		ledger.Days = append(ledger.Days, entry)
+	}
+	ledger.Closing = balance
+
+	return ledger, nil
+}
+
+// GetStockOnHand returns the eggs currently in store per flock, for the dashboard widget
+func (s *EggStockService) GetStockOnHand(userID uint) (*models.EggStockOnHand, error) {
+	var flocks []models.Flock
+	if err := s.DB.Where("user_id = ?", userID).Select("id", "name").Find(&flocks).Error; err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	onHand := &models.EggStockOnHand{
+		AsOf:   today,
+		Flocks: []models.FlockEggStock{},
+	}
+
+	for _, flock := range flocks {
+		m, err := s.loadMovements(userID, flock.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		eggs := 0
+		for _, day := range m.days() {
+			if day <= today {
+				eggs += m.net(day)
+			}
+		}
+
+		onHand.TotalEggs += eggs
+		onHand.ProducedToday += m.produced[today]
+		onHand.SoldToday += m.sold[today]
+		onHand.Flocks = append(onHand.Flocks, models.FlockEggStock{
+			FlockID:   flock.ID,
+			FlockName: flock.Name,
+			Eggs:      eggs,
+		})
+	}
+
+	return onHand, nil
+}
+
+// CheckStock verifies that a flock's closing egg stock stays at or above zero on every day from `from` onward.
+// Call it inside the transaction that writes a sale or adjustment so the write can be rolled back.
+func (s *EggStockService) CheckStock(userID, flockID uint, from time.Time) error {
+	m, err := s.loadMovements(userID, flockID)
+	if err != nil {
+		return err
+	}
+
+	fromKey := truncateDay(from).Format("2006-01-02")
+	balance := 0
+	for _, day := range m.days() {
+		balance += m.net(day)
+		if day >= fromKey && balance < 0 {
+			return fmt.Errorf("%w: flock would be %d eggs short on %s", ErrInsufficientEggStock, -balance, day)
+		}
+	}
+	return nil
+}
+
+// eggOutflowIncreased reports whether replacing an outflow (flock, date, quantity) with another
+// could lower the stock on any day, which is when the stock check needs to run
+func eggOutflowIncreased(oldFlockID uint, oldDate time.Time, oldQty int, newFlockID uint, newDate time.Time, newQty int) bool {
+	if oldFlockID != newFlockID || newQty > oldQty {
+		return true
+	}
+	return truncateDay(newDate).Before(truncateDay(oldDate))
+}
@@ -11,15 +11,23 @@ import (
 
 // SMTP2GoRequest defines the payload for sending email
 type SMTP2GoRequest struct {
-	Sender   string   `json:"sender"`
-	To       []string `json:"to"`
-	Subject  string   `json:"subject"`
-	HtmlBody string   `json:"html_body,omitempty"`
-	TextBody string   `json:"text_body,omitempty"`
+	Sender      string       `json:"sender"`
+	To          []string     `json:"to"`
+	Subject     string       `json:"subject"`
+	HtmlBody    string       `json:"html_body,omitempty"`
+	TextBody    string       `json:"text_body,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
-// SendEmail sends an email using the SMTP2GO API
-func SendEmail(to, subject, htmlBody string) error {
+// Attachment is a file sent with an email; FileBlob is the base64-encoded content
+type Attachment struct {
+	Filename string `json:"filename"`
+	FileBlob string `json:"fileblob"`
+	MimeType string `json:"mimetype"`
+}
+
+// SendEmail sends an email using the SMTP2GO API, with any attachments given
+func SendEmail(to, subject, htmlBody string, attachments ...Attachment) error {
 	apiKey := os.Getenv("SMTP2GO_API_KEY")
 	if apiKey == "" {
 		return fmt.Errorf("SMTP2GO API key is missing")
@@ -28,11 +36,12 @@ func SendEmail(to, subject, htmlBody string) error {
 	url := "https://api.smtp2go.com/v3/email/send"
 
 	emailReq := SMTP2GoRequest{
-		Sender:   "birdseye-poultry@816-dynamics.com", 
-		To:       []string{to},
-		Subject:  subject,
-		HtmlBody: htmlBody,
-		TextBody: "Thank you for using Birdseye Poultry.",
+		Sender:      "birdseye-poultry@816-dynamics.com",
+		To:          []string{to},
+		Subject:     subject,
+		HtmlBody:    htmlBody,
+		TextBody:    "Thank you for using Birdseye Poultry.",
+		Attachments: attachments,
 	}
 
 	data, err := json.Marshal(emailReq)
@@ -0,0 +1,208 @@
+package email
+
+import (
+	"birdseye-backend/pkg/models"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// SendLowStockEmail alerts the user that an inventory item has dropped to its reorder level
+func SendLowStockEmail(toEmail string, userName string, item *models.InventoryItem) error {
+	subject := fmt.Sprintf("🐓 Birdseye Poultry: %s is running low", item.ItemName)
+
+	body := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html lang="en">
+	<head>
+		<meta charset="UTF-8" />
+		<meta name="viewport" content="width=device-width, initial-scale=1" />
+		<title>Low Stock Alert</title>
+		<style>
+			body {
+				font-family: Arial, sans-serif;
+				background-color: #e0f7fa;
+				color: #333;
+				margin: 0;
+				padding: 0;
+			}
+			.container {
+				max-width: 600px;
+				margin: 40px auto;
+				background: #fff;
+				border-radius: 10px;
+				box-shadow: 0 2px 8px rgba(0, 0, 0, 0.05);
+				overflow: hidden;
+			}
+			.header {
+				background-color: #2563eb;
+				color: white;
+				padding: 24px;
+				text-align: center;
+			}
+			.header h1 {
+				margin: 0;
+				font-size: 24px;
+			}
+			.content {
+				padding: 24px;
+				font-size: 16px;
+				line-height: 1.7;
+			}
+			.footer {
+				padding: 20px;
+				font-size: 13px;
+				text-align: center;
+				color: #555;
+				background-color: #f1f5f9;
+			}
+			a.button {
+				display: inline-block;
+				margin-top: 20px;
+				padding: 12px 24px;
+				background-color: #10b981;
+				color: white;
+				text-decoration: none;
+				border-radius: 6px;
+				font-weight: bold;
+			}
+			@media (max-width: 600px) {
+				.container {
+					border-radius: 0;
+				}
+				.header, .content, .footer {
+					padding: 16px;
+				}
+			}
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Low Stock Alert 📦</h1>
+			</div>
+			<div class="content">
+				<p>Hi %s,</p>
+				<p>One of your inventory items has reached its reorder level:</p>
+				<ul>
+					<li><strong>Item:</strong> %s</li>
+					<li><strong>In Stock:</strong> %d</li>
+					<li><strong>Reorder Level:</strong> %d</li>
+				</ul>
+				<p>Restock soon to avoid running out.</p>
+				<a href="https://app.birdseye-poultry.com/inventory" class="button">View Inventory</a>
+				<p style="margin-top: 30px;">Thank you for trusting Birdseye Poultry Manager!</p>
+			</div>
+			<div class="footer">
+				<p>&copy; 2025 Birdseye Poultry. All rights reserved.</p>
+				<p>A proud product of <strong>816 Dynamics</strong> – empowering modern agriculture with smart software solutions.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, userName, item.ItemName, item.Quantity, item.ReorderLevel)
+
+	return SendEmail(toEmail, subject, body)
+}
+
+// SendLotExpiryEmail warns the user about inventory lots that are close to or past their expiry date
+func SendLotExpiryEmail(toEmail string, userName string, lots []models.InventoryLot, warningDays int) error {
+	subject := fmt.Sprintf("🐓 Birdseye Poultry: %d inventory lot(s) expiring soon", len(lots))
+
+	var items strings.Builder
+	for _, lot := range lots {
+		items.WriteString(fmt.Sprintf("<li><strong>%s</strong>, batch %s: %d left, expires %s</li>",
+			html.EscapeString(lot.ItemName), html.EscapeString(lot.BatchNumber), lot.Quantity, lot.ExpiryDate.Format("2006-01-02")))
+	}
+
+	body := fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html lang="en">
+	<head>
+		<meta charset="UTF-8" />
+		<meta name="viewport" content="width=device-width, initial-scale=1" />
+		<title>Expiry Warning</title>
+		<style>
+			body {
+				font-family: Arial, sans-serif;
+				background-color: #e0f7fa;
+				color: #333;
+				margin: 0;
+				padding: 0;
+			}
+			.container {
+				max-width: 600px;
+				margin: 40px auto;
+				background: #fff;
+				border-radius: 10px;
+				box-shadow: 0 2px 8px rgba(0, 0, 0, 0.05);
+				overflow: hidden;
+			}
+			.header {
+				background-color: #2563eb;
+				color: white;
+				padding: 24px;
+				text-align: center;
+			}
+			.header h1 {
+				margin: 0;
+				font-size: 24px;
+			}
+			.content {
+				padding: 24px;
+				font-size: 16px;
+				line-height: 1.7;
+			}
+			.footer {
+				padding: 20px;
+				font-size: 13px;
+				text-align: center;
+				color: #555;
+				background-color: #f1f5f9;
+			}
+			a.button {
+				display: inline-block;
+				margin-top: 20px;
+				padding: 12px 24px;
+				background-color: #10b981;
+				color: white;
+				text-decoration: none;
+				border-radius: 6px;
+				font-weight: bold;
+			}
+			@media (max-width: 600px) {
+				.container {
+					border-radius: 0;
+				}
+				.header, .content, .footer {
+					padding: 16px;
+				}
+			}
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Expiry Warning ⏳</h1>
+			</div>
+			<div class="content">
+				<p>Hi %s,</p>
+				<p>The following inventory lots expire within %d days:</p>
+				<ul>
+					%s
+				</ul>
+				<p>Use these batches first or plan to replace them before they expire.</p>
+				<a href="https://app.birdseye-poultry.com/inventory" class="button">View Inventory</a>
+				<p style="margin-top: 30px;">Thank you for trusting Birdseye Poultry Manager!</p>
+			</div>
+			<div class="footer">
+				<p>&copy; 2025 Birdseye Poultry. All rights reserved.</p>
+				<p>A proud product of <strong>816 Dynamics</strong> – empowering modern agriculture with smart software solutions.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, userName, warningDays, items.String())
+
+	return SendEmail(toEmail, subject, body)
+}
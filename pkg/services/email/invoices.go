@@ -0,0 +1,38 @@
+package email
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SendSaleInvoiceEmail emails a sales invoice or receipt PDF to a customer
+func SendSaleInvoiceEmail(toEmail, customerName, farmName, documentType, documentNo string, balance float64, pdfPath string) error {
+	content, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", documentType, err)
+	}
+
+	subject := fmt.Sprintf("%s %s from %s", documentType, documentNo, farmName)
+
+	balanceLine := "<p>This purchase is fully paid. Thank you for your business!</p>"
+	if balance > 0 {
+		balanceLine = fmt.Sprintf("<p>The balance due is <strong>KES %.2f</strong>.</p>", balance)
+	}
+
+	body := fmt.Sprintf(`
+		<h2>Hi %s,</h2>
+		<p>Please find attached your %s <strong>%s</strong> from <strong>%s</strong>.</p>
+		%s
+		<p>– %s</p>
+	`, customerName, strings.ToLower(documentType), documentNo, farmName, balanceLine, farmName)
+
+	attachment := Attachment{
+		Filename: filepath.Base(pdfPath),
+		FileBlob: base64.StdEncoding.EncodeToString(content),
+		MimeType: "application/pdf",
+	}
+	return SendEmail(toEmail, subject, body, attachment)
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrExpenseAllocation is returned when an expense has no flock or its allocation rule cannot be applied
+var ErrExpenseAllocation = errors.New("invalid expense allocation")
+
+// PreviewAllocation computes how a farm-level expense would be split across flocks without saving it
+func (s *ExpenseService) PreviewAllocation(expense *models.Expense) ([]models.ExpenseAllocation, error) {
+	return computeAllocations(s.DB, expense)
+}
+
+// prepareAllocation checks a single-flock expense has its flock, or splits a farm-level expense
+// by its allocation rule. Farm-level expenses belong to no one flock.
+func prepareAllocation(tx *gorm.DB, expense *models.Expense) error {
+	if expense.AllocationMethod == "" {
+		if expense.FlockID == nil || *expense.FlockID == 0 {
+			return fmt.Errorf("%w: flock_id is required unless an allocation_method is set", ErrExpenseAllocation)
+		}
+		expense.Allocations = nil
+		return nil
+	}
+
+	allocations, err := computeAllocations(tx, expense)
+	if err != nil {
+		return err
+	}
+	expense.FlockID = nil
+	expense.Allocations = allocations
+	return nil
+}
+
+// saveAllocations replaces the stored split of an expense with its Allocations
+func saveAllocations(tx *gorm.DB, expense *models.Expense) error {
+	if err := tx.Where("expense_id = ?", expense.ID).Delete(&models.ExpenseAllocation{}).Error; err != nil {
+		return err
+	}
+	if len(expense.Allocations) == 0 {
+		return nil
+	}
+	for i := range expense.Allocations {
+		expense.Allocations[i].ID = 0
+		expense.Allocations[i].ExpenseID = expense.ID
+		expense.Allocations[i].UserID = expense.UserID
+	}
+	return tx.Create(&expense.Allocations).Error
+}
+
+// computeAllocations splits an expense's amount across flocks by its allocation rule. Flocks listed in
+// the expense's Allocations limit the split to those flocks; percentage splits must list every flock
+// with its percentage. Otherwise the split covers every flock of the user that has birds.
+func computeAllocations(tx *gorm.DB, expense *models.Expense) ([]models.ExpenseAllocation, error) {
+	date := expense.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	requested := make([]uint, 0, len(expense.Allocations))
+	percentages := make(map[uint]float64, len(expense.Allocations))
+	for _, allocation := range expense.Allocations {
+		if _, dup := percentages[allocation.FlockID]; dup {
+			return nil, fmt.Errorf("%w: flock %d is listed more than once", ErrExpenseAllocation, allocation.FlockID)
+		}
+		requested = append(requested, allocation.FlockID)
+		percentages[allocation.FlockID] = allocation.Percentage
+	}
+
+	var flocks []models.Flock
+	query := tx.Where("user_id = ?", expense.UserID)
+	if len(requested) > 0 {
+		query = query.Where("id IN ?", requested)
+	} else {
+		query = query.Where("bird_count > 0")
+	}
+	if err := query.Order("id ASC").Find(&flocks).Error; err != nil {
+		return nil, err
+	}
+	if len(requested) > 0 && len(flocks) != len(requested) {
+		return nil, fmt.Errorf("%w: flock not found", ErrExpenseAllocation)
+	}
+	if len(flocks) == 0 {
+		return nil, fmt.Errorf("%w: no flocks to allocate the expense to", ErrExpenseAllocation)
+	}
+
+	weights := make([]float64, len(flocks))
+	switch expense.AllocationMethod {
+	case models.AllocationEqual:
+		for i := range flocks {
+			weights[i] = 1
+		}
+
+	case models.AllocationBirdCount:
+		day := truncateDay(date)
+		mortality := NewMortalityService(tx)
+		for i := range flocks {
+			history, err := mortality.BirdCountHistory(&flocks[i], day, day)
+			if err != nil {
+				return nil, err
+			}
+			weights[i] = float64(history[day.Format("2006-01-02")])
+		}
+
+	case models.AllocationPercentage:
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("%w: list each flock's percentage in allocations", ErrExpenseAllocation)
+		}
+		var total float64
+		for i, flock := range flocks {
+			if percentages[flock.ID] <= 0 {
+				return nil, fmt.Errorf("%w: percentage for flock %s must be greater than zero", ErrExpenseAllocation, flock.Name)
+			}
+			weights[i] = percentages[flock.ID]
+			total += weights[i]
+		}
+		if math.Abs(total-100) > 0.01 {
+			return nil, fmt.Errorf("%w: percentages add up to %.2f, not 100", ErrExpenseAllocation, total)
+		}
+
+	case models.AllocationFeedUsage:
+		monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		monthEnd := monthStart.AddDate(0, 1, -1)
+		ids := make([]uint, len(flocks))
+		for i, flock := range flocks {
+			ids[i] = flock.ID
+		}
+		var usage []struct {
+			FlockID uint
+			Kg      float64
+		}
+		if err := tx.Model(&models.FeedLog{}).Select("flock_id, SUM(quantity_kg) AS kg").
+			Where("user_id = ? AND flock_id IN ? AND date BETWEEN ? AND ?", expense.UserID, ids,
+				monthStart.Format("2006-01-02"), monthEnd.Format("2006-01-02")).
+			Group("flock_id").Scan(&usage).Error; err != nil {
+			return nil, err
+		}
+		kgByFlock := make(map[uint]float64, len(usage))
+		for _, u := range usage {
+			kgByFlock[u.FlockID] = u.Kg
+		}
+		for i, flock := range flocks {
+			weights[i] = kgByFlock[flock.ID]
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: allocation_method must be equal, bird_count, percentage or feed_usage", ErrExpenseAllocation)
+	}
+
+	var totalWeight float64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	if totalWeight <= 0 {
+		switch expense.AllocationMethod {
+		case models.AllocationFeedUsage:
+			return nil, fmt.Errorf("%w: no feed was logged for these flocks in %s", ErrExpenseAllocation, date.Format("January 2006"))
+		default:
+			return nil, fmt.Errorf("%w: these flocks had no birds on %s", ErrExpenseAllocation, date.Format("2006-01-02"))
+		}
+	}
+
+	return splitAllocations(expense, flocks, weights, totalWeight), nil
+}
+
+// splitAllocations shares an expense's amount across flocks in proportion to their weights, leaving out
+// flocks with no weight. Rounding differences go to the last flock so the shares add up to the expense amount.
+func splitAllocations(expense *models.Expense, flocks []models.Flock, weights []float64, totalWeight float64) []models.ExpenseAllocation {
+	var allocations []models.ExpenseAllocation
+	for i, flock := range flocks {
+		if weights[i] <= 0 {
+			continue
+		}
+		allocations = append(allocations, models.ExpenseAllocation{
+			UserID:     expense.UserID,
+			FlockID:    flock.ID,
+			Basis:      weights[i],
+			Percentage: roundTo(weights[i]/totalWeight*100, 4),
+			Amount:     roundTo(expense.Amount*weights[i]/totalWeight, 2),
+		})
+	}
+
+	var allocated float64
+	for _, allocation := range allocations {
+		allocated += allocation.Amount
+	}
+	last := &allocations[len(allocations)-1]
+	last.Amount = roundTo(last.Amount+expense.Amount-allocated, 2)
+	return allocations
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSplitAllocations(t *testing.T) {
+	flocks := []models.Flock{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	tests := []struct {
+		name    string
+		amount  float64
+		weights []float64
+		flocks  []uint
+		amounts []float64
+	}{
+		{
+			name:    "equal split gives the remainder cent to the last flock",
+			amount:  100,
+			weights: []float64{1, 1, 1},
+			flocks:  []uint{1, 2, 3},
+			amounts: []float64{33.33, 33.33, 33.34},
+		},
+		{
+			name:    "rounding up takes the excess off the last flock",
+			amount:  0.05,
+			weights: []float64{1, 1, 1},
+			flocks:  []uint{1, 2, 3},
+			amounts: []float64{0.02, 0.02, 0.01},
+		},
+		{
+			name:    "weighted split",
+			amount:  250.5,
+			weights: []float64{300, 700, 0},
+			flocks:  []uint{1, 2},
+			amounts: []float64{75.15, 175.35},
+		},
+		{
+			name:    "flocks without weight are left out and the last weighted flock takes the remainder",
+			amount:  10,
+			weights: []float64{1, 2, 0},
+			flocks:  []uint{1, 2},
+			amounts: []float64{3.33, 6.67},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := 0.0
+			for _, w := range tt.weights {
+				total += w
+			}
+			expense := &models.Expense{UserID: 7, Amount: tt.amount}
+			allocations := splitAllocations(expense, flocks, tt.weights, total)
+			if len(allocations) != len(tt.amounts) {
+				t.Fatalf("got %d allocations, want %d", len(allocations), len(tt.amounts))
+			}
+
+			sum := 0.0
+			for i, allocation := range allocations {
+				if allocation.FlockID != tt.flocks[i] {
+					t.Errorf("allocation %d flock = %d, want %d", i, allocation.FlockID, tt.flocks[i])
+				}
+				if allocation.UserID != expense.UserID {
+					t.Errorf("allocation %d user = %d, want %d", i, allocation.UserID, expense.UserID)
+				}
+				if math.Abs(allocation.Amount-tt.amounts[i]) > 1e-9 {
+					t.Errorf("allocation %d amount = %.2f, want %.2f", i, allocation.Amount, tt.amounts[i])
+				}
+				sum += allocation.Amount
+			}
+			if math.Abs(sum-tt.amount) > 0.001 {
+				t.Errorf("allocations add up to %.2f, want %.2f", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestComputeAllocationsRejectsDuplicateFlocks(t *testing.T) {
+	expense := &models.Expense{
+		UserID:           1,
+		Amount:           100,
+		AllocationMethod: models.AllocationPercentage,
+		Allocations: []models.ExpenseAllocation{
+			{FlockID: 4, Percentage: 50},
+			{FlockID: 4, Percentage: 50},
+		},
+	}
+	if _, err := computeAllocations(nil, expense); !errors.Is(err, ErrExpenseAllocation) {
+		t.Fatalf("computeAllocations error = %v, want ErrExpenseAllocation", err)
+	}
+}
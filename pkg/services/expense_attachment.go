@@ -0,0 +1,281 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/storage"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAttachmentInvalid is returned for uploads that are too large or not an image or PDF
+	ErrAttachmentInvalid = errors.New("invalid attachment")
+	// ErrAttachmentNotFound is returned when the expense or attachment does not belong to the user
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+// thumbnailSize is the longest side, in pixels, of attachment thumbnails. Images larger than
+// maxThumbnailPixels are stored without one rather than decoded.
+const (
+	thumbnailSize      = 240
+	maxThumbnailPixels = 50_000_000
+)
+
+// ExpenseAttachmentService stores receipts and invoices uploaded against expenses
+type ExpenseAttachmentService struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+}
+
+// NewExpenseAttachmentService initializes a new service instance
+func NewExpenseAttachmentService(db *gorm.DB, store storage.Storage) *ExpenseAttachmentService {
+	return &ExpenseAttachmentService{DB: db, Storage: store}
+}
+
+// GetAttachments returns the attachments on an expense owned by the user, oldest first
+func (s *ExpenseAttachmentService) GetAttachments(expenseID, userID uint) ([]models.ExpenseAttachment, error) {
+	if err := s.checkExpense(expenseID, userID); err != nil {
+		return nil, err
+	}
+	var attachments []models.ExpenseAttachment
+	err := s.DB.Where("expense_id = ? AND user_id = ?", expenseID, userID).Order("id ASC").Find(&attachments).Error
+	return attachments, err
+}
+
+// GetAttachment returns a single attachment on an expense owned by the user
+func (s *ExpenseAttachmentService) GetAttachment(expenseID, attachmentID, userID uint) (*models.ExpenseAttachment, error) {
+	var attachment models.ExpenseAttachment
+	if err := s.DB.Where("id = ? AND expense_id = ? AND user_id = ?", attachmentID, expenseID, userID).
+		First(&attachment).Error; err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+	return &attachment, nil
+}
+
+// AddAttachment stores an uploaded image or PDF against an expense. The content type is detected
+// from the file itself, and images get a JPEG thumbnail where they can be decoded.
+func (s *ExpenseAttachmentService) AddAttachment(expenseID, userID uint, fileName string, r io.Reader) (*models.ExpenseAttachment, error) {
+	if err := s.checkExpense(expenseID, userID); err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, models.MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrAttachmentInvalid)
+	}
+	if len(content) > models.MaxAttachmentSize {
+		return nil, fmt.Errorf("%w: file exceeds %d MB", ErrAttachmentInvalid, models.MaxAttachmentSize>>20)
+	}
+
+	contentType := http.DetectContentType(content)
+	ext, ok := attachmentExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: only JPEG, PNG, WebP images and PDF files are accepted", ErrAttachmentInvalid)
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	attachment := &models.ExpenseAttachment{
+		UserID:      userID,
+		ExpenseID:   expenseID,
+		FileName:    attachmentFileName(fileName, ext),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		StorageKey:  fmt.Sprintf("expenses/%d/%d/%s%s", userID, expenseID, name, ext),
+	}
+	if err := s.Storage.Save(attachment.StorageKey, bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	if thumbnail, err := makeThumbnail(content); err != nil {
+		log.Printf("Could not make thumbnail for %s: %v", attachment.StorageKey, err)
+	} else if thumbnail != nil {
+		key := fmt.Sprintf("expenses/%d/%d/%s_thumb.jpg", userID, expenseID, name)
+		if err := s.Storage.Save(key, bytes.NewReader(thumbnail)); err != nil {
+			log.Printf("Could not store thumbnail for %s: %v", attachment.StorageKey, err)
+		} else {
+			attachment.ThumbnailKey = key
+		}
+	}
+
+	if err := s.DB.Create(attachment).Error; err != nil {
+		s.removeFiles(attachment)
+		return nil, err
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+
+	broadcast.SendExpenseUpdate(userID, "expense_attachment_added", *attachment)
+	return attachment, nil
+}
+
+// OpenAttachment returns an attachment and its file, or its thumbnail when thumbnail is set.
+// The caller must close the file.
+func (s *ExpenseAttachmentService) OpenAttachment(expenseID, attachmentID, userID uint, thumbnail bool) (*models.ExpenseAttachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachment(expenseID, attachmentID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, nil, fmt.Errorf("%w: attachment has no thumbnail", ErrAttachmentNotFound)
+		}
+		key = attachment.ThumbnailKey
+	}
+
+	file, err := s.Storage.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, fmt.Errorf("%w: file is missing from storage", ErrAttachmentNotFound)
+		}
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// DeleteAttachment removes an attachment and its files
+func (s *ExpenseAttachmentService) DeleteAttachment(expenseID, attachmentID, userID uint) error {
+	attachment, err := s.GetAttachment(expenseID, attachmentID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.DB.Delete(attachment).Error; err != nil {
+		return err
+	}
+	s.removeFiles(attachment)
+
+	broadcast.SendExpenseUpdate(userID, "expense_attachment_deleted", attachment.ID)
+	return nil
+}
+
+// DeleteExpenseAttachments removes every attachment on an expense, used when the expense is deleted
+func (s *ExpenseAttachmentService) DeleteExpenseAttachments(expenseID uint) error {
+	var attachments []models.ExpenseAttachment
+	if err := s.DB.Where("expense_id = ?", expenseID).Find(&attachments).Error; err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+	if err := s.DB.Where("expense_id = ?", expenseID).Delete(&models.ExpenseAttachment{}).Error; err != nil {
+		return err
+	}
+	for i := range attachments {
+		s.removeFiles(&attachments[i])
+	}
+	return nil
+}
+
+// checkExpense verifies the expense belongs to the user
+func (s *ExpenseAttachmentService) checkExpense(expenseID, userID uint) error {
+	var count int64
+	if err := s.DB.Model(&models.Expense{}).Where("id = ? AND user_id = ?", expenseID, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: expense not found", ErrAttachmentNotFound)
+	}
+	return nil
+}
+
+// removeFiles deletes an attachment's stored files. Failures are logged rather than returned since
+// the attachment record is already gone.
+func (s *ExpenseAttachmentService) removeFiles(attachment *models.ExpenseAttachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.Storage.Delete(key); err != nil {
+			log.Printf("Could not delete stored file %s: %v", key, err)
+		}
+	}
+}
+
+// attachmentExtensions maps accepted content types to the extension files are stored with
+var attachmentExtensions = map[string]string{
+	models.AttachmentJPEG: ".jpg",
+	models.AttachmentPNG:  ".png",
+	models.AttachmentWebP: ".webp",
+	models.AttachmentPDF:  ".pdf",
+}
+
+// attachmentFileName cleans the uploaded file name for display and download
+func attachmentFileName(name, ext string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment" + ext
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to name attachment: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// makeThumbnail scales a JPEG or PNG image down to fit within thumbnailSize and encodes it as JPEG.
+// It returns nil for content it cannot decode, such as PDFs and WebP images.
+func makeThumbnail(content []byte) ([]byte, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, nil
+	}
+	if config.Width*config.Height > maxThumbnailPixels {
+		return nil, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, nil
+	}
+	scale := float64(thumbnailSize) / float64(max(width, height))
+	if scale > 1 {
+		scale = 1
+	}
+	dstWidth, dstHeight := max(1, int(float64(width)*scale)), max(1, int(float64(height)*scale))
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		srcY := bounds.Min.Y + y*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			dst.Set(x, y, src.At(bounds.Min.X+x*width/dstWidth, srcY))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
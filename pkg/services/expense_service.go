@@ -4,6 +4,7 @@ import (
 	"errors"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/storage"
 	"gorm.io/gorm"
 	"log"
 	"time"
@@ -23,7 +24,7 @@ func NewExpenseService(db *gorm.DB) *ExpenseService {
 // GetExpensesByUser retrieves expenses for a specific user
 func (s *ExpenseService) GetExpensesByUser(userID uint) ([]models.Expense, error) {
 	var expenses []models.Expense
-	err := s.DB.Where("user_id = ?", userID).Find(&expenses).Error
+	err := s.DB.Preload("Allocations").Where("user_id = ?", userID).Find(&expenses).Error
 	return expenses, err
 }
 
@@ -55,7 +56,25 @@ func (s *ExpenseService) GetExpensesByFlockAndPeriod(flockID uint, userID uint,
 func (s *ExpenseService) AddExpense(expense *models.Expense) error {
 	log.Println("ℹ️ Adding new expense...")
 
-	if err := s.DB.Create(expense).Error; err != nil {
+	// Purchase order and recurring links are set only by those services
+	clearExpenseLinks(expense)
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCategory(tx, expense.UserID, models.CategoryKindExpense, &expense.CategoryRef); err != nil {
+			return err
+		}
+		if err := prepareAllocation(tx, expense); err != nil {
+			return err
+		}
+		if err := tx.Omit("Attachments", "Allocations").Create(expense).Error; err != nil {
+			return err
+		}
+		if err := saveAllocations(tx, expense); err != nil {
+			return err
+		}
+		return postExpenseEntries(tx, expense.UserID, expense.ID)
+	})
+	if err != nil {
 		log.Printf("❌ Error adding expense: %v\n", err)
 		return err
 	}
@@ -72,29 +91,81 @@ func (s *ExpenseService) AddExpense(expense *models.Expense) error {
 	broadcast.SendNotification(expense.UserID, "New Expense", message, "/expenses")
 	log.Println("✅ Push notification sent.")
 
+	NewBudgetService(s.DB).CheckExpenseAlerts(*expense)
+	queueExpenseRecompute(*expense)
 	return nil
 }
 
-// UpdateExpense updates an existing expense and sends a WebSocket update
+// clearExpenseLinks drops purchase order and recurring links sent by a client
+func clearExpenseLinks(expense *models.Expense) {
+	expense.SupplierID = nil
+	expense.PurchaseOrderID = nil
+	expense.InventoryItemID = nil
+	expense.RecurringExpenseID = nil
+}
+
+// UpdateExpense updates an existing expense, recomputing its split across flocks, and sends a WebSocket update
 func (s *ExpenseService) UpdateExpense(expense *models.Expense) error {
-	if err := s.DB.Save(expense).Error; err != nil {
+	var previous models.Expense
+	if err := s.DB.Preload("Allocations").Where("id = ? AND user_id = ?", expense.ID, expense.UserID).
+		First(&previous).Error; err != nil {
+		return errors.New("expense not found")
+	}
+	// Links can't be changed by an edit
+	expense.SupplierID = previous.SupplierID
+	expense.PurchaseOrderID = previous.PurchaseOrderID
+	expense.InventoryItemID = previous.InventoryItemID
+	expense.RecurringExpenseID = previous.RecurringExpenseID
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCategory(tx, expense.UserID, models.CategoryKindExpense, &expense.CategoryRef); err != nil {
+			return err
+		}
+		if err := prepareAllocation(tx, expense); err != nil {
+			return err
+		}
+		if err := tx.Omit("Attachments", "Allocations").Save(expense).Error; err != nil {
+			return err
+		}
+		if err := saveAllocations(tx, expense); err != nil {
+			return err
+		}
+		return postExpenseEntries(tx, expense.UserID, expense.ID)
+	})
+	if err != nil {
 		return err
 	}
 	broadcast.SendExpenseUpdate(expense.UserID, "expense_updated", *expense)
+	NewBudgetService(s.DB).CheckExpenseAlerts(previous, *expense)
+	queueExpenseRecompute(previous, *expense)
 	return nil
 }
 
 // DeleteExpense removes an expense by ID and sends a WebSocket update
 func (s *ExpenseService) DeleteExpense(expenseID uint, userID uint) error {
 	var expense models.Expense
-	if err := s.DB.Where("id = ? AND user_id = ?", expenseID, userID).First(&expense).Error; err != nil {
+	if err := s.DB.Preload("Allocations").Where("id = ? AND user_id = ?", expenseID, userID).First(&expense).Error; err != nil {
 		return errors.New("expense not found")
 	}
 
-	if err := s.DB.Delete(&expense).Error; err != nil {
+	if err := NewExpenseAttachmentService(s.DB, storage.Default()).DeleteExpenseAttachments(expense.ID); err != nil {
+		return err
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expense_id = ?", expense.ID).Delete(&models.ExpenseAllocation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&expense).Error; err != nil {
+			return err
+		}
+		return postExpenseEntries(tx, userID, expense.ID)
+	})
+	if err != nil {
 		return err
 	}
 
 	broadcast.SendExpenseUpdate(userID, "expense_deleted", expenseID)
+	NewBudgetService(s.DB).CheckExpenseAlerts(expense)
+	queueExpenseRecompute(expense)
 	return nil
 }
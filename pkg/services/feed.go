@@ -0,0 +1,282 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultEggWeightKg is the average egg weight used for FCR when none is supplied
+const DefaultEggWeightKg = 0.06
+
+// FeedService manages feed consumption logs and feed efficiency metrics
+type FeedService struct {
+	DB *gorm.DB
+}
+
+// NewFeedService initializes a new service instance
+func NewFeedService(db *gorm.DB) *FeedService {
+	return &FeedService{DB: db}
+}
+
+// GetFeedLogs retrieves feed logs for a flock, newest first
+func (s *FeedService) GetFeedLogs(flockID, userID uint) ([]models.FeedLog, error) {
+	var logs []models.FeedLog
+	err := s.DB.Where("flock_id = ? AND user_id = ?", flockID, userID).
+		Order("date DESC, id DESC").Find(&logs).Error
+	return logs, err
+}
+
+// AddFeedLog saves a feed log and deducts the feed from the linked inventory item
+func (s *FeedService) AddFeedLog(feedLog *models.FeedLog) error {
+	if err := validateFeedLog(feedLog); err != nil {
+		return err
+	}
+
+	var item *models.InventoryItem
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", feedLog.FlockID, feedLog.UserID).First(&models.Flock{}).Error; err != nil {
+			return errors.New("flock not found")
+		}
+
+		if err := tx.Create(feedLog).Error; err != nil {
+			return err
+		}
+
+		var err error
+		if item, err = s.adjustFeedStock(tx, *feedLog, feedLog.InventoryItemID); err != nil {
+			return err
+		}
+		return s.syncFeedIntake(tx, feedLog.FlockID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.broadcastFeedChange(feedLog.UserID, item)
+	return nil
+}
+
+// UpdateFeedLog edits a feed log and re-syncs the stock of its old and new inventory items
+func (s *FeedService) UpdateFeedLog(logID, userID uint, updated *models.FeedLog) (*models.FeedLog, error) {
+	if err := validateFeedLog(updated); err != nil {
+		return nil, err
+	}
+
+	var feedLog models.FeedLog
+	var items []*models.InventoryItem
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", logID, userID).First(&feedLog).Error; err != nil {
+			return errors.New("feed log not found")
+		}
+
+		previousItemID := feedLog.InventoryItemID
+
+		feedLog.Date = updated.Date
+		feedLog.FeedType = updated.FeedType
+		feedLog.QuantityKg = updated.QuantityKg
+		feedLog.InventoryItemID = updated.InventoryItemID
+		feedLog.AvgBirdWeightKg = updated.AvgBirdWeightKg
+		feedLog.Notes = updated.Notes
+		if err := tx.Save(&feedLog).Error; err != nil {
+			return err
+		}
+
+		if previousItemID != nil && (feedLog.InventoryItemID == nil || *previousItemID != *feedLog.InventoryItemID) {
+			restored, err := s.adjustFeedStock(tx, feedLog, previousItemID)
+			if err != nil {
+				return err
+			}
+			items = append(items, restored)
+		}
+		deducted, err := s.adjustFeedStock(tx, feedLog, feedLog.InventoryItemID)
+		if err != nil {
+			return err
+		}
+		items = append(items, deducted)
+		return s.syncFeedIntake(tx, feedLog.FlockID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		s.broadcastFeedChange(userID, item)
+	}
+	return &feedLog, nil
+}
+
+// DeleteFeedLog removes a feed log and returns its feed to inventory
+func (s *FeedService) DeleteFeedLog(logID, userID uint) error {
+	var item *models.InventoryItem
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var feedLog models.FeedLog
+		if err := tx.Where("id = ? AND user_id = ?", logID, userID).First(&feedLog).Error; err != nil {
+			return errors.New("feed log not found")
+		}
+
+		if err := tx.Delete(&feedLog).Error; err != nil {
+			return err
+		}
+
+		var err error
+		if item, err = s.adjustFeedStock(tx, feedLog, feedLog.InventoryItemID); err != nil {
+			return err
+		}
+		return s.syncFeedIntake(tx, feedLog.FlockID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.broadcastFeedChange(userID, item)
+	return nil
+}
+
+// GetFeedAnalytics computes feed per bird per day and FCR for a flock between start and end.
+// FCR is expressed per kg of eggs (eggs collected x eggWeightKg) and per kg of liveweight gained
+// between the first and last weigh-ins recorded on feed logs in the range.
+func (s *FeedService) GetFeedAnalytics(flock *models.Flock, start, end time.Time, eggWeightKg float64) (*models.FeedAnalytics, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	if eggWeightKg <= 0 {
+		eggWeightKg = DefaultEggWeightKg
+	}
+
+	var logs []models.FeedLog
+	if err := s.DB.Where("flock_id = ? AND user_id = ? AND date BETWEEN ? AND ?", flock.ID, flock.UserID, start, end).
+		Order("date ASC, id ASC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	analytics := &models.FeedAnalytics{
+		FlockID:     flock.ID,
+		StartDate:   start.Format("2006-01-02"),
+		EndDate:     end.Format("2006-01-02"),
+		Days:        int(end.Sub(start).Hours()/24) + 1,
+		FeedByType:  make(map[string]float64),
+		EggWeightKg: eggWeightKg,
+	}
+
+	var firstWeight, lastWeight *float64
+	for _, l := range logs {
+		analytics.TotalFeedKg += l.QuantityKg
+		analytics.FeedByType[l.FeedType] += l.QuantityKg
+		if l.AvgBirdWeightKg != nil {
+			if firstWeight == nil {
+				firstWeight = l.AvgBirdWeightKg
+			}
+			lastWeight = l.AvgBirdWeightKg
+		}
+	}
+
+	history, err := NewMortalityService(s.DB).BirdCountHistory(flock, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, birds := range history {
+		analytics.BirdDays += birds
+	}
+	if analytics.BirdDays > 0 {
+		analytics.FeedPerBirdPerDayG = roundTo(analytics.TotalFeedKg/float64(analytics.BirdDays)*1000, 2)
+	}
+
+	var eggs int64
+	if err := s.DB.Model(&models.EggProduction{}).
+		Where("flock_id = ? AND user_id = ? AND date_produced BETWEEN ? AND ?", flock.ID, flock.UserID, start, end).
+		Select("COALESCE(SUM(eggs_collected), 0)").Scan(&eggs).Error; err != nil {
+		return nil, err
+	}
+	analytics.EggsCollected = int(eggs)
+	analytics.EggMassKg = roundTo(float64(eggs)*eggWeightKg, 3)
+	if analytics.EggMassKg > 0 {
+		analytics.FCREggs = roundTo(analytics.TotalFeedKg/analytics.EggMassKg, 3)
+	}
+
+	if firstWeight != nil && lastWeight != nil && *lastWeight > *firstWeight {
+		analytics.LiveweightGainKg = roundTo((*lastWeight-*firstWeight)*float64(history[analytics.EndDate]), 3)
+		if analytics.LiveweightGainKg > 0 {
+			analytics.FCRLiveweight = roundTo(analytics.TotalFeedKg/analytics.LiveweightGainKg, 3)
+		}
+	}
+
+	analytics.TotalFeedKg = roundTo(analytics.TotalFeedKg, 3)
+	return analytics, nil
+}
+
+// adjustFeedStock records the usage movement that brings an inventory item's feed usage in line with the
+// feed logged against it, dated and referenced on feedLog. Feed items are counted in whole kilograms, so
+// the item is charged the rounded total of all its feed logs; fractions carry over between logs instead
+// of being rounded away on each one.
+func (s *FeedService) adjustFeedStock(tx *gorm.DB, feedLog models.FeedLog, itemID *uint) (*models.InventoryItem, error) {
+	if itemID == nil {
+		return nil, nil
+	}
+
+	var loggedKg float64
+	if err := tx.Model(&models.FeedLog{}).Where("inventory_item_id = ?", *itemID).
+		Select("COALESCE(SUM(quantity_kg), 0)").Scan(&loggedKg).Error; err != nil {
+		return nil, err
+	}
+	var posted int
+	if err := tx.Model(&models.InventoryMovement{}).
+		Where("inventory_item_id = ? AND reference LIKE ?", *itemID, "feed_log:%").
+		Select("COALESCE(SUM(quantity_delta), 0)").Scan(&posted).Error; err != nil {
+		return nil, err
+	}
+	delta := -int(math.Round(loggedKg)) - posted
+	if delta == 0 {
+		return nil, nil
+	}
+
+	reference := fmt.Sprintf("feed_log:%d", feedLog.ID)
+	if delta > 0 {
+		reference += " reversed"
+	}
+
+	flockID := feedLog.FlockID
+	return NewInventoryService(tx).RecordMovement(&models.InventoryMovement{
+		UserID:          feedLog.UserID,
+		InventoryItemID: *itemID,
+		Type:            models.MovementUsage,
+		QuantityDelta:   delta,
+		Reference:       reference,
+		FlockID:         &flockID,
+		Date:            feedLog.Date,
+	})
+}
+
+// syncFeedIntake stores the flock's cumulative feed intake in kg
+func (s *FeedService) syncFeedIntake(tx *gorm.DB, flockID uint) error {
+	var total float64
+	if err := tx.Model(&models.FeedLog{}).Where("flock_id = ?", flockID).
+		Select("COALESCE(SUM(quantity_kg), 0)").Scan(&total).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Flock{ID: flockID}).UpdateColumn("feed_intake", total).Error
+}
+
+func (s *FeedService) broadcastFeedChange(userID uint, item *models.InventoryItem) {
+	if item != nil {
+		broadcast.SendInventoryUpdate(userID, "inventory_updated", *item)
+		NewInventoryService(s.DB).CheckLowStock(item)
+	}
+}
+
+func validateFeedLog(feedLog *models.FeedLog) error {
+	if feedLog.QuantityKg <= 0 {
+		return errors.New("quantity_kg must be greater than zero")
+	}
+	if feedLog.FeedType == "" {
+		return errors.New("feed_type is required")
+	}
+	if feedLog.Date.IsZero() {
+		feedLog.Date = time.Now()
+	}
+	feedLog.Date = truncateDay(feedLog.Date)
+	return nil
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxRebuildMonths bounds an on-demand rebuild so a mistyped range cannot tie up the database
+const maxRebuildMonths = 120
+
+// reconcileRecentMonths is how many months, counting the current one, the nightly reconcile always recomputes
+const reconcileRecentMonths = 3
+
+// flockMonth identifies one flock's financial month
+type flockMonth struct {
+	UserID  uint
+	FlockID uint
+	Year    int
+	Month   int
+}
+
+// financialQueue carries flock-months whose financial data went stale to the aggregation worker
+var financialQueue = make(chan flockMonth, 1024)
+
+// QueueFinancialRecompute schedules the months of a flock containing the given dates for recomputation.
+// It never blocks: if the queue is full the month is left for the nightly reconcile.
+func QueueFinancialRecompute(userID, flockID uint, dates ...time.Time) {
+	for _, date := range dates {
+		month := flockMonth{UserID: userID, FlockID: flockID, Year: date.Year(), Month: int(date.Month())}
+		select {
+		case financialQueue <- month:
+		default:
+			log.Printf("Financial recompute queue full, leaving flock %d %d-%02d to the nightly reconcile",
+				flockID, month.Year, month.Month)
+		}
+	}
+}
+
+// queueExpenseRecompute schedules the flock-months an expense counts towards, directly or through its allocations
+func queueExpenseRecompute(expenses ...models.Expense) {
+	for _, expense := range expenses {
+		if expense.FlockID != nil {
+			QueueFinancialRecompute(expense.UserID, *expense.FlockID, expense.Date)
+		}
+		for _, allocation := range expense.Allocations {
+			QueueFinancialRecompute(expense.UserID, allocation.FlockID, expense.Date)
+		}
+	}
+}
+
+// FinancialAggregationService keeps FlocksFinancialData in step with the sales and expenses behind it
+type FinancialAggregationService struct {
+	DB *gorm.DB
+}
+
+// NewFinancialAggregationService initializes a new service instance
+func NewFinancialAggregationService(db *gorm.DB) *FinancialAggregationService {
+	return &FinancialAggregationService{DB: db}
+}
+
+// Run recomputes queued flock-months until the process exits. Months queued together in a burst,
+// such as a recurring expense catching up, are recomputed once.
+func (s *FinancialAggregationService) Run() {
+	for month := range financialQueue {
+		pending := map[flockMonth]bool{month: true}
+		for drained := false; !drained; {
+			select {
+			case next := <-financialQueue:
+				pending[next] = true
+			default:
+				drained = true
+			}
+		}
+
+		for month := range pending {
+			if _, err := s.RecomputeMonth(month.UserID, month.FlockID, month.Year, month.Month); err != nil {
+				log.Printf("Error recomputing financial data for flock %d %d-%02d: %v", month.FlockID, month.Year, month.Month, err)
+			}
+		}
+	}
+}
+
+// flockTotals is a flock's revenue, net of credit notes, and costs over a period
+type flockTotals struct {
+	Revenue   float64
+	EggSales  float64
+	Expenses  float64
+	StockUsed float64 // the part of Expenses that is the cost of stock used, feed included
+}
+
+// computeFlockTotals totals a flock's sales, credit notes and expenses dated between start and end.
+// Expenses include the flock's share of farm-level expenses and the cost of stock it used; stock
+// purchases are left out since they reach the flock as that usage cost.
+func computeFlockTotals(db *gorm.DB, userID, flockID uint, start, end time.Time) (flockTotals, error) {
+	var totals flockTotals
+	start, last := truncateDay(start), truncateDay(end)
+	end = endOfDay(last)
+
+	eggSalesID, err := EggSalesCategoryID(db)
+	if err != nil {
+		return totals, err
+	}
+
+	var sales struct {
+		Revenue  float64
+		EggSales float64
+	}
+	if err := db.Model(&models.Sale{}).
+		Select("COALESCE(SUM(amount), 0) AS revenue, COALESCE(SUM(CASE WHEN category_id = ? THEN amount ELSE 0 END), 0) AS egg_sales",
+			eggSalesID).
+		Where("user_id = ? AND flock_id = ? AND date BETWEEN ? AND ?", userID, flockID, start, end).
+		Scan(&sales).Error; err != nil {
+		return totals, err
+	}
+
+	var returns struct {
+		Revenue  float64
+		EggSales float64
+	}
+	if err := db.Model(&models.SaleReturn{}).
+		Select("COALESCE(SUM(sale_returns.amount), 0) AS revenue, "+
+			"COALESCE(SUM(CASE WHEN sales.category_id = ? THEN sale_returns.amount ELSE 0 END), 0) AS egg_sales",
+			eggSalesID).
+		Joins("JOIN sales ON sales.id = sale_returns.sale_id").
+		Where("sale_returns.user_id = ? AND sale_returns.flock_id = ? AND sale_returns.date BETWEEN ? AND ?",
+			userID, flockID, start, end).
+		Scan(&returns).Error; err != nil {
+		return totals, err
+	}
+
+	var direct float64
+	if err := db.Model(&models.Expense{}).Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ? AND flock_id = ? AND inventory_item_id IS NULL AND date BETWEEN ? AND ?",
+			userID, flockID, start.Format("2006-01-02"), last.Format("2006-01-02")).
+		Scan(&direct).Error; err != nil {
+		return totals, err
+	}
+
+	var allocated float64
+	if err := db.Model(&models.ExpenseAllocation{}).Select("COALESCE(SUM(expense_allocations.amount), 0)").
+		Joins("JOIN expenses ON expenses.id = expense_allocations.expense_id").
+		Where("expense_allocations.user_id = ? AND expense_allocations.flock_id = ? AND expenses.date BETWEEN ? AND ?",
+			userID, flockID, start.Format("2006-01-02"), last.Format("2006-01-02")).
+		Scan(&allocated).Error; err != nil {
+		return totals, err
+	}
+
+	usageCost, err := NewInventoryService(db).GetInventoryUsageCost(userID, flockID, start, last)
+	if err != nil {
+		return totals, err
+	}
+
+	totals.Revenue = roundTo(sales.Revenue-returns.Revenue, 2)
+	totals.EggSales = roundTo(sales.EggSales-returns.EggSales, 2)
+	totals.Expenses = roundTo(direct+allocated+usageCost, 2)
+	totals.StockUsed = roundTo(usageCost, 2)
+	return totals, nil
+}
+
+// RecomputeMonth rebuilds a flock's financial data for a month from the sales, credit notes and expenses
+// dated in it, as totalled by computeFlockTotals
+func (s *FinancialAggregationService) RecomputeMonth(userID, flockID uint, year, month int) (*models.FlocksFinancialData, error) {
+	start, last := budgetPeriod(year, month)
+	totals, err := computeFlockTotals(s.DB, userID, flockID, start, last)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := NewBudgetService(s.DB).GetFlockBudget(userID, flockID, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	// Upsert on the (flock, year, month) unique index so concurrent recomputes can't insert duplicates
+	data := models.FlocksFinancialData{
+		FlockID:    flockID,
+		UserID:     userID,
+		Month:      month,
+		Year:       year,
+		Revenue:    totals.Revenue,
+		EggSales:   totals.EggSales,
+		Expenses:   totals.Expenses,
+		NetRevenue: roundTo(totals.Revenue-totals.Expenses, 2),
+		Budget:     budget,
+	}
+	err = s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "flock_id"}, {Name: "year"}, {Name: "month"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "revenue", "egg_sales", "expenses", "net_revenue", "budget"}),
+	}).Create(&data).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DB.Where("flock_id = ? AND year = ? AND month = ?", flockID, year, month).First(&data).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Rebuild recomputes every month between start and end for one of the user's flocks,
+// or all of them when flockID is 0, and returns the rebuilt rows
+func (s *FinancialAggregationService) Rebuild(userID, flockID uint, start, end time.Time) ([]models.FlocksFinancialData, error) {
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()) + 1
+	if months > maxRebuildMonths {
+		return nil, fmt.Errorf("a rebuild can cover at most %d months", maxRebuildMonths)
+	}
+
+	query := s.DB.Select("id").Where("user_id = ?", userID)
+	if flockID != 0 {
+		query = query.Where("id = ?", flockID)
+	}
+	var flocks []models.Flock
+	if err := query.Find(&flocks).Error; err != nil {
+		return nil, err
+	}
+	if flockID != 0 && len(flocks) == 0 {
+		return nil, ErrFlockNotFound
+	}
+
+	rebuilt := []models.FlocksFinancialData{}
+	for _, flock := range flocks {
+		for month := firstOfMonth(start); !month.After(end); month = month.AddDate(0, 1, 0) {
+			data, err := s.RecomputeMonth(userID, flock.ID, month.Year(), int(month.Month()))
+			if err != nil {
+				return nil, err
+			}
+			rebuilt = append(rebuilt, *data)
+		}
+	}
+	return rebuilt, nil
+}
+
+// ReconcileAll recomputes, for every flock, the last reconcileRecentMonths months up to now and any
+// earlier month holding a sale, credit note, expense or stock movement changed since the given time.
+// A zero since covers every month with activity. Rows left behind by deleted flocks are removed.
+func (s *FinancialAggregationService) ReconcileAll(now, since time.Time) error {
+	if err := s.DB.Where("flock_id NOT IN (?)", s.DB.Model(&models.Flock{}).Select("id")).
+		Delete(&models.FlocksFinancialData{}).Error; err != nil {
+		return err
+	}
+
+	var flocks []models.Flock
+	if err := s.DB.Select("id, user_id, created_at").Find(&flocks).Error; err != nil {
+		return err
+	}
+
+	months := map[flockMonth]bool{}
+	owners := map[uint]uint{}
+	for _, flock := range flocks {
+		owners[flock.ID] = flock.UserID
+		for i := 0; i < reconcileRecentMonths; i++ {
+			month := firstOfMonth(now).AddDate(0, -i, 0)
+			if month.AddDate(0, 1, 0).After(flock.CreatedAt) {
+				months[flockMonth{UserID: flock.UserID, FlockID: flock.ID, Year: month.Year(), Month: int(month.Month())}] = true
+			}
+		}
+	}
+
+	changed, err := s.changedMonths(since)
+	if err != nil {
+		return err
+	}
+	for _, month := range changed {
+		if userID, ok := owners[month.FlockID]; ok {
+			month.UserID = userID
+			months[month] = true
+		}
+	}
+
+	for month := range months {
+		if _, err := s.RecomputeMonth(month.UserID, month.FlockID, month.Year, month.Month); err != nil {
+			log.Printf("Error recomputing financial data for flock %d %d-%02d: %v", month.FlockID, month.Year, month.Month, err)
+		}
+	}
+	return nil
+}
+
+// changedMonths returns the flock-months of sales, credit notes, expenses and stock movements
+// created or edited since the given time. UserID is left unset.
+func (s *FinancialAggregationService) changedMonths(since time.Time) ([]flockMonth, error) {
+	queries := []*gorm.DB{
+		s.DB.Model(&models.Sale{}).Select("flock_id, YEAR(date) AS year, MONTH(date) AS month").
+			Where("updated_at >= ?", since),
+		s.DB.Model(&models.SaleReturn{}).Select("flock_id, YEAR(date) AS year, MONTH(date) AS month").
+			Where("updated_at >= ?", since),
+		s.DB.Model(&models.Expense{}).Select("flock_id, YEAR(date) AS year, MONTH(date) AS month").
+			Where("flock_id IS NOT NULL AND updated_at >= ?", since),
+		s.DB.Model(&models.ExpenseAllocation{}).Joins("JOIN expenses ON expenses.id = expense_allocations.expense_id").
+			Select("expense_allocations.flock_id, YEAR(expenses.date) AS year, MONTH(expenses.date) AS month").
+			Where("expenses.updated_at >= ?", since),
+		s.DB.Model(&models.InventoryMovement{}).Select("flock_id, YEAR(date) AS year, MONTH(date) AS month").
+			Where("flock_id IS NOT NULL AND created_at >= ?", since),
+	}
+
+	var months []flockMonth
+	for _, query := range queries {
+		var found []flockMonth
+		if err := query.Distinct().Scan(&found).Error; err != nil {
+			return nil, err
+		}
+		months = append(months, found...)
+	}
+	return months, nil
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
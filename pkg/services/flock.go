@@ -12,6 +12,9 @@ import (
 	"time"
 )
 
+// ErrFlockNotFound is returned when a flock does not exist or belongs to another user
+var ErrFlockNotFound = errors.New("flock not found")
+
 type FlockService struct {
 	DB                  *gorm.DB
 	EggProductionService *EggProductionService
@@ -79,7 +82,8 @@ func (s *FlockService) UpdateFlock(flock *models.Flock) error {
 
     s.CalculateFlockMetrics(flock, flock.UserID, start, end)
 
-    if err := s.DB.Save(flock).Error; err != nil {
+    // Bird count and mortality are derived from the mortality log, never written directly
+    if err := s.DB.Omit("bird_count", "mortality_rate", "mortality_rate_data").Save(flock).Error; err != nil {
         log.Printf("❌ Error saving flock ID %d: %v\n", flock.ID, err)
         return err
     }
@@ -108,7 +112,7 @@ func (s *FlockService) DeleteFlock(flockID, userID uint) error {
 func (s *FlockService) CalculateFlockMetrics(flock *models.Flock, userID uint, start, end time.Time) {
     log.Printf("Calculating metrics for Flock ID %d...", flock.ID)
 
-    s.CalculateMortalityRate(flock)
+    // Mortality rate is kept by the mortality log (see MortalityService), not recomputed here
     s.CalculateRevenueAndExpenses(flock, userID, start, end) // ✅ Now includes date range
  
 
@@ -116,7 +120,6 @@ func (s *FlockService) CalculateFlockMetrics(flock *models.Flock, userID uint, s
 
     err := s.DB.Model(&models.Flock{}).Where("id = ?", flock.ID).
         Select("*").Updates(map[string]interface{}{
-        "mortality_rate":         flock.MortalityRate,
         "feed_intake":            flock.FeedIntake,
         "revenue":                flock.Revenue,
         "expenses":               flock.Expenses,
@@ -134,88 +137,15 @@ func (s *FlockService) CalculateFlockMetrics(flock *models.Flock, userID uint, s
 
 
 
-// 🔹 Mortality rate calculation (Stores in DB)
-func (s *FlockService) CalculateMortalityRate(flock *models.Flock) {
-	// Ensure InitialBirdCount is greater than zero to avoid division by zero
-	if flock.InitialBirdCount > 0 {
-		// Calculate total deaths using the difference between InitialBirdCount and current BirdCount
-		totalDeaths := flock.InitialBirdCount - flock.BirdCount
-		flock.MortalityRate = (float64(totalDeaths) / float64(flock.InitialBirdCount)) * 100
-	} else {
-		flock.MortalityRate = 0
-	}
-
-	fmt.Printf("Flock ID %d - Mortality Rate Calculated: %.2f%% (Initial Count: %d, Current Count: %d)\n",
-		flock.ID, flock.MortalityRate, flock.InitialBirdCount, flock.BirdCount)
-
-	// 🔥 Save updated mortality rate in the database
-	err := s.DB.Model(&models.Flock{}).
-		Where("id = ?", flock.ID).
-		Update("mortality_rate", flock.MortalityRate).Error
-
-	if err != nil {
-		fmt.Printf("❌ Error updating mortality rate for flock ID %d: %v\n", flock.ID, err)
-	} else {
-		fmt.Printf("✅ Successfully stored mortality rate for flock ID %d\n", flock.ID)
-	}
-}
-
-
-
-
+// CalculateRevenueAndExpenses recomputes the flock's financial data for the month containing start
 func (s *FlockService) CalculateRevenueAndExpenses(flock *models.Flock, userID uint, start, end time.Time) {
-    var totalRevenue, totalEggSales, totalExpenses float64
-
-    // Fetch sales within the specified date range
-    sales, err := s.SalesService.GetSalesByFlockAndPeriod(flock.ID, userID, start, end)
+    data, err := NewFinancialAggregationService(s.DB).RecomputeMonth(userID, flock.ID, start.Year(), int(start.Month()))
     if err != nil {
-        fmt.Println("Error fetching flock sales:", err)
-        return
-    }
-
-    // Filter and sum revenue from egg sales
-    for _, sale := range sales {
-        if sale.Category == "Egg Sales" {
-            totalEggSales += sale.Amount
-        }
-        totalRevenue += sale.Amount
-    }
-
-    // Fetch expenses within the specified date range
-    expenses, err := s.ExpenseService.GetExpensesByFlockAndPeriod(flock.ID, userID, start, end)
-    if err != nil {
-        fmt.Println("Error fetching flock expenses:", err)
-        return
-    }
-
-    // Sum up all expenses
-    for _, expense := range expenses {
-        totalExpenses += expense.Amount
-    }
-
-    // Compute net revenue (profit/loss)
-    netRevenue := totalRevenue - totalExpenses
-
-    // Store financial data in a separate table
-	financialData := models.FlocksFinancialData{
-		FlockID:    flock.ID,
-		UserID:     userID,
-		Month:      int(start.Month()),  // Convert time.Month to int
-		Year:       start.Year(),
-		Revenue:    totalRevenue,
-		EggSales:   totalEggSales,
-		Expenses:   totalExpenses,
-		NetRevenue: netRevenue,
-	}
-	
-
-    // Upsert into the database
-    if err := s.DB.Where("flock_id = ? AND user_id = ? AND month = ? AND year = ?", flock.ID, userID, start.Month(), start.Year()).
-        Assign(financialData).FirstOrCreate(&financialData).Error; err != nil {
         fmt.Println("Error updating flock financial data:", err)
+        return
     }
 
     fmt.Printf("Flock ID %d - Revenue: %.2f, Egg Sales: %.2f, Expenses: %.2f, Net Revenue: %.2f for %s %d\n",
-        flock.ID, totalRevenue, totalEggSales, totalExpenses, netRevenue, start.Month().String(), start.Year())
+        flock.ID, data.Revenue, data.EggSales, data.Expenses, data.NetRevenue, start.Month().String(), start.Year())
 }
 
@@ -6,6 +6,14 @@ import (
 	"birdseye-backend/pkg/broadcast"
 	"gorm.io/gorm"
 	"fmt"
+	"time"
+)
+
+// Errors returned when a movement asks for more stock than an item or lot can give
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrLotExpired        = errors.New("expired batch")
+	ErrItemHasHistory    = errors.New("item has stock history and cannot be deleted")
 )
 
 // InventoryService provides methods to manage inventory items
@@ -25,14 +33,41 @@ func (s *InventoryService) GetInventoryByUser(userID uint) ([]models.InventoryIt
 	return items, err
 }
 
-/// AddInventoryItem adds a new inventory item, sends a WebSocket update, and notifies the user
+/// AddInventoryItem adds a new inventory item, sends a WebSocket update, and notifies the user.
+// The item's starting Quantity is recorded as an opening balance correction, posted against opening balance equity.
 func (s *InventoryService) AddInventoryItem(item *models.InventoryItem) error {
-	if err := s.DB.Create(item).Error; err != nil {
+	opening := item.Quantity
+	item.Quantity = 0
+	item.LowStockAlerted = false
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		if opening == 0 {
+			return nil
+		}
+
+		updated, err := NewInventoryService(tx).RecordMovement(&models.InventoryMovement{
+			UserID:          item.UserID,
+			InventoryItemID: item.ID,
+			Type:            models.MovementCorrection,
+			QuantityDelta:   opening,
+			UnitCost:        item.CostPerUnit,
+			Reference:       models.OpeningBalanceReference,
+		})
+		if err != nil {
+			return err
+		}
+		item.Quantity = updated.Quantity
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// Send real-time update with userID
 	broadcast.SendInventoryUpdate(item.UserID, "inventory_added", *item)
+	s.CheckLowStock(item)
 
 	// Send notification to the user
 	broadcast.SendNotification(item.UserID, "New Inventory Item Added", 
@@ -42,14 +77,50 @@ func (s *InventoryService) AddInventoryItem(item *models.InventoryItem) error {
 	return nil
 }
 
-// UpdateInventoryItem updates an existing inventory item, sends a WebSocket update, and notifies the user
+// UpdateInventoryItem updates an existing inventory item, sends a WebSocket update, and notifies the user.
+// Quantity is never overwritten; a change to it is recorded as a correction movement.
 func (s *InventoryService) UpdateInventoryItem(item *models.InventoryItem) error {
-	if err := s.DB.Save(item).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.InventoryItem
+		if err := tx.Where("id = ? AND user_id = ?", item.ID, item.UserID).First(&current).Error; err != nil {
+			return errors.New("inventory item not found")
+		}
+
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"item_name":     item.ItemName,
+			"reorder_level": item.ReorderLevel,
+			"cost_per_unit": item.CostPerUnit,
+			"flock_id":      item.FlockID,
+		}).Error; err != nil {
+			return err
+		}
+
+		delta := item.Quantity - current.Quantity
+		item.Quantity = current.Quantity
+		item.LowStockAlerted = current.LowStockAlerted
+		if delta != 0 {
+			updated, err := NewInventoryService(tx).RecordMovement(&models.InventoryMovement{
+				UserID:          item.UserID,
+				InventoryItemID: item.ID,
+				Type:            models.MovementCorrection,
+				QuantityDelta:   delta,
+				UnitCost:        item.CostPerUnit,
+				Reference:       "Manual stock update",
+			})
+			if err != nil {
+				return err
+			}
+			item.Quantity = updated.Quantity
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// Send real-time update with userID
 	broadcast.SendInventoryUpdate(item.UserID, "inventory_updated", *item)
+	s.CheckLowStock(item)
 
 	// Send notification to the user
 	broadcast.SendNotification(item.UserID, "Inventory Item Updated", 
@@ -59,14 +130,42 @@ func (s *InventoryService) UpdateInventoryItem(item *models.InventoryItem) error
 	return nil
 }
 
-// DeleteInventoryItem removes an inventory item by ID, sends a WebSocket update, and notifies the user
+// DeleteInventoryItem removes an inventory item by ID, sends a WebSocket update, and notifies the user.
+// Items with stock movements are refused with ErrItemHasHistory.
 func (s *InventoryService) DeleteInventoryItem(itemID uint, userID uint) error {
 	var item models.InventoryItem
 	if err := s.DB.Where("id = ? AND user_id = ?", itemID, userID).First(&item).Error; err != nil {
 		return errors.New("inventory item not found")
 	}
 
-	if err := s.DB.Delete(&item).Error; err != nil {
+	// Movements are the item's audit trail and back its ledger entries, so they are never removed
+	var movements int64
+	if err := s.DB.Model(&models.InventoryMovement{}).Where("inventory_item_id = ?", item.ID).
+		Count(&movements).Error; err != nil {
+		return err
+	}
+	if movements > 0 {
+		return fmt.Errorf("%w: '%s' has %d movements", ErrItemHasHistory, item.ItemName, movements)
+	}
+
+	// Keep lots that vaccinations point to, so the records stay traceable
+	var referenced int64
+	if err := s.DB.Model(&models.Vaccination{}).Where("inventory_lot_id IN (?)",
+		s.DB.Model(&models.InventoryLot{}).Select("id").Where("inventory_item_id = ?", item.ID)).
+		Count(&referenced).Error; err != nil {
+		return err
+	}
+	if referenced > 0 {
+		return fmt.Errorf("%w: '%s' has lots referenced by vaccinations", ErrItemHasHistory, item.ItemName)
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("inventory_item_id = ?", item.ID).Delete(&models.InventoryLot{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+	if err != nil {
 		return err
 	}
 
@@ -80,3 +179,126 @@ func (s *InventoryService) DeleteInventoryItem(itemID uint, userID uint) error {
 
 	return nil
 }
+
+// RecordMovement saves a stock movement and refreshes the item's Quantity from the sum of its movements.
+// Movements that would take stock below zero are rejected. Stock taken from a lot-tracked item without
+// a LotID is drawn first-expired, first-out and split into one movement per lot (see drawFEFO).
+// Callers inside a transaction should construct the service with the transaction; broadcasting and
+// CheckLowStock are left to the caller.
+func (s *InventoryService) RecordMovement(movement *models.InventoryMovement) (*models.InventoryItem, error) {
+	if !models.IsValidMovementType(movement.Type) {
+		return nil, fmt.Errorf("invalid movement type '%s'", movement.Type)
+	}
+	if movement.QuantityDelta == 0 {
+		return nil, errors.New("quantity_delta cannot be zero")
+	}
+	if movement.Date.IsZero() {
+		movement.Date = time.Now()
+	}
+
+	var item models.InventoryItem
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", movement.InventoryItemID, movement.UserID).First(&item).Error; err != nil {
+			return errors.New("inventory item not found")
+		}
+
+		if item.Quantity+movement.QuantityDelta < 0 {
+			return fmt.Errorf("%w for '%s': %d available", ErrInsufficientStock, item.ItemName, item.Quantity)
+		}
+		if movement.UnitCost == 0 {
+			movement.UnitCost = item.CostPerUnit
+		}
+
+		if movement.LotID != nil {
+			if err := postLotMovement(tx, &item, movement); err != nil {
+				return err
+			}
+		} else if movement.QuantityDelta < 0 {
+			if err := drawFEFO(tx, &item, movement); err != nil {
+				return err
+			}
+		} else if err := tx.Create(movement).Error; err != nil {
+			return err
+		}
+		if err := syncItemQuantity(tx, &item); err != nil {
+			return err
+		}
+		return postInventoryEntries(tx, item.UserID, item.ID, movement.Date)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	movement.ItemName = item.ItemName
+	return &item, nil
+}
+
+// TransferStock moves quantity from one inventory item to another as a pair of transfer movements
+func (s *InventoryService) TransferStock(userID, fromItemID, toItemID uint, quantity int, reference, notes string) ([]*models.InventoryItem, error) {
+	if quantity <= 0 {
+		return nil, errors.New("transfer quantity must be greater than zero")
+	}
+	if fromItemID == toItemID {
+		return nil, errors.New("cannot transfer stock to the same item")
+	}
+
+	var items []*models.InventoryItem
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		txService := NewInventoryService(tx)
+		for _, leg := range []struct {
+			itemID uint
+			delta  int
+		}{{fromItemID, -quantity}, {toItemID, quantity}} {
+			item, err := txService.RecordMovement(&models.InventoryMovement{
+				UserID:          userID,
+				InventoryItemID: leg.itemID,
+				Type:            models.MovementTransfer,
+				QuantityDelta:   leg.delta,
+				Reference:       reference,
+				Notes:           notes,
+			})
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// GetMovements returns an item's movement history between start and end, newest first, with the cost of each issue
+func (s *InventoryService) GetMovements(itemID, userID uint, start, end time.Time) ([]models.InventoryMovement, error) {
+	var item models.InventoryItem
+	if err := s.DB.Where("id = ? AND user_id = ?", itemID, userID).First(&item).Error; err != nil {
+		return nil, errors.New("inventory item not found")
+	}
+
+	// Replay the full history so each movement carries its cost under the user's costing method
+	_, history, err := s.replayItemCosts(itemID, time.Time{}, s.CostingMethodFor(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	movements := []models.InventoryMovement{}
+	for i := len(history) - 1; i >= 0; i-- {
+		m := history[i]
+		if m.Date.Before(start) || m.Date.After(end) {
+			continue
+		}
+		m.ItemName = item.ItemName
+		movements = append(movements, m)
+	}
+	return movements, nil
+}
+
+// syncItemQuantity stores the item's balance as the sum of its movements
+func syncItemQuantity(tx *gorm.DB, item *models.InventoryItem) error {
+	var balance int64
+	if err := tx.Model(&models.InventoryMovement{}).Where("inventory_item_id = ?", item.ID).
+		Select("COALESCE(SUM(quantity_delta), 0)").Scan(&balance).Error; err != nil {
+		return err
+	}
+	item.Quantity = int(balance)
+	return tx.Model(item).UpdateColumn("quantity", item.Quantity).Error
+}
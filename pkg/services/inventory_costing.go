@@ -0,0 +1,222 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"time"
+)
+
+// costLayer is a quantity of stock held at one unit cost
+type costLayer struct {
+	quantity int
+	unitCost float64
+}
+
+// costLedger replays an item's movements to cost stock issued and value stock on hand.
+// FIFO keeps one layer per receipt and issues the oldest first; weighted average keeps a single
+// layer whose unit cost is re-averaged on every purchase.
+type costLedger struct {
+	method   string
+	layers   []costLayer
+	lastCost float64
+}
+
+func newCostLedger(method string) *costLedger {
+	if !models.IsValidCostingMethod(method) {
+		method = models.DefaultCostingMethod
+	}
+	return &costLedger{method: method}
+}
+
+func (l *costLedger) quantity() int {
+	total := 0
+	for _, layer := range l.layers {
+		total += layer.quantity
+	}
+	return total
+}
+
+func (l *costLedger) value() float64 {
+	total := 0.0
+	for _, layer := range l.layers {
+		total += float64(layer.quantity) * layer.unitCost
+	}
+	return total
+}
+
+// averageCost is the current value per unit, or the last known unit cost when there is no stock
+func (l *costLedger) averageCost() float64 {
+	if q := l.quantity(); q > 0 {
+		return l.value() / float64(q)
+	}
+	return l.lastCost
+}
+
+// receive adds stock at unitCost
+func (l *costLedger) receive(quantity int, unitCost float64) {
+	l.lastCost = unitCost
+	if l.method == models.CostingWeightedAverage && len(l.layers) > 0 {
+		q := l.layers[0].quantity + quantity
+		if q > 0 {
+			l.layers[0].unitCost = (l.value() + float64(quantity)*unitCost) / float64(q)
+		}
+		l.layers[0].quantity = q
+		return
+	}
+	l.layers = append(l.layers, costLayer{quantity: quantity, unitCost: unitCost})
+}
+
+// issue removes stock and returns its cost. Quantity beyond the layers held is costed at the last known unit cost.
+func (l *costLedger) issue(quantity int) float64 {
+	cost := 0.0
+	for quantity > 0 && len(l.layers) > 0 {
+		layer := &l.layers[0]
+		take := layer.quantity
+		if take > quantity {
+			take = quantity
+		}
+		cost += float64(take) * layer.unitCost
+		l.lastCost = layer.unitCost
+		layer.quantity -= take
+		quantity -= take
+		if layer.quantity == 0 {
+			l.layers = l.layers[1:]
+		}
+	}
+	return cost + float64(quantity)*l.lastCost
+}
+
+// apply posts one movement and returns the cost of stock it issued (negative for stock returned).
+// Purchases come in at their own unit cost; other stock coming in, such as returns from usage,
+// transfers and corrections, comes in at the current average so it does not distort the cost base.
+func (l *costLedger) apply(m models.InventoryMovement) float64 {
+	if m.QuantityDelta < 0 {
+		return l.issue(-m.QuantityDelta)
+	}
+
+	unitCost := m.UnitCost
+	if m.Type != models.MovementPurchase && l.quantity() > 0 {
+		unitCost = l.averageCost()
+	}
+	l.receive(m.QuantityDelta, unitCost)
+	if m.Type == models.MovementUsage || m.Type == models.MovementWastage {
+		return -float64(m.QuantityDelta) * unitCost
+	}
+	return 0
+}
+
+// CostingMethodFor returns the user's inventory costing method, falling back to the default
+func (s *InventoryService) CostingMethodFor(userID uint) string {
+	var user models.User
+	if err := s.DB.Select("id, costing_method").First(&user, userID).Error; err != nil || !models.IsValidCostingMethod(user.CostingMethod) {
+		return models.DefaultCostingMethod
+	}
+	return user.CostingMethod
+}
+
+// replayItemCosts replays an item's movements up to and including asOf under method, setting each movement's Cost.
+// A zero asOf replays every movement.
+func (s *InventoryService) replayItemCosts(itemID uint, asOf time.Time, method string) (*costLedger, []models.InventoryMovement, error) {
+	query := s.DB.Where("inventory_item_id = ?", itemID)
+	if !asOf.IsZero() {
+		query = query.Where("date <= ?", asOf)
+	}
+
+	var movements []models.InventoryMovement
+	if err := query.Order("date ASC, id ASC").Find(&movements).Error; err != nil {
+		return nil, nil, err
+	}
+
+	ledger := newCostLedger(method)
+	for i := range movements {
+		movements[i].Cost = roundTo(ledger.apply(movements[i]), 2)
+	}
+	return ledger, movements, nil
+}
+
+// GetValuation values a user's stock at the end of asOf's day from purchase movements under their costing method
+func (s *InventoryService) GetValuation(userID uint, asOf time.Time) (*models.InventoryValuation, error) {
+	method := s.CostingMethodFor(userID)
+	asOfEnd := endOfDay(asOf)
+
+	var items []models.InventoryItem
+	if err := s.DB.Where("user_id = ?", userID).Order("item_name ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	valuation := &models.InventoryValuation{AsOf: truncateDay(asOf), CostingMethod: method, Items: []models.ItemValuation{}}
+	for _, item := range items {
+		ledger, _, err := s.replayItemCosts(item.ID, asOfEnd, method)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := models.ItemValuation{
+			InventoryItemID: item.ID,
+			ItemName:        item.ItemName,
+			FlockID:         item.FlockID,
+			Quantity:        ledger.quantity(),
+			Value:           roundTo(ledger.value(), 2),
+		}
+		if entry.Quantity > 0 {
+			entry.UnitCost = roundTo(ledger.value()/float64(entry.Quantity), 2)
+		}
+		valuation.TotalValue += entry.Value
+		valuation.Items = append(valuation.Items, entry)
+	}
+	valuation.TotalValue = roundTo(valuation.TotalValue, 2)
+
+	return valuation, nil
+}
+
+// GetInventoryUsageCost totals the cost of stock used or wasted on a flock between start and end under
+// the user's costing method. Movements without a flock count against the item's flock.
+// An end date without a time of day includes that whole day.
+func (s *InventoryService) GetInventoryUsageCost(userID, flockID uint, start, end time.Time) (float64, error) {
+	method := s.CostingMethodFor(userID)
+	if end.Equal(truncateDay(end)) {
+		end = endOfDay(end)
+	}
+	outflowTypes := []string{models.MovementUsage, models.MovementWastage}
+
+	var itemIDs []uint
+	if err := s.DB.Model(&models.InventoryMovement{}).
+		Joins("JOIN inventory_items ON inventory_items.id = inventory_movements.inventory_item_id").
+		Where("inventory_movements.user_id = ? AND inventory_movements.type IN ? AND inventory_movements.date BETWEEN ? AND ?",
+			userID, outflowTypes, start, end).
+		Where("COALESCE(inventory_movements.flock_id, inventory_items.flock_id) = ?", flockID).
+		Distinct().Pluck("inventory_movements.inventory_item_id", &itemIDs).Error; err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, itemID := range itemIDs {
+		var item models.InventoryItem
+		if err := s.DB.Select("id, flock_id").First(&item, itemID).Error; err != nil {
+			return 0, err
+		}
+
+		_, movements, err := s.replayItemCosts(itemID, end, method)
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range movements {
+			if m.Date.Before(start) || (m.Type != models.MovementUsage && m.Type != models.MovementWastage) {
+				continue
+			}
+			movementFlock := item.FlockID
+			if m.FlockID != nil {
+				movementFlock = *m.FlockID
+			}
+			if movementFlock == flockID {
+				total += m.Cost
+			}
+		}
+	}
+
+	return roundTo(total, 2), nil
+}
+
+// endOfDay returns the last instant of t's day
+func endOfDay(t time.Time) time.Time {
+	return truncateDay(t).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"math"
+	"testing"
+)
+
+func TestCostLedger(t *testing.T) {
+	movements := []models.InventoryMovement{
+		{Type: models.MovementPurchase, QuantityDelta: 10, UnitCost: 100},
+		{Type: models.MovementPurchase, QuantityDelta: 10, UnitCost: 130},
+		{Type: models.MovementUsage, QuantityDelta: -15},
+		{Type: models.MovementPurchase, QuantityDelta: 5, UnitCost: 160},
+		{Type: models.MovementWastage, QuantityDelta: -8},
+	}
+
+	tests := []struct {
+		method   string
+		costs    []float64 // cost returned for each movement
+		quantity int
+		value    float64
+	}{
+		{
+			// 10 @ 100 then 5 @ 130 go to usage; the remaining 5 @ 130 and 3 of the 5 @ 160 are wasted
+			method:   models.CostingFIFO,
+			costs:    []float64{0, 0, 1650, 0, 1130},
+			quantity: 2,
+			value:    320,
+		},
+		{
+			// 20 average 115; usage costs 15 x 115; 5 @ 115 + 5 @ 160 average 137.5
+			method:   models.CostingWeightedAverage,
+			costs:    []float64{0, 0, 1725, 0, 1100},
+			quantity: 2,
+			value:    275,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			ledger := newCostLedger(tt.method)
+			for i, m := range movements {
+				if cost := ledger.apply(m); math.Abs(cost-tt.costs[i]) > 1e-9 {
+					t.Errorf("movement %d (%s %d) cost = %.2f, want %.2f", i, m.Type, m.QuantityDelta, cost, tt.costs[i])
+				}
+			}
+			if q := ledger.quantity(); q != tt.quantity {
+				t.Errorf("quantity = %d, want %d", q, tt.quantity)
+			}
+			if v := ledger.value(); math.Abs(v-tt.value) > 1e-9 {
+				t.Errorf("value = %.2f, want %.2f", v, tt.value)
+			}
+		})
+	}
+}
+
+func TestCostLedgerEdgeCases(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		movements []models.InventoryMovement
+		lastCost  float64 // cost returned for the last movement
+		value     float64
+	}{
+		{
+			name:   "unknown method falls back to the default",
+			method: "lifo",
+			movements: []models.InventoryMovement{
+				{Type: models.MovementPurchase, QuantityDelta: 1, UnitCost: 10},
+				{Type: models.MovementPurchase, QuantityDelta: 1, UnitCost: 20},
+				{Type: models.MovementUsage, QuantityDelta: -1},
+			},
+			lastCost: 15,
+			value:    15,
+		},
+		{
+			name:   "issuing beyond stock costs the excess at the last unit cost",
+			method: models.CostingFIFO,
+			movements: []models.InventoryMovement{
+				{Type: models.MovementPurchase, QuantityDelta: 2, UnitCost: 50},
+				{Type: models.MovementUsage, QuantityDelta: -5},
+			},
+			lastCost: 250,
+			value:    0,
+		},
+		{
+			name:   "stock returned from usage comes back at the current average",
+			method: models.CostingFIFO,
+			movements: []models.InventoryMovement{
+				{Type: models.MovementPurchase, QuantityDelta: 4, UnitCost: 10},
+				{Type: models.MovementPurchase, QuantityDelta: 4, UnitCost: 20},
+				{Type: models.MovementUsage, QuantityDelta: 2, UnitCost: 99},
+			},
+			lastCost: -30,
+			value:    150,
+		},
+		{
+			name:   "corrections into an empty item use their own unit cost",
+			method: models.CostingWeightedAverage,
+			movements: []models.InventoryMovement{
+				{Type: models.MovementCorrection, QuantityDelta: 3, UnitCost: 40},
+			},
+			lastCost: 0,
+			value:    120,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := newCostLedger(tt.method)
+			var cost float64
+			for _, m := range tt.movements {
+				cost = ledger.apply(m)
+			}
+			if math.Abs(cost-tt.lastCost) > 1e-9 {
+				t.Errorf("last movement cost = %.2f, want %.2f", cost, tt.lastCost)
+			}
+			if v := ledger.value(); math.Abs(v-tt.value) > 1e-9 {
+				t.Errorf("value = %.2f, want %.2f", v, tt.value)
+			}
+		})
+	}
+}
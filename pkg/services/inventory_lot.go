@@ -0,0 +1,380 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services/email"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetLot returns a single lot owned by the user
+func (s *InventoryService) GetLot(lotID, userID uint) (*models.InventoryLot, error) {
+	var lot models.InventoryLot
+	if err := s.DB.Where("id = ? AND user_id = ?", lotID, userID).First(&lot).Error; err != nil {
+		return nil, errors.New("inventory lot not found")
+	}
+	return &lot, nil
+}
+
+// GetLots returns an item's lots in FEFO order. Lots with no stock left are only included when includeEmpty is set.
+func (s *InventoryService) GetLots(itemID, userID uint, includeEmpty bool) ([]models.InventoryLot, error) {
+	var item models.InventoryItem
+	if err := s.DB.Where("id = ? AND user_id = ?", itemID, userID).First(&item).Error; err != nil {
+		return nil, errors.New("inventory item not found")
+	}
+
+	query := s.DB.Where("inventory_item_id = ?", itemID)
+	if !includeEmpty {
+		query = query.Where("quantity > 0")
+	}
+
+	var lots []models.InventoryLot
+	if err := query.Order("expiry_date ASC, id ASC").Find(&lots).Error; err != nil {
+		return nil, err
+	}
+	for i := range lots {
+		fillLotFields(&lots[i], item.ItemName)
+	}
+	return lots, nil
+}
+
+// AddLot receives quantity into a lot of an item, creating the lot if its batch number is new.
+// Stock is posted as a purchase movement against the lot.
+func (s *InventoryService) AddLot(lot *models.InventoryLot, quantity int, reference string) (*models.InventoryItem, error) {
+	lot.BatchNumber = strings.TrimSpace(lot.BatchNumber)
+	if lot.BatchNumber == "" {
+		return nil, errors.New("batch number is required")
+	}
+	if lot.ExpiryDate.IsZero() {
+		return nil, errors.New("expiry date is required")
+	}
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be greater than zero")
+	}
+	if lot.ReceivedDate.IsZero() {
+		lot.ReceivedDate = time.Now()
+	}
+
+	var item *models.InventoryItem
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.InventoryLot
+		err := tx.Where("inventory_item_id = ? AND batch_number = ?", lot.InventoryItemID, lot.BatchNumber).First(&existing).Error
+		switch {
+		case err == nil:
+			if existing.ExpiryDate.Format("2006-01-02") != lot.ExpiryDate.Format("2006-01-02") {
+				return fmt.Errorf("batch %s is already recorded with expiry %s", existing.BatchNumber, existing.ExpiryDate.Format("2006-01-02"))
+			}
+			*lot = existing
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			var owner models.InventoryItem
+			if err := tx.Where("id = ? AND user_id = ?", lot.InventoryItemID, lot.UserID).First(&owner).Error; err != nil {
+				return errors.New("inventory item not found")
+			}
+			if lot.UnitCost == 0 {
+				lot.UnitCost = owner.CostPerUnit
+			}
+			lot.ID = 0
+			lot.Quantity = 0
+			lot.ExpiryAlerted = false
+			if err := tx.Create(lot).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		lotID := lot.ID
+		item, err = NewInventoryService(tx).RecordMovement(&models.InventoryMovement{
+			UserID:          lot.UserID,
+			InventoryItemID: lot.InventoryItemID,
+			Type:            models.MovementPurchase,
+			QuantityDelta:   quantity,
+			UnitCost:        lot.UnitCost,
+			Reference:       reference,
+			LotID:           &lotID,
+			Date:            lot.ReceivedDate,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.First(lot, lot.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fillLotFields(lot, item.ItemName)
+	return item, nil
+}
+
+// UpdateLot corrects a lot's batch number or expiry date. Changing the expiry re-arms its expiry warning.
+func (s *InventoryService) UpdateLot(lotID, userID uint, batchNumber string, expiryDate time.Time) (*models.InventoryLot, error) {
+	lot, err := s.GetLot(lotID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if batchNumber = strings.TrimSpace(batchNumber); batchNumber != "" {
+		updates["batch_number"] = batchNumber
+	}
+	if !expiryDate.IsZero() && expiryDate.Format("2006-01-02") != lot.ExpiryDate.Format("2006-01-02") {
+		updates["expiry_date"] = expiryDate
+		updates["expiry_alerted"] = false
+	}
+	if len(updates) > 0 {
+		if err := s.DB.Model(lot).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.GetLot(lotID, userID)
+}
+
+// GetExpiringLots returns a user's lots with stock that expire within days, including lots already expired
+func (s *InventoryService) GetExpiringLots(userID uint, days int) ([]models.InventoryLot, error) {
+	cutoff := truncateDay(time.Now()).AddDate(0, 0, days)
+
+	var lots []models.InventoryLot
+	if err := s.DB.Where("user_id = ? AND quantity > 0 AND expiry_date <= ?", userID, cutoff).
+		Order("expiry_date ASC, id ASC").Find(&lots).Error; err != nil {
+		return nil, err
+	}
+	if err := s.fillLotItemNames(lots); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// CheckExpiringLots warns each user once about lots with stock expiring within their ExpiryWarningDays.
+// It is meant to run daily.
+func (s *InventoryService) CheckExpiringLots() error {
+	var lots []models.InventoryLot
+	if err := s.DB.Where("quantity > 0 AND expiry_alerted = ?", false).
+		Order("user_id, expiry_date").Find(&lots).Error; err != nil {
+		return err
+	}
+	if err := s.fillLotItemNames(lots); err != nil {
+		return err
+	}
+
+	byUser := map[uint][]models.InventoryLot{}
+	var userIDs []uint
+	for _, lot := range lots {
+		if _, ok := byUser[lot.UserID]; !ok {
+			userIDs = append(userIDs, lot.UserID)
+		}
+		byUser[lot.UserID] = append(byUser[lot.UserID], lot)
+	}
+
+	for _, userID := range userIDs {
+		user, err := GetUserByID(userID)
+		if err != nil {
+			log.Printf("Error fetching user %d for lot expiry check: %v", userID, err)
+			continue
+		}
+		warningDays := user.ExpiryWarningDays
+		if warningDays <= 0 {
+			warningDays = models.DefaultExpiryWarningDays
+		}
+
+		var expiring []models.InventoryLot
+		for _, lot := range byUser[userID] {
+			if lot.DaysUntilExpiry > warningDays {
+				continue
+			}
+			// Flip the flag only if nobody else has, so each lot is warned about once
+			result := s.DB.Model(&models.InventoryLot{}).
+				Where("id = ? AND expiry_alerted = ?", lot.ID, false).
+				UpdateColumn("expiry_alerted", true)
+			if result.Error != nil {
+				log.Printf("Error updating expiry flag for lot %d: %v", lot.ID, result.Error)
+				continue
+			}
+			if result.RowsAffected > 0 {
+				expiring = append(expiring, lot)
+			}
+		}
+		if len(expiring) > 0 {
+			s.sendLotExpiryAlert(user, expiring, warningDays)
+		}
+	}
+	return nil
+}
+
+// sendLotExpiryAlert notifies a user in-app, over websocket and optionally by email about expiring lots
+func (s *InventoryService) sendLotExpiryAlert(user *models.User, lots []models.InventoryLot, warningDays int) {
+	title := fmt.Sprintf("%d inventory lot(s) expiring soon", len(lots))
+	var parts []string
+	for _, lot := range lots {
+		parts = append(parts, fmt.Sprintf("%s batch %s (%d left, expires %s)",
+			lot.ItemName, lot.BatchNumber, lot.Quantity, lot.ExpiryDate.Format("2006-01-02")))
+	}
+	message := fmt.Sprintf("Expiring within %d days: %s.", warningDays, strings.Join(parts, "; "))
+
+	notification := models.Notification{
+		UserID: user.ID,
+		Title:  title,
+		Body:   message,
+		Type:   "warning",
+		URL:    "/inventory",
+	}
+	if err := NewNotificationService(s.DB).CreateNotification(&notification); err != nil {
+		log.Printf("Error saving lot expiry notification for user %d: %v", user.ID, err)
+	}
+
+	broadcast.SendInventoryUpdate(user.ID, "inventory_lots_expiring", lots)
+	broadcast.SendNotification(user.ID, title, message, "/inventory")
+
+	if user.LowStockEmails {
+		if err := email.SendLotExpiryEmail(user.Email, user.Username, lots, warningDays); err != nil {
+			log.Printf("Error sending lot expiry email for user %d: %v", user.ID, err)
+		}
+	}
+}
+
+// postLotMovement saves a movement against the lot it names and refreshes the lot's quantity.
+// Expired lots cannot be drawn for usage; they can still be written off as wastage.
+func postLotMovement(tx *gorm.DB, item *models.InventoryItem, movement *models.InventoryMovement) error {
+	var lot models.InventoryLot
+	if err := tx.Where("id = ? AND inventory_item_id = ?", *movement.LotID, item.ID).First(&lot).Error; err != nil {
+		return errors.New("inventory lot not found for this item")
+	}
+
+	if movement.QuantityDelta < 0 {
+		if lot.Quantity+movement.QuantityDelta < 0 {
+			return fmt.Errorf("%w in batch %s: %d available", ErrInsufficientStock, lot.BatchNumber, lot.Quantity)
+		}
+		if movement.Type == models.MovementUsage && lot.IsExpired(movement.Date) {
+			return fmt.Errorf("%w: batch %s expired on %s", ErrLotExpired, lot.BatchNumber, lot.ExpiryDate.Format("2006-01-02"))
+		}
+		if lot.UnitCost > 0 {
+			movement.UnitCost = lot.UnitCost
+		}
+	}
+
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+	return syncLotQuantity(tx, &lot)
+}
+
+// drawFEFO takes stock out of a lot-tracked item first-expired, first-out, saving one movement per lot drawn.
+// The passed movement becomes the first draw. Stock held outside any lot is drawn last, and usage skips
+// expired lots. Items without lots are saved as a single movement.
+func drawFEFO(tx *gorm.DB, item *models.InventoryItem, movement *models.InventoryMovement) error {
+	var lots []models.InventoryLot
+	if err := tx.Where("inventory_item_id = ? AND quantity > 0", item.ID).
+		Order("expiry_date ASC, id ASC").Find(&lots).Error; err != nil {
+		return err
+	}
+	if len(lots) == 0 {
+		return tx.Create(movement).Error
+	}
+
+	untracked := item.Quantity
+	for _, lot := range lots {
+		untracked -= lot.Quantity
+	}
+
+	type draw struct {
+		lot      *models.InventoryLot
+		quantity int
+	}
+	var draws []draw
+	need := -movement.QuantityDelta
+	for i := range lots {
+		if need == 0 {
+			break
+		}
+		if movement.Type == models.MovementUsage && lots[i].IsExpired(movement.Date) {
+			continue
+		}
+		take := lots[i].Quantity
+		if take > need {
+			take = need
+		}
+		draws = append(draws, draw{lot: &lots[i], quantity: take})
+		need -= take
+	}
+	if need > 0 {
+		if untracked < need {
+			return fmt.Errorf("%w: not enough unexpired stock for '%s'", ErrInsufficientStock, item.ItemName)
+		}
+		draws = append(draws, draw{quantity: need})
+	}
+
+	base := *movement
+	for i, d := range draws {
+		part := movement
+		if i > 0 {
+			copied := base
+			part = &copied
+		}
+		part.QuantityDelta = -d.quantity
+		part.LotID = nil
+		if d.lot != nil {
+			lotID := d.lot.ID
+			part.LotID = &lotID
+			if d.lot.UnitCost > 0 {
+				part.UnitCost = d.lot.UnitCost
+			}
+		}
+
+		if err := tx.Create(part).Error; err != nil {
+			return err
+		}
+		if d.lot != nil {
+			if err := syncLotQuantity(tx, d.lot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncLotQuantity stores the lot's balance as the sum of its movements
+func syncLotQuantity(tx *gorm.DB, lot *models.InventoryLot) error {
+	var balance int64
+	if err := tx.Model(&models.InventoryMovement{}).Where("lot_id = ?", lot.ID).
+		Select("COALESCE(SUM(quantity_delta), 0)").Scan(&balance).Error; err != nil {
+		return err
+	}
+	lot.Quantity = int(balance)
+	return tx.Model(lot).UpdateColumn("quantity", lot.Quantity).Error
+}
+
+// fillLotItemNames sets ItemName and DaysUntilExpiry on each lot
+func (s *InventoryService) fillLotItemNames(lots []models.InventoryLot) error {
+	if len(lots) == 0 {
+		return nil
+	}
+
+	itemIDs := make([]uint, 0, len(lots))
+	for _, lot := range lots {
+		itemIDs = append(itemIDs, lot.InventoryItemID)
+	}
+	var items []models.InventoryItem
+	if err := s.DB.Select("id, item_name").Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(items))
+	for _, item := range items {
+		names[item.ID] = item.ItemName
+	}
+
+	for i := range lots {
+		fillLotFields(&lots[i], names[lots[i].InventoryItemID])
+	}
+	return nil
+}
+
+func fillLotFields(lot *models.InventoryLot, itemName string) {
+	lot.ItemName = itemName
+	lot.DaysUntilExpiry = daysBetween(time.Now(), lot.ExpiryDate)
+}
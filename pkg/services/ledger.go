@@ -0,0 +1,643 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnbalancedEntry is returned when a journal entry's debits and credits differ
+var ErrUnbalancedEntry = errors.New("journal entry does not balance")
+
+// LedgerService posts journal entries from sales, expenses and stock movements and reads statements from them
+type LedgerService struct {
+	DB *gorm.DB
+}
+
+// NewLedgerService initializes a new service instance
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{DB: db}
+}
+
+// GetAccounts returns the chart of accounts in code order
+func (s *LedgerService) GetAccounts() ([]models.Account, error) {
+	var accounts []models.Account
+	err := s.DB.Order("code ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// GetEntries returns the user's journal entries dated between start and end with their lines, oldest first.
+// A non-zero accountID keeps only entries that post to that account.
+func (s *LedgerService) GetEntries(userID, accountID uint, start, end time.Time) ([]models.JournalEntry, error) {
+	query := s.DB.Preload("Lines.Account").
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, truncateDay(start), endOfDay(end))
+	if accountID != 0 {
+		query = query.Where("id IN (?)", s.DB.Model(&models.JournalLine{}).Select("journal_entry_id").
+			Where("account_id = ?", accountID))
+	}
+
+	entries := []models.JournalEntry{}
+	err := query.Order("date ASC, id ASC").Find(&entries).Error
+	return entries, err
+}
+
+// GetTrialBalance totals every account the user has posted to up to the end of asOf's day
+func (s *LedgerService) GetTrialBalance(userID uint, asOf time.Time) (*models.TrialBalance, error) {
+	balances, err := s.balances(userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	trial := &models.TrialBalance{AsOf: truncateDay(asOf).Format("2006-01-02"), Accounts: balances}
+	for _, balance := range balances {
+		trial.Debit += balance.Debit
+		trial.Credit += balance.Credit
+	}
+	trial.Debit, trial.Credit = roundTo(trial.Debit, 2), roundTo(trial.Credit, 2)
+	trial.Balanced = math.Abs(trial.Debit-trial.Credit) < 0.005
+	return trial, nil
+}
+
+// GetBalanceSheet returns the user's assets, liabilities and equity at the end of asOf's day
+func (s *LedgerService) GetBalanceSheet(userID uint, asOf time.Time) (*models.BalanceSheet, error) {
+	balances, err := s.balances(userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &models.BalanceSheet{
+		AsOf:        truncateDay(asOf).Format("2006-01-02"),
+		Assets:      []models.AccountBalance{},
+		Liabilities: []models.AccountBalance{},
+		Equity:      []models.AccountBalance{},
+	}
+	for _, balance := range balances {
+		switch balance.Type {
+		case models.AccountAsset:
+			sheet.Assets = append(sheet.Assets, balance)
+			sheet.TotalAssets += balance.Balance
+		case models.AccountLiability:
+			sheet.Liabilities = append(sheet.Liabilities, balance)
+			sheet.TotalLiabilities += balance.Balance
+		case models.AccountEquity:
+			sheet.Equity = append(sheet.Equity, balance)
+			sheet.TotalEquity += balance.Balance
+		default:
+			sheet.RetainedEarnings += balance.Credit - balance.Debit
+		}
+	}
+	sheet.RetainedEarnings = roundTo(sheet.RetainedEarnings, 2)
+	sheet.TotalAssets = roundTo(sheet.TotalAssets, 2)
+	sheet.TotalLiabilities = roundTo(sheet.TotalLiabilities, 2)
+	sheet.TotalEquity = roundTo(sheet.TotalEquity+sheet.RetainedEarnings, 2)
+	return sheet, nil
+}
+
+// GetProfitAndLoss reads revenue and expenses posted between start and end from the ledger, by account
+// and category, for one flock or the whole farm when flockID is 0
+func (s *LedgerService) GetProfitAndLoss(userID, flockID uint, start, end time.Time) (*models.LedgerProfitAndLoss, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	if flockID != 0 {
+		var count int64
+		if err := s.DB.Model(&models.Flock{}).Where("id = ? AND user_id = ?", flockID, userID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrFlockNotFound
+		}
+	}
+
+	var rows []struct {
+		Code       string
+		Name       string
+		Type       string
+		CategoryID uint
+		Category   string
+		Debit      float64
+		Credit     float64
+	}
+	query := s.DB.Model(&models.JournalLine{}).
+		Select("accounts.code, accounts.name, accounts.type, journal_lines.category_id, MAX(journal_lines.category) AS category, "+
+			"SUM(journal_lines.debit) AS debit, SUM(journal_lines.credit) AS credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.user_id = ? AND journal_entries.date BETWEEN ? AND ?", userID, start, endOfDay(end)).
+		Where("accounts.type IN ?", []string{models.AccountRevenue, models.AccountExpense})
+	if flockID != 0 {
+		query = query.Where("journal_lines.flock_id = ?", flockID)
+	}
+	if err := query.Group("accounts.code, accounts.name, accounts.type, journal_lines.category_id").
+		Order("accounts.code ASC, category ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	statement := &models.LedgerProfitAndLoss{
+		Start:    start.Format("2006-01-02"),
+		End:      end.Format("2006-01-02"),
+		Revenue:  []models.LedgerProfitAndLossLine{},
+		Expenses: []models.LedgerProfitAndLossLine{},
+		Total:    models.ProfitAndLossPeriod{Start: start.Format("2006-01-02"), End: end.Format("2006-01-02")},
+	}
+	if flockID != 0 {
+		statement.FlockID = &flockID
+	}
+	for _, row := range rows {
+		line := models.LedgerProfitAndLossLine{
+			Code:       row.Code,
+			Account:    row.Name,
+			CategoryID: row.CategoryID,
+			Category:   row.Category,
+		}
+		if row.Type == models.AccountRevenue {
+			line.Amount = roundTo(row.Credit-row.Debit, 2)
+			statement.Revenue = append(statement.Revenue, line)
+			statement.Total.Revenue += line.Amount
+		} else {
+			line.Amount = roundTo(row.Debit-row.Credit, 2)
+			statement.Expenses = append(statement.Expenses, line)
+			statement.Total.Costs += line.Amount
+		}
+	}
+	statement.Total.Revenue = roundTo(statement.Total.Revenue, 2)
+	statement.Total.Costs = roundTo(statement.Total.Costs, 2)
+	statement.Total.NetProfit = roundTo(statement.Total.Revenue-statement.Total.Costs, 2)
+	return statement, nil
+}
+
+// Rebuild removes the user's journal entries and posts them again from their sales, expenses and stock movements
+func (s *LedgerService) Rebuild(userID uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("journal_entry_id IN (?)", tx.Model(&models.JournalEntry{}).Select("id").
+			Where("user_id = ?", userID)).Delete(&models.JournalLine{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.JournalEntry{}).Error; err != nil {
+			return err
+		}
+
+		sources := []struct {
+			model interface{}
+			post  func(tx *gorm.DB, userID, id uint) error
+		}{
+			{&models.Sale{}, postSaleEntries},
+			{&models.Expense{}, postExpenseEntries},
+			{&models.InventoryItem{}, func(tx *gorm.DB, userID, id uint) error {
+				return postInventoryEntries(tx, userID, id, time.Time{})
+			}},
+		}
+		for _, source := range sources {
+			var ids []uint
+			if err := tx.Model(source.model).Where("user_id = ?", userID).Order("id ASC").Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := source.post(tx, userID, id); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// RepostInventory reposts the user's stock movements, after a change of costing method
+func (s *LedgerService) RepostInventory(userID uint) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&models.InventoryItem{}).Where("user_id = ?", userID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := postInventoryEntries(tx, userID, id, time.Time{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BackfillLedger posts the ledger of every user who has sales, expenses or stock but no journal entries yet
+func (s *LedgerService) BackfillLedger() error {
+	var userIDs []uint
+	posted := s.DB.Model(&models.JournalEntry{}).Select("user_id")
+	for _, model := range []interface{}{&models.Sale{}, &models.Expense{}, &models.InventoryItem{}} {
+		var ids []uint
+		if err := s.DB.Model(model).Where("user_id NOT IN (?)", posted).Distinct().Pluck("user_id", &ids).Error; err != nil {
+			return err
+		}
+		userIDs = append(userIDs, ids...)
+	}
+
+	done := make(map[uint]bool)
+	for _, userID := range userIDs {
+		if done[userID] {
+			continue
+		}
+		done[userID] = true
+		if err := s.Rebuild(userID); err != nil {
+			return fmt.Errorf("user %d: %w", userID, err)
+		}
+		log.Printf("Posted ledger for user %d", userID)
+	}
+	return nil
+}
+
+// RepostOpeningBalances reposts inventory items whose opening balance was posted to inventory adjustments,
+// before the ledger had an opening balance equity account
+func (s *LedgerService) RepostOpeningBalances() error {
+	type row struct {
+		UserID   uint
+		SourceID uint
+	}
+	var rows []row
+	if err := s.DB.Model(&models.JournalEntry{}).Distinct("journal_entries.user_id", "journal_entries.source_id").
+		Joins("JOIN journal_lines ON journal_lines.journal_entry_id = journal_entries.id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.source_type = ? AND journal_entries.reference = ? AND accounts.code = ?",
+			models.SourceInventoryItem, models.OpeningBalanceReference, models.AccountInventoryAdjustments).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if err := s.DB.Transaction(func(tx *gorm.DB) error {
+			return postInventoryEntries(tx, r.UserID, r.SourceID, time.Time{})
+		}); err != nil {
+			return fmt.Errorf("inventory item %d: %w", r.SourceID, err)
+		}
+		log.Printf("Reposted opening balance for inventory item %d", r.SourceID)
+	}
+	return nil
+}
+
+// RemoveOrphanedEntries deletes journal entries whose sale, expense or inventory item no longer exists,
+// such as records removed with their flock
+func (s *LedgerService) RemoveOrphanedEntries() error {
+	sources := []struct {
+		sourceType string
+		model      interface{}
+	}{
+		{models.SourceSale, &models.Sale{}},
+		{models.SourceExpense, &models.Expense{}},
+		{models.SourceInventoryItem, &models.InventoryItem{}},
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, source := range sources {
+			var ids []uint
+			if err := tx.Model(&models.JournalEntry{}).Where("source_type = ? AND source_id NOT IN (?)",
+				source.sourceType, tx.Model(source.model).Select("id")).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			if err := tx.Where("journal_entry_id IN ?", ids).Delete(&models.JournalLine{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.JournalEntry{}, ids).Error; err != nil {
+				return err
+			}
+			log.Printf("Removed %d journal entries of deleted %s records", len(ids), source.sourceType)
+		}
+		return nil
+	})
+}
+
+// balances totals the user's debits and credits by account up to the end of asOf's day
+func (s *LedgerService) balances(userID uint, asOf time.Time) ([]models.AccountBalance, error) {
+	query := s.DB.Model(&models.JournalLine{}).
+		Select("accounts.id AS account_id, accounts.code, accounts.name, accounts.type, "+
+			"SUM(journal_lines.debit) AS debit, SUM(journal_lines.credit) AS credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.user_id = ? AND journal_entries.date <= ?", userID, endOfDay(asOf))
+
+	balances := []models.AccountBalance{}
+	if err := query.Group("accounts.id, accounts.code, accounts.name, accounts.type").
+		Order("accounts.code ASC").Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		b := &balances[i]
+		b.Debit, b.Credit = roundTo(b.Debit, 2), roundTo(b.Credit, 2)
+		account := models.Account{Code: b.Code, Type: b.Type}
+		if account.DebitNormal() {
+			b.Balance = roundTo(b.Debit-b.Credit, 2)
+		} else {
+			b.Balance = roundTo(b.Credit-b.Debit, 2)
+		}
+	}
+	return balances, nil
+}
+
+// ledgerLine is one side of a posting: a positive amount debits the account, a negative amount credits it
+type ledgerLine struct {
+	code     string
+	flockID  *uint
+	category models.CategoryRef
+	amount   float64
+}
+
+// ledgerPoster posts the journal entries of one source record
+type ledgerPoster struct {
+	tx         *gorm.DB
+	accounts   map[string]uint
+	userID     uint
+	sourceType string
+	sourceID   uint
+}
+
+func newLedgerPoster(tx *gorm.DB, userID uint, sourceType string, sourceID uint) (*ledgerPoster, error) {
+	var accounts []models.Account
+	if err := tx.Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	p := &ledgerPoster{tx: tx, accounts: make(map[string]uint, len(accounts)), userID: userID, sourceType: sourceType, sourceID: sourceID}
+	for _, account := range accounts {
+		p.accounts[account.Code] = account.ID
+	}
+	return p, nil
+}
+
+// clear removes the source's entries dated from from onwards, or all of them when from is zero
+func (p *ledgerPoster) clear(from time.Time) error {
+	entries := p.tx.Model(&models.JournalEntry{}).
+		Where("user_id = ? AND source_type = ? AND source_id = ?", p.userID, p.sourceType, p.sourceID)
+	if !from.IsZero() {
+		entries = entries.Where("date >= ?", from)
+	}
+
+	var ids []uint
+	if err := entries.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := p.tx.Where("journal_entry_id IN ?", ids).Delete(&models.JournalLine{}).Error; err != nil {
+		return err
+	}
+	return p.tx.Delete(&models.JournalEntry{}, ids).Error
+}
+
+// post saves an entry from lines, leaving out zero amounts. Entries must balance.
+func (p *ledgerPoster) post(date time.Time, description, reference string, lines ...ledgerLine) error {
+	entry := models.JournalEntry{
+		UserID:      p.userID,
+		Date:        date,
+		Description: description,
+		SourceType:  p.sourceType,
+		SourceID:    p.sourceID,
+		Reference:   reference,
+	}
+
+	balance := 0.0
+	for _, line := range lines {
+		amount := roundTo(line.amount, 2)
+		if amount == 0 {
+			continue
+		}
+		accountID, ok := p.accounts[line.code]
+		if !ok {
+			return fmt.Errorf("account %s is not in the chart of accounts", line.code)
+		}
+		journalLine := models.JournalLine{
+			AccountID:  accountID,
+			FlockID:    line.flockID,
+			CategoryID: line.category.CategoryID,
+			Category:   line.category.Category,
+		}
+		if amount > 0 {
+			journalLine.Debit = amount
+		} else {
+			journalLine.Credit = -amount
+		}
+		entry.Lines = append(entry.Lines, journalLine)
+		balance += amount
+	}
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+	if math.Abs(balance) >= 0.005 {
+		return fmt.Errorf("%w: %s is off by %.2f", ErrUnbalancedEntry, description, balance)
+	}
+	return p.tx.Create(&entry).Error
+}
+
+// postSaleEntries reposts a sale: the sale on account, then the payments and credit notes against it.
+// A sale marked paid without instalments is settled in cash on its date. Credit notes that leave the sale
+// overpaid are refunded in cash, as worked out by saleRefunds.
+// Nothing is posted for a sale that no longer exists.
+func postSaleEntries(tx *gorm.DB, userID, saleID uint) error {
+	p, err := newLedgerPoster(tx, userID, models.SourceSale, saleID)
+	if err != nil {
+		return err
+	}
+	if err := p.clear(time.Time{}); err != nil {
+		return err
+	}
+
+	var sale models.Sale
+	err = tx.Preload("Payments").Where("id = ? AND user_id = ?", saleID, userID).First(&sale).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var returns []models.SaleReturn
+	if err := tx.Where("sale_id = ?", sale.ID).Order("date ASC, id ASC").Find(&returns).Error; err != nil {
+		return err
+	}
+
+	flockID := &sale.FlockID
+	if err := p.post(sale.Date, "Sale: "+sale.Description, sale.RefNo,
+		ledgerLine{code: models.AccountReceivable, flockID: flockID, amount: sale.Amount},
+		ledgerLine{code: models.AccountSales, flockID: flockID, category: sale.CategoryRef, amount: -sale.Amount},
+	); err != nil {
+		return err
+	}
+
+	settled := sale.Status == models.SaleStatusPaid && len(sale.Payments) == 0
+	if settled {
+		if err := p.post(sale.Date, "Sale settled: "+sale.Description, sale.RefNo,
+			ledgerLine{code: models.AccountCash, amount: sale.Amount},
+			ledgerLine{code: models.AccountReceivable, flockID: flockID, amount: -sale.Amount},
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, payment := range sale.Payments {
+		reference := payment.Reference
+		if reference == "" {
+			reference = sale.RefNo
+		}
+		if err := p.post(payment.Date, fmt.Sprintf("Payment for sale %s via %s", sale.RefNo, payment.Method), reference,
+			ledgerLine{code: models.AccountCash, amount: payment.Amount},
+			ledgerLine{code: models.AccountReceivable, flockID: flockID, amount: -payment.Amount},
+		); err != nil {
+			return err
+		}
+	}
+
+	refunds := saleRefunds(&sale, returns)
+	for i, ret := range returns {
+		if err := p.post(ret.Date, fmt.Sprintf("Credit note for sale %s: %s", sale.RefNo, ret.Reason), ret.CreditNoteNo,
+			ledgerLine{code: models.AccountSalesReturns, flockID: flockID, category: sale.CategoryRef, amount: ret.Amount},
+			ledgerLine{code: models.AccountReceivable, flockID: flockID, amount: -ret.Amount},
+		); err != nil {
+			return err
+		}
+		if err := p.post(ret.Date, fmt.Sprintf("Refund on sale %s", sale.RefNo), ret.CreditNoteNo,
+			ledgerLine{code: models.AccountReceivable, flockID: flockID, amount: refunds[i]},
+			ledgerLine{code: models.AccountCash, amount: -refunds[i]},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postExpenseEntries reposts an expense as paid in cash on its date. Stock bought on a purchase order goes
+// to inventory; other expenses go to operating expenses, split across flocks by their allocations.
+// Nothing is posted for an expense that no longer exists.
+func postExpenseEntries(tx *gorm.DB, userID, expenseID uint) error {
+	p, err := newLedgerPoster(tx, userID, models.SourceExpense, expenseID)
+	if err != nil {
+		return err
+	}
+	if err := p.clear(time.Time{}); err != nil {
+		return err
+	}
+
+	var expense models.Expense
+	err = tx.Preload("Allocations").Where("id = ? AND user_id = ?", expenseID, userID).First(&expense).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	code := models.AccountOperatingExpenses
+	if expense.InventoryItemID != nil {
+		code = models.AccountInventory
+	}
+	var lines []ledgerLine
+	if len(expense.Allocations) > 0 {
+		for _, allocation := range expense.Allocations {
+			flockID := allocation.FlockID
+			lines = append(lines, ledgerLine{code: code, flockID: &flockID, category: expense.CategoryRef, amount: allocation.Amount})
+		}
+	} else {
+		lines = append(lines, ledgerLine{code: code, flockID: expense.FlockID, category: expense.CategoryRef, amount: expense.Amount})
+	}
+	lines = append(lines, ledgerLine{code: models.AccountCash, amount: -expense.Amount})
+
+	return p.post(expense.Date, "Expense: "+expense.Description, "", lines...)
+}
+
+// postInventoryEntries reposts an inventory item's stock movements dated from from onwards, or all of them
+// when from is zero. Movements are valued by replaying the item's history under the user's costing method:
+// purchases are paid in cash, except stock received on a purchase order whose expense posts the purchase;
+// usage and wastage are charged to stock used; opening balances go to opening balance equity; other
+// transfers and corrections go to inventory adjustments.
+// Nothing is posted for an item that no longer exists.
+func postInventoryEntries(tx *gorm.DB, userID, itemID uint, from time.Time) error {
+	p, err := newLedgerPoster(tx, userID, models.SourceInventoryItem, itemID)
+	if err != nil {
+		return err
+	}
+	if err := p.clear(from); err != nil {
+		return err
+	}
+
+	var item models.InventoryItem
+	err = tx.Where("id = ? AND user_id = ?", itemID, userID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var movements []models.InventoryMovement
+	if err := tx.Where("inventory_item_id = ?", itemID).Order("date ASC, id ASC").Find(&movements).Error; err != nil {
+		return err
+	}
+	var orderNos []string
+	if err := tx.Model(&models.PurchaseOrder{}).Where("user_id = ?", userID).Pluck("order_no", &orderNos).Error; err != nil {
+		return err
+	}
+	onOrder := make(map[string]bool, len(orderNos))
+	for _, orderNo := range orderNos {
+		onOrder[orderNo] = true
+	}
+
+	ledger := newCostLedger(NewInventoryService(tx).CostingMethodFor(userID))
+	for _, m := range movements {
+		before := ledger.value()
+		cost := ledger.apply(m)
+		change := ledger.value() - before
+		if m.Date.Before(from) {
+			continue
+		}
+
+		var flockID *uint
+		if m.FlockID != nil {
+			flockID = m.FlockID
+		} else if item.FlockID != 0 {
+			id := item.FlockID
+			flockID = &id
+		}
+		description := fmt.Sprintf("%s %s: %d", item.ItemName, m.Type, m.QuantityDelta)
+		reference := m.Reference
+		if reference == "" {
+			reference = fmt.Sprintf("movement %d", m.ID)
+		}
+
+		var lines []ledgerLine
+		switch m.Type {
+		case models.MovementPurchase:
+			if onOrder[m.Reference] {
+				continue
+			}
+			lines = []ledgerLine{
+				{code: models.AccountInventory, flockID: flockID, amount: change},
+				{code: models.AccountCash, amount: -change},
+			}
+		case models.MovementUsage, models.MovementWastage:
+			lines = []ledgerLine{
+				{code: models.AccountStockUsed, flockID: flockID, amount: cost},
+				{code: models.AccountInventory, flockID: flockID, amount: -cost},
+			}
+		case models.MovementCorrection:
+			code := models.AccountInventoryAdjustments
+			if m.Reference == models.OpeningBalanceReference {
+				code = models.AccountOpeningBalanceEquity
+			}
+			lines = []ledgerLine{
+				{code: models.AccountInventory, flockID: flockID, amount: change},
+				{code: code, flockID: flockID, amount: -change},
+			}
+		default:
+			lines = []ledgerLine{
+				{code: models.AccountInventory, flockID: flockID, amount: change},
+				{code: models.AccountInventoryAdjustments, flockID: flockID, amount: -change},
+			}
+		}
+		if err := p.post(m.Date, description, reference, lines...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunDB returns a session that builds SQL without connecting to a database
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:3306)/test?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, SkipDefaultTransaction: true, DisableAutomaticPing: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestLedgerPosterPost(t *testing.T) {
+	flockID := uint(3)
+	eggs := models.CategoryRef{CategoryID: 1, Category: models.EggSalesCategory}
+
+	tests := []struct {
+		name       string
+		lines      []ledgerLine
+		unbalanced bool
+		wantErr    bool
+	}{
+		{
+			name: "balanced sale",
+			lines: []ledgerLine{
+				{code: models.AccountReceivable, flockID: &flockID, amount: 1500},
+				{code: models.AccountSales, flockID: &flockID, category: eggs, amount: -1500},
+			},
+		},
+		{
+			name: "balanced split across several lines",
+			lines: []ledgerLine{
+				{code: models.AccountOperatingExpenses, amount: 33.33},
+				{code: models.AccountOperatingExpenses, amount: 33.33},
+				{code: models.AccountOperatingExpenses, amount: 33.34},
+				{code: models.AccountCash, amount: -100},
+			},
+		},
+		{
+			name: "sub-cent differences round away",
+			lines: []ledgerLine{
+				{code: models.AccountStockUsed, amount: 10.004},
+				{code: models.AccountInventory, amount: -10.001},
+			},
+		},
+		{
+			name: "all zero posts nothing",
+			lines: []ledgerLine{
+				{code: models.AccountCash, amount: 0},
+				{code: "9999", amount: 0},
+			},
+		},
+		{
+			name: "debits exceed credits",
+			lines: []ledgerLine{
+				{code: models.AccountCash, amount: 100},
+				{code: models.AccountSales, amount: -90},
+			},
+			unbalanced: true,
+			wantErr:    true,
+		},
+		{
+			name: "one-sided entry",
+			lines: []ledgerLine{
+				{code: models.AccountInventory, amount: 250},
+			},
+			unbalanced: true,
+			wantErr:    true,
+		},
+		{
+			name: "unknown account",
+			lines: []ledgerLine{
+				{code: "9999", amount: 100},
+				{code: models.AccountCash, amount: -100},
+			},
+			wantErr: true,
+		},
+	}
+
+	accounts := map[string]uint{}
+	for i, code := range []string{
+		models.AccountCash, models.AccountReceivable, models.AccountInventory, models.AccountOpeningBalanceEquity,
+		models.AccountSales, models.AccountSalesReturns, models.AccountOperatingExpenses,
+		models.AccountStockUsed, models.AccountInventoryAdjustments,
+	} {
+		accounts[code] = uint(i + 1)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ledgerPoster{tx: dryRunDB(t), accounts: accounts, userID: 1, sourceType: models.SourceSale, sourceID: 42}
+			err := p.post(time.Now(), tt.name, "REF-1", tt.lines...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("post error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrUnbalancedEntry) != tt.unbalanced {
+				t.Errorf("post error = %v, want unbalanced %v", err, tt.unbalanced)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"birdseye-backend/pkg/services/email"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LowStockUsageWindowDays is how many days of usage the stock-out projection averages over
+const LowStockUsageWindowDays = 30
+
+// isLowStock reports whether an item has dropped to its reorder level. Items without a reorder level never alert.
+func isLowStock(item *models.InventoryItem) bool {
+	return item.ReorderLevel > 0 && item.Quantity <= item.ReorderLevel
+}
+
+// CheckLowStock alerts the user once when an item drops to its reorder level and re-arms
+// the alert when the item is restocked above it. Call it after the write has committed.
+func (s *InventoryService) CheckLowStock(item *models.InventoryItem) {
+	if item == nil {
+		return
+	}
+
+	low := isLowStock(item)
+	if low == item.LowStockAlerted {
+		return
+	}
+
+	// Flip the flag only if nobody else has, so concurrent writers alert once
+	result := s.DB.Model(&models.InventoryItem{}).
+		Where("id = ? AND low_stock_alerted = ?", item.ID, item.LowStockAlerted).
+		UpdateColumn("low_stock_alerted", low)
+	if result.Error != nil {
+		log.Printf("Error updating low stock flag for item %d: %v", item.ID, result.Error)
+		return
+	}
+	item.LowStockAlerted = low
+	if !low || result.RowsAffected == 0 {
+		return
+	}
+
+	title := fmt.Sprintf("Low stock: %s", item.ItemName)
+	message := fmt.Sprintf("'%s' is down to %d, at or below its reorder level of %d.", item.ItemName, item.Quantity, item.ReorderLevel)
+
+	notification := models.Notification{
+		UserID: item.UserID,
+		Title:  title,
+		Body:   message,
+		Type:   "warning",
+		URL:    "/inventory",
+	}
+	if err := NewNotificationService(s.DB).CreateNotification(&notification); err != nil {
+		log.Printf("Error saving low stock notification for item %d: %v", item.ID, err)
+	}
+
+	broadcast.SendInventoryUpdate(item.UserID, "inventory_low_stock", *item)
+	broadcast.SendNotification(item.UserID, title, message, "/inventory")
+
+	user, err := GetUserByID(item.UserID)
+	if err != nil {
+		log.Printf("Error fetching user for low stock alert on item %d: %v", item.ID, err)
+		return
+	}
+	if user.LowStockEmails {
+		if err := email.SendLowStockEmail(user.Email, user.Username, item); err != nil {
+			log.Printf("Error sending low stock email for item %d: %v", item.ID, err)
+		}
+	}
+}
+
+// CheckAllLowStock raises or re-arms low stock alerts for every item whose state has changed
+func (s *InventoryService) CheckAllLowStock() error {
+	var items []models.InventoryItem
+	if err := s.DB.Where("reorder_level > 0 AND ((quantity <= reorder_level AND low_stock_alerted = ?) OR (quantity > reorder_level AND low_stock_alerted = ?))", false, true).
+		Find(&items).Error; err != nil {
+		return err
+	}
+
+	for i := range items {
+		s.CheckLowStock(&items[i])
+	}
+	return nil
+}
+
+// GetLowStockItems lists a user's items at or below their reorder level with projected days until stock-out,
+// based on average daily usage and wastage over the last LowStockUsageWindowDays days
+func (s *InventoryService) GetLowStockItems(userID uint) ([]models.LowStockItem, error) {
+	var items []models.InventoryItem
+	if err := s.DB.Where("user_id = ? AND reorder_level > 0 AND quantity <= reorder_level", userID).
+		Order("item_name ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	lowStock := make([]models.LowStockItem, 0, len(items))
+	since := time.Now().AddDate(0, 0, -LowStockUsageWindowDays)
+	for _, item := range items {
+		var used int64
+		if err := s.DB.Model(&models.InventoryMovement{}).
+			Where("inventory_item_id = ? AND type IN ? AND date >= ?", item.ID,
+				[]string{models.MovementUsage, models.MovementWastage}, since).
+			Select("COALESCE(-SUM(quantity_delta), 0)").Scan(&used).Error; err != nil {
+			return nil, err
+		}
+
+		entry := models.LowStockItem{InventoryItem: item}
+		if used > 0 {
+			entry.AvgDailyUsage = roundTo(float64(used)/LowStockUsageWindowDays, 2)
+			days := roundTo(float64(item.Quantity)/entry.AvgDailyUsage, 1)
+			entry.DaysToStockout = &days
+		}
+		lowStock = append(lowStock, entry)
+	}
+
+	return lowStock, nil
+}
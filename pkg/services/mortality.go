@@ -0,0 +1,287 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MortalityService manages per-event mortality records and keeps Flock.BirdCount in sync
+type MortalityService struct {
+	DB *gorm.DB
+}
+
+// NewMortalityService initializes a new service instance
+func NewMortalityService(db *gorm.DB) *MortalityService {
+	return &MortalityService{DB: db}
+}
+
+// GetMortalityEvents retrieves all mortality events for a flock, oldest first
+func (s *MortalityService) GetMortalityEvents(flockID, userID uint) ([]models.MortalityEvent, error) {
+	var events []models.MortalityEvent
+	err := s.DB.Where("flock_id = ? AND user_id = ?", flockID, userID).
+		Order("date ASC, id ASC").Find(&events).Error
+	return events, err
+}
+
+// AddMortalityEvent records dead birds and decrements the flock's bird count in the same transaction
+func (s *MortalityService) AddMortalityEvent(event *models.MortalityEvent) error {
+	if err := validateMortalityEvent(event); err != nil {
+		return err
+	}
+
+	var flock models.Flock
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", event.FlockID, event.UserID).First(&flock).Error; err != nil {
+			return errors.New("flock not found")
+		}
+		if event.Count > flock.BirdCount {
+			return fmt.Errorf("cannot record %d deaths, flock only has %d birds", event.Count, flock.BirdCount)
+		}
+
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+
+		flock.BirdCount -= event.Count
+		return s.syncFlockMortality(tx, &flock)
+	})
+	if err != nil {
+		return err
+	}
+
+	broadcast.SendFlockUpdate(flock.UserID, "flock_updated", flock)
+	broadcast.SendNotification(flock.UserID, "Mortality Recorded",
+		fmt.Sprintf("%d bird(s) lost in flock '%s' (%s).", event.Count, flock.Name, event.Cause),
+		fmt.Sprintf("/flocks/%d", flock.ID))
+	return nil
+}
+
+// UpdateMortalityEvent edits an event and applies the difference in count to the flock
+func (s *MortalityService) UpdateMortalityEvent(eventID, userID uint, updated *models.MortalityEvent) (*models.MortalityEvent, error) {
+	if err := validateMortalityEvent(updated); err != nil {
+		return nil, err
+	}
+
+	var flock models.Flock
+	var event models.MortalityEvent
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", eventID, userID).First(&event).Error; err != nil {
+			return errors.New("mortality event not found")
+		}
+		if err := tx.Where("id = ? AND user_id = ?", event.FlockID, userID).First(&flock).Error; err != nil {
+			return errors.New("flock not found")
+		}
+
+		delta := updated.Count - event.Count
+		if delta > flock.BirdCount {
+			return fmt.Errorf("cannot record %d more deaths, flock only has %d birds", delta, flock.BirdCount)
+		}
+
+		event.Date = updated.Date
+		event.Count = updated.Count
+		event.Cause = updated.Cause
+		event.Notes = updated.Notes
+		if err := tx.Save(&event).Error; err != nil {
+			return err
+		}
+
+		flock.BirdCount -= delta
+		return s.syncFlockMortality(tx, &flock)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	broadcast.SendFlockUpdate(flock.UserID, "flock_updated", flock)
+	return &event, nil
+}
+
+// DeleteMortalityEvent removes an event and returns its birds to the flock's bird count
+func (s *MortalityService) DeleteMortalityEvent(eventID, userID uint) error {
+	var flock models.Flock
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var event models.MortalityEvent
+		if err := tx.Where("id = ? AND user_id = ?", eventID, userID).First(&event).Error; err != nil {
+			return errors.New("mortality event not found")
+		}
+		if err := tx.Where("id = ? AND user_id = ?", event.FlockID, userID).First(&flock).Error; err != nil {
+			return errors.New("flock not found")
+		}
+
+		if err := tx.Delete(&event).Error; err != nil {
+			return err
+		}
+
+		flock.BirdCount += event.Count
+		return s.syncFlockMortality(tx, &flock)
+	})
+	if err != nil {
+		return err
+	}
+
+	broadcast.SendFlockUpdate(flock.UserID, "flock_updated", flock)
+	return nil
+}
+
+// GetMortalityCurve returns daily and cumulative mortality for every day between start and end.
+// Cumulative figures include deaths recorded before start.
+func (s *MortalityService) GetMortalityCurve(flock *models.Flock, start, end time.Time) ([]models.MortalityPoint, error) {
+	var events []models.MortalityEvent
+	if err := s.DB.Where("flock_id = ? AND user_id = ?", flock.ID, flock.UserID).
+		Order("date ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	start, end = truncateDay(start), truncateDay(end)
+	deathsByDay := make(map[string]int)
+	cumulative := 0
+	for _, e := range events {
+		if truncateDay(e.Date).Before(start) {
+			cumulative += e.Count
+			continue
+		}
+		deathsByDay[e.Date.Format("2006-01-02")] += e.Count
+	}
+
+	history, err := s.BirdCountHistory(flock, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []models.MortalityPoint
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		deaths := deathsByDay[key]
+		cumulative += deaths
+
+		// Birds alive at the start of the day are those alive at its end plus that day's deaths
+		aliveAtStart := history[key] + deaths
+		point := models.MortalityPoint{
+			Date:             key,
+			Deaths:           deaths,
+			CumulativeDeaths: cumulative,
+		}
+		if aliveAtStart > 0 {
+			point.DailyRate = roundTo(float64(deaths)/float64(aliveAtStart)*100, 3)
+		}
+		if flock.InitialBirdCount > 0 {
+			point.CumulativeRate = roundTo(float64(cumulative)/float64(flock.InitialBirdCount)*100, 3)
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// BirdCountHistory returns the number of birds alive at the end of each day between start and end,
+// keyed by YYYY-MM-DD. It is reconstructed backwards from the current BirdCount using mortality events.
+func (s *MortalityService) BirdCountHistory(flock *models.Flock, start, end time.Time) (map[string]int, error) {
+	start, end = truncateDay(start), truncateDay(end)
+
+	var events []models.MortalityEvent
+	if err := s.DB.Where("flock_id = ? AND date > ?", flock.ID, start).
+		Order("date DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	deathsByDay := make(map[string]int)
+	deathsAfterEnd := 0
+	for _, e := range events {
+		if truncateDay(e.Date).After(end) {
+			deathsAfterEnd += e.Count
+			continue
+		}
+		deathsByDay[e.Date.Format("2006-01-02")] += e.Count
+	}
+
+	history := make(map[string]int)
+	alive := flock.BirdCount + deathsAfterEnd
+	for day := end; !day.Before(start); day = day.AddDate(0, 0, -1) {
+		key := day.Format("2006-01-02")
+		history[key] = alive
+		alive += deathsByDay[key]
+	}
+
+	return history, nil
+}
+
+// syncFlockMortality recomputes the mortality rate and curve for a flock and stores them
+// together with its bird count. Hooks are skipped; callers broadcast once the transaction commits.
+func (s *MortalityService) syncFlockMortality(tx *gorm.DB, flock *models.Flock) error {
+	if flock.InitialBirdCount > 0 {
+		totalDeaths := flock.InitialBirdCount - flock.BirdCount
+		flock.MortalityRate = (float64(totalDeaths) / float64(flock.InitialBirdCount)) * 100
+	} else {
+		flock.MortalityRate = 0
+	}
+
+	curveStart := flock.CreatedAt
+	var first models.MortalityEvent
+	if err := tx.Where("flock_id = ?", flock.ID).Order("date ASC").First(&first).Error; err == nil && first.Date.Before(curveStart) {
+		curveStart = first.Date
+	}
+
+	points, err := (&MortalityService{DB: tx}).GetMortalityCurve(flock, curveStart, time.Now())
+	if err != nil {
+		return err
+	}
+	curve, err := json.Marshal(points)
+	if err != nil {
+		return err
+	}
+	flock.MortalityRateData = curve
+
+	if err := tx.Model(flock).UpdateColumns(map[string]interface{}{
+		"bird_count":          flock.BirdCount,
+		"mortality_rate":      flock.MortalityRate,
+		"mortality_rate_data": flock.MortalityRateData,
+	}).Error; err != nil {
+		log.Printf("❌ Error syncing mortality for flock ID %d: %v\n", flock.ID, err)
+		return err
+	}
+	return nil
+}
+
+func validateMortalityEvent(event *models.MortalityEvent) error {
+	if event.Count <= 0 {
+		return errors.New("count must be greater than zero")
+	}
+	if event.Cause == "" {
+		event.Cause = models.MortalityCauseUnknown
+	}
+	if !models.IsValidMortalityCause(event.Cause) {
+		return fmt.Errorf("invalid cause '%s'", event.Cause)
+	}
+	if event.Date.IsZero() {
+		event.Date = time.Now()
+	}
+	event.Date = truncateDay(event.Date)
+	return nil
+}
+
+// truncateDay strips the time of day, keeping the location
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days from a to b, ignoring time of day and time zone
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	from := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	to := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceListService manages price lists and fills prices on new sales and egg production records
+type PriceListService struct {
+	DB *gorm.DB
+}
+
+// NewPriceListService initializes a new service instance
+func NewPriceListService(db *gorm.DB) *PriceListService {
+	return &PriceListService{DB: db}
+}
+
+// GetItems returns a user's price list, optionally for one product, newest prices first
+func (s *PriceListService) GetItems(userID uint, product string) ([]models.PriceListItem, error) {
+	query := s.DB.Preload("Customer").Where("user_id = ?", userID)
+	if product != "" {
+		query = query.Where("LOWER(product) = ?", strings.ToLower(strings.TrimSpace(product)))
+	}
+
+	var items []models.PriceListItem
+	err := query.Order("product ASC, grade ASC, customer_id ASC, effective_from DESC").Find(&items).Error
+	return items, err
+}
+
+// GetItem returns a single price list item owned by the user
+func (s *PriceListService) GetItem(id, userID uint) (*models.PriceListItem, error) {
+	var item models.PriceListItem
+	if err := s.DB.Preload("Customer").Where("id = ? AND user_id = ?", id, userID).First(&item).Error; err != nil {
+		return nil, errors.New("price list item not found")
+	}
+	return &item, nil
+}
+
+// AddItem adds a price. Prices for the same product, grade and customer may not overlap in time.
+func (s *PriceListService) AddItem(item *models.PriceListItem) error {
+	if err := s.validateItem(item); err != nil {
+		return err
+	}
+	return s.DB.Omit("Customer").Create(item).Error
+}
+
+// UpdateItem saves changes to a price owned by the user
+func (s *PriceListService) UpdateItem(item *models.PriceListItem) error {
+	existing, err := s.GetItem(item.ID, item.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateItem(item); err != nil {
+		return err
+	}
+	item.CreatedAt = existing.CreatedAt
+	return s.DB.Omit("Customer").Save(item).Error
+}
+
+// DeleteItem removes a price
+func (s *PriceListService) DeleteItem(id, userID uint) error {
+	result := s.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.PriceListItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("price list item not found")
+	}
+	return nil
+}
+
+// Resolve finds the price of a product, and grade if given, on a date for a customer.
+// It returns nil when the price list has no price that applies.
+func (s *PriceListService) Resolve(userID uint, customerID *uint, product, grade string, on time.Time) (*models.ResolvedPrice, error) {
+	product = strings.TrimSpace(product)
+	if product == "" {
+		return nil, nil
+	}
+	day := on.Format("2006-01-02")
+
+	query := s.DB.Where("user_id = ? AND LOWER(product) = ?", userID, strings.ToLower(product)).
+		Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", day, day)
+	if grade != "" {
+		query = query.Where("grade IN ?", []string{"", grade})
+	} else {
+		query = query.Where("grade = ''")
+	}
+	if customerID != nil && *customerID != 0 {
+		query = query.Where("customer_id IS NULL OR customer_id = ?", *customerID)
+	} else {
+		query = query.Where("customer_id IS NULL")
+	}
+
+	var item models.PriceListItem
+	err := query.Order("customer_id IS NULL ASC, grade = '' ASC, effective_from DESC, id DESC").First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ResolvedPrice{
+		Product:    product,
+		Grade:      grade,
+		CustomerID: item.CustomerID,
+		Date:       day,
+		UnitPrice:  item.UnitPrice,
+		ItemID:     item.ID,
+	}, nil
+}
+
+// validateItem checks the item's customer belongs to its user and that it does not overlap another price
+func (s *PriceListService) validateItem(item *models.PriceListItem) error {
+	item.Product = strings.TrimSpace(item.Product)
+	if item.CustomerID != nil && *item.CustomerID == 0 {
+		item.CustomerID = nil
+	}
+	if item.CustomerID != nil {
+		var count int64
+		if err := s.DB.Model(&models.Customer{}).Where("id = ? AND user_id = ?", *item.CustomerID, item.UserID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("customer not found")
+		}
+	}
+
+	query := s.DB.Model(&models.PriceListItem{}).
+		Where("user_id = ? AND id <> ? AND LOWER(product) = ? AND grade = ?",
+			item.UserID, item.ID, strings.ToLower(item.Product), item.Grade).
+		Where("effective_to IS NULL OR effective_to >= ?", item.EffectiveFrom.Format("2006-01-02"))
+	if item.EffectiveTo != nil {
+		query = query.Where("effective_from <= ?", item.EffectiveTo.Format("2006-01-02"))
+	}
+	if item.CustomerID != nil {
+		query = query.Where("customer_id = ?", *item.CustomerID)
+	} else {
+		query = query.Where("customer_id IS NULL")
+	}
+
+	var overlapping models.PriceListItem
+	err := query.First(&overlapping).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("price overlaps the price effective from %s; end that price first",
+		overlapping.EffectiveFrom.Format("2006-01-02"))
+}
+
+// fillSalePrices prices a new sale, or its grade lines, from the price list where no price was given.
+// Egg sales fall back to the EggPriceProduct price when their own product has none.
+func fillSalePrices(tx *gorm.DB, sale *models.Sale) error {
+	eggs, err := isEggSale(tx, sale)
+	if err != nil {
+		return err
+	}
+	prices := NewPriceListService(tx)
+	resolve := func(grade string) (float64, bool, error) {
+		price, err := prices.Resolve(sale.UserID, sale.CustomerID, sale.Product, grade, sale.Date)
+		if err == nil && price == nil && eggs {
+			price, err = prices.Resolve(sale.UserID, sale.CustomerID, models.EggPriceProduct, grade, sale.Date)
+		}
+		if err != nil || price == nil {
+			return 0, false, err
+		}
+		return price.UnitPrice, true, nil
+	}
+
+	if len(sale.Grades) > 0 {
+		for i := range sale.Grades {
+			if sale.Grades[i].UnitPrice != 0 {
+				continue
+			}
+			price, ok, err := resolve(sale.Grades[i].Grade)
+			if err != nil {
+				return err
+			}
+			if ok {
+				sale.Grades[i].UnitPrice = price
+			}
+		}
+		return nil
+	}
+
+	if sale.UnitPrice != 0 {
+		return nil
+	}
+	price, ok, err := resolve("")
+	if err != nil || !ok {
+		return err
+	}
+	sale.UnitPrice = price
+	if sale.Amount == 0 {
+		sale.Amount = float64(sale.Quantity) * price
+	}
+	return nil
+}
+
+// fillProductionPrices prices a new egg production record, or its grade lines, from the EggPriceProduct
+// price where no price was given
+func fillProductionPrices(tx *gorm.DB, record *models.EggProduction) error {
+	prices := NewPriceListService(tx)
+	if len(record.Grades) > 0 {
+		for i := range record.Grades {
+			if record.Grades[i].PricePerUnit != 0 {
+				continue
+			}
+			price, err := prices.Resolve(record.UserID, nil, models.EggPriceProduct, record.Grades[i].Grade, record.DateProduced)
+			if err != nil {
+				return err
+			}
+			if price != nil {
+				record.Grades[i].PricePerUnit = price.UnitPrice
+			}
+		}
+		return nil
+	}
+
+	if record.PricePerUnit != 0 {
+		return nil
+	}
+	price, err := prices.Resolve(record.UserID, nil, models.EggPriceProduct, "", record.DateProduced)
+	if err != nil || price == nil {
+		return err
+	}
+	record.PricePerUnit = price.UnitPrice
+	return nil
+}
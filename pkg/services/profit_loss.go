@@ -0,0 +1,217 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProfitAndLossService builds comparative profit and loss statements from the general ledger, so they
+// agree with the ledger's own profit and loss for the same period
+type ProfitAndLossService struct {
+	DB *gorm.DB
+}
+
+// NewProfitAndLossService initializes a new service instance
+func NewProfitAndLossService(db *gorm.DB) *ProfitAndLossService {
+	return &ProfitAndLossService{DB: db}
+}
+
+// categoryKey identifies a statement line: a category, or by name a line without one such as stock used
+type categoryKey struct {
+	ID   uint
+	Name string
+}
+
+// categoryAmounts holds a period's amounts by category, with the category names seen
+type categoryAmounts struct {
+	Totals map[categoryKey]float64
+	Names  map[categoryKey]string
+}
+
+func newCategoryAmounts() categoryAmounts {
+	return categoryAmounts{Totals: map[categoryKey]float64{}, Names: map[categoryKey]string{}}
+}
+
+func (a categoryAmounts) add(id uint, name string, amount float64) {
+	key := categoryKey{ID: id}
+	if id == 0 {
+		key.Name = name
+	}
+	a.Totals[key] += amount
+	if name != "" {
+		a.Names[key] = name
+	}
+}
+
+func (a categoryAmounts) sum() float64 {
+	total := 0.0
+	for _, amount := range a.Totals {
+		total += amount
+	}
+	return total
+}
+
+// GetProfitAndLoss returns the user's revenue by sale category and costs by expense category between
+// start and end, for one flock or the whole farm when flockID is 0. It compares them with the period of
+// the same length just before, or the same number of whole months when the period is whole months,
+// and with the same dates a year earlier.
+//
+// Amounts are read from the journal. A flock's costs include its share of farm-level expenses; the farm's
+// include those expenses in full. Stock purchases are costed as the stock is used, on the StockUsedLine,
+// and inventory corrections appear on their own line.
+func (s *ProfitAndLossService) GetProfitAndLoss(userID, flockID uint, start, end time.Time) (*models.ProfitAndLoss, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	if flockID != 0 {
+		var count int64
+		if err := s.DB.Model(&models.Flock{}).Where("id = ? AND user_id = ?", flockID, userID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrFlockNotFound
+		}
+	}
+
+	prevStart, prevEnd := previousPeriod(start, end)
+	lastYearStart, lastYearEnd := sameDayLastYear(start), sameDayLastYear(end)
+
+	statement := &models.ProfitAndLoss{}
+	if flockID != 0 {
+		statement.FlockID = &flockID
+	}
+
+	periods := []struct {
+		start, end time.Time
+		summary    *models.ProfitAndLossPeriod
+		revenue    categoryAmounts
+		costs      categoryAmounts
+	}{
+		{start: start, end: end, summary: &statement.Current},
+		{start: prevStart, end: prevEnd, summary: &statement.PreviousPeriod},
+		{start: lastYearStart, end: lastYearEnd, summary: &statement.LastYear},
+	}
+	for i := range periods {
+		p := &periods[i]
+		var err error
+		if p.revenue, p.costs, err = s.amounts(userID, flockID, p.start, p.end); err != nil {
+			return nil, err
+		}
+		revenue, costs := roundTo(p.revenue.sum(), 2), roundTo(p.costs.sum(), 2)
+		*p.summary = models.ProfitAndLossPeriod{
+			Start:     p.start.Format("2006-01-02"),
+			End:       p.end.Format("2006-01-02"),
+			Revenue:   revenue,
+			Costs:     costs,
+			NetProfit: roundTo(revenue-costs, 2),
+		}
+	}
+
+	statement.Revenue = profitAndLossLines(periods[0].revenue, periods[1].revenue, periods[2].revenue)
+	statement.Costs = profitAndLossLines(periods[0].costs, periods[1].costs, periods[2].costs)
+	return statement, nil
+}
+
+// amounts reads the revenue and costs posted in the period from the ledger by category. Lines without a
+// category, such as stock used, are named after their account.
+func (s *ProfitAndLossService) amounts(userID, flockID uint, start, end time.Time) (categoryAmounts, categoryAmounts, error) {
+	revenue, costs := newCategoryAmounts(), newCategoryAmounts()
+	statement, err := NewLedgerService(s.DB).GetProfitAndLoss(userID, flockID, start, end)
+	if err != nil {
+		return revenue, costs, err
+	}
+	for _, line := range statement.Revenue {
+		revenue.add(line.CategoryID, ledgerLineName(line), line.Amount)
+	}
+	for _, line := range statement.Expenses {
+		costs.add(line.CategoryID, ledgerLineName(line), line.Amount)
+	}
+	return revenue, costs, nil
+}
+
+func ledgerLineName(line models.LedgerProfitAndLossLine) string {
+	if line.CategoryID == 0 {
+		return line.Account
+	}
+	return line.Category
+}
+
+// profitAndLossLines lines up each category's amounts across the three periods, largest current amount first
+func profitAndLossLines(current, previous, lastYear categoryAmounts) []models.ProfitAndLossLine {
+	keys := make(map[categoryKey]bool)
+	for _, amounts := range []categoryAmounts{current, previous, lastYear} {
+		for key := range amounts.Totals {
+			keys[key] = true
+		}
+	}
+
+	lines := make([]models.ProfitAndLossLine, 0, len(keys))
+	for key := range keys {
+		name := current.Names[key]
+		if name == "" {
+			name = previous.Names[key]
+		}
+		if name == "" {
+			name = lastYear.Names[key]
+		}
+		line := models.ProfitAndLossLine{
+			CategoryID:     key.ID,
+			Category:       name,
+			Amount:         roundTo(current.Totals[key], 2),
+			PreviousPeriod: roundTo(previous.Totals[key], 2),
+			LastYear:       roundTo(lastYear.Totals[key], 2),
+		}
+		line.PreviousChange = percentChange(line.Amount, line.PreviousPeriod)
+		line.LastYearChange = percentChange(line.Amount, line.LastYear)
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Amount != lines[j].Amount {
+			return lines[i].Amount > lines[j].Amount
+		}
+		if lines[i].CategoryID != lines[j].CategoryID {
+			return lines[i].CategoryID < lines[j].CategoryID
+		}
+		return lines[i].Category < lines[j].Category
+	})
+	return lines
+}
+
+// percentChange returns the change from base to amount as a percentage of base, or nil when base is zero
+func percentChange(amount, base float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+	change := roundTo((amount-base)/base*100, 2)
+	if base < 0 {
+		change = -change
+	}
+	return &change
+}
+
+// previousPeriod returns the period of the same length ending the day before start. A period of whole
+// months is compared with the same number of whole months before it.
+func previousPeriod(start, end time.Time) (time.Time, time.Time) {
+	prevEnd := start.AddDate(0, 0, -1)
+	if start.Day() == 1 && end.AddDate(0, 0, 1).Day() == 1 {
+		months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()) + 1
+		return start.AddDate(0, -months, 0), prevEnd
+	}
+	return prevEnd.AddDate(0, 0, -daysBetween(start, end)), prevEnd
+}
+
+// sameDayLastYear returns the date a year earlier, moving 29 February and other month ends to the
+// last day of the month
+func sameDayLastYear(t time.Time) time.Time {
+	year, month := t.Year()-1, t.Month()
+	day := t.Day()
+	if last := time.Date(year, month+1, 0, 0, 0, 0, 0, t.Location()).Day(); day > last || t.AddDate(0, 0, 1).Day() == 1 {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+func parseTestDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		wantStart  string
+		wantEnd    string
+	}{
+		{"single day", "2026-03-01", "2026-03-01", "2026-02-28", "2026-02-28"},
+		{"ten days", "2026-03-10", "2026-03-19", "2026-02-28", "2026-03-09"},
+		{"whole month into a shorter one", "2026-03-01", "2026-03-31", "2026-02-01", "2026-02-28"},
+		{"whole month into a longer one", "2026-02-01", "2026-02-28", "2026-01-01", "2026-01-31"},
+		{"quarter", "2026-04-01", "2026-06-30", "2026-01-01", "2026-03-31"},
+		{"year", "2026-01-01", "2026-12-31", "2025-01-01", "2025-12-31"},
+		{"months across the year end", "2026-12-01", "2027-01-31", "2026-10-01", "2026-11-30"},
+		{"part months are compared by days", "2026-03-01", "2026-03-15", "2026-02-14", "2026-02-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := previousPeriod(parseTestDate(t, tt.start), parseTestDate(t, tt.end))
+			if got := start.Format("2006-01-02"); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := end.Format("2006-01-02"); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSameDayLastYear(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2026-10-16", "2025-10-16"},
+		{"2026-01-01", "2025-01-01"},
+		{"2028-02-29", "2027-02-28"},
+		{"2029-02-28", "2028-02-29"},
+		{"2027-02-28", "2026-02-28"},
+		{"2027-02-27", "2026-02-27"},
+		{"2026-04-30", "2025-04-30"},
+		{"2026-03-31", "2025-03-31"},
+	}
+
+	for _, tt := range tests {
+		if got := sameDayLastYear(parseTestDate(t, tt.date)).Format("2006-01-02"); got != tt.want {
+			t.Errorf("sameDayLastYear(%s) = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestProfitAndLossLinesFromLedger(t *testing.T) {
+	ledger := []models.LedgerProfitAndLossLine{
+		{Code: models.AccountSales, Account: "Sales", CategoryID: 1, Category: models.EggSalesCategory, Amount: 1000},
+		{Code: models.AccountSalesReturns, Account: "Sales Returns", CategoryID: 1, Category: models.EggSalesCategory, Amount: -100},
+		{Code: models.AccountOperatingExpenses, Account: "Operating Expenses", CategoryID: 7, Category: "Labour", Amount: 400},
+		{Code: models.AccountStockUsed, Account: models.StockUsedLine, Amount: 300},
+		{Code: models.AccountInventoryAdjustments, Account: "Inventory Adjustments", Amount: 50},
+	}
+
+	amounts := newCategoryAmounts()
+	ledgerTotal := 0.0
+	for _, line := range ledger {
+		amounts.add(line.CategoryID, ledgerLineName(line), line.Amount)
+		ledgerTotal += line.Amount
+	}
+	lines := profitAndLossLines(amounts, newCategoryAmounts(), newCategoryAmounts())
+
+	want := []struct {
+		categoryID uint
+		category   string
+		amount     float64
+	}{
+		{1, models.EggSalesCategory, 900},
+		{7, "Labour", 400},
+		{0, models.StockUsedLine, 300},
+		{0, "Inventory Adjustments", 50},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(lines), len(want), lines)
+	}
+	statementTotal := 0.0
+	for i, w := range want {
+		got := lines[i]
+		if got.CategoryID != w.categoryID || got.Category != w.category || got.Amount != w.amount {
+			t.Errorf("line %d = %d %q %.2f, want %d %q %.2f", i, got.CategoryID, got.Category, got.Amount,
+				w.categoryID, w.category, w.amount)
+		}
+		statementTotal += got.Amount
+	}
+	if statementTotal != ledgerTotal {
+		t.Errorf("statement total = %.2f, ledger total = %.2f", statementTotal, ledgerTotal)
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurchaseOrderService manages purchase orders and posts received orders to inventory and expenses
+type PurchaseOrderService struct {
+	DB *gorm.DB
+}
+
+// NewPurchaseOrderService initializes a new service instance
+func NewPurchaseOrderService(db *gorm.DB) *PurchaseOrderService {
+	return &PurchaseOrderService{DB: db}
+}
+
+// GetPurchaseOrders returns a user's purchase orders, newest first, optionally filtered by status and supplier
+func (s *PurchaseOrderService) GetPurchaseOrders(userID uint, status string, supplierID uint) ([]models.PurchaseOrder, error) {
+	query := s.DB.Preload("Lines").Preload("Supplier").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID != 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+
+	var orders []models.PurchaseOrder
+	err := query.Order("order_date DESC, id DESC").Find(&orders).Error
+	return orders, err
+}
+
+// GetPurchaseOrder returns a single purchase order owned by the user
+func (s *PurchaseOrderService) GetPurchaseOrder(id, userID uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	if err := s.DB.Preload("Lines").Preload("Supplier").
+		Where("id = ? AND user_id = ?", id, userID).First(&order).Error; err != nil {
+		return nil, errors.New("purchase order not found")
+	}
+	return &order, nil
+}
+
+// CreatePurchaseOrder saves a new draft or ordered purchase order
+func (s *PurchaseOrderService) CreatePurchaseOrder(order *models.PurchaseOrder) error {
+	if order.Status != "" && order.Status != models.PurchaseOrderDraft && order.Status != models.PurchaseOrderOrdered {
+		return errors.New("new purchase orders must be draft or ordered")
+	}
+	if err := s.validate(order); err != nil {
+		return err
+	}
+	if order.OrderDate.IsZero() {
+		order.OrderDate = time.Now()
+	}
+	order.ReceivedDate = nil
+	return s.DB.Create(order).Error
+}
+
+// UpdatePurchaseOrder replaces the details and lines of a draft or ordered purchase order
+func (s *PurchaseOrderService) UpdatePurchaseOrder(order *models.PurchaseOrder) error {
+	existing, err := s.GetPurchaseOrder(order.ID, order.UserID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != models.PurchaseOrderDraft && existing.Status != models.PurchaseOrderOrdered {
+		return fmt.Errorf("cannot edit a %s purchase order", existing.Status)
+	}
+	if err := s.validate(order); err != nil {
+		return err
+	}
+
+	order.OrderNo = existing.OrderNo
+	order.Status = existing.Status
+	order.ReceivedDate = nil
+	order.CreatedAt = existing.CreatedAt
+	if order.OrderDate.IsZero() {
+		order.OrderDate = existing.OrderDate
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range order.Lines {
+			order.Lines[i].ID = 0
+			order.Lines[i].PurchaseOrderID = order.ID
+		}
+		return tx.Omit("Supplier").Save(order).Error
+	})
+}
+
+// UpdateStatus moves a purchase order to ordered or cancelled. Use ReceivePurchaseOrder to receive it.
+func (s *PurchaseOrderService) UpdateStatus(id, userID uint, status string) (*models.PurchaseOrder, error) {
+	order, err := s.GetPurchaseOrder(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case status == models.PurchaseOrderOrdered && order.Status == models.PurchaseOrderDraft:
+	case status == models.PurchaseOrderCancelled &&
+		(order.Status == models.PurchaseOrderDraft || order.Status == models.PurchaseOrderOrdered):
+	default:
+		return nil, fmt.Errorf("cannot change a %s purchase order to %s", order.Status, status)
+	}
+
+	if err := s.DB.Model(order).UpdateColumn("status", status).Error; err != nil {
+		return nil, err
+	}
+	order.Status = status
+	return order, nil
+}
+
+// DeletePurchaseOrder removes a purchase order that has not been received
+func (s *PurchaseOrderService) DeletePurchaseOrder(id, userID uint) error {
+	order, err := s.GetPurchaseOrder(id, userID)
+	if err != nil {
+		return err
+	}
+	if order.Status == models.PurchaseOrderReceived {
+		return errors.New("received purchase orders cannot be deleted")
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("purchase_order_id = ?", id).Delete(&models.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.PurchaseOrder{}, id).Error
+	})
+}
+
+// ReceivePurchaseOrder marks an order received. In one transaction it claims the order, then each line posts a purchase
+// movement to its inventory item, into a lot when the line has a batch number, and an expense
+// against the item's flock, linked to the supplier and order.
+func (s *PurchaseOrderService) ReceivePurchaseOrder(id, userID uint, receivedDate time.Time) (*models.PurchaseOrder, error) {
+	if receivedDate.IsZero() {
+		receivedDate = time.Now()
+	}
+
+	var items []*models.InventoryItem
+	var expenses []models.Expense
+	var order models.PurchaseOrder
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Lines").Preload("Supplier").
+			Where("id = ? AND user_id = ?", id, userID).First(&order).Error; err != nil {
+			return errors.New("purchase order not found")
+		}
+		if order.Status != models.PurchaseOrderDraft && order.Status != models.PurchaseOrderOrdered {
+			return fmt.Errorf("cannot receive a %s purchase order", order.Status)
+		}
+
+		// Claim the order only if nobody else has, so concurrent receives post it once
+		result := tx.Model(&models.PurchaseOrder{}).
+			Where("id = ? AND status IN ?", order.ID, []string{models.PurchaseOrderDraft, models.PurchaseOrderOrdered}).
+			UpdateColumns(map[string]interface{}{
+				"status":        models.PurchaseOrderReceived,
+				"received_date": receivedDate,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("purchase order has already been received")
+		}
+		order.Status = models.PurchaseOrderReceived
+		order.ReceivedDate = &receivedDate
+
+		inventory := NewInventoryService(tx)
+		for _, line := range order.Lines {
+			var item *models.InventoryItem
+			var err error
+			if line.BatchNumber != "" && line.ExpiryDate != nil {
+				item, err = inventory.AddLot(&models.InventoryLot{
+					UserID:          userID,
+					InventoryItemID: line.InventoryItemID,
+					BatchNumber:     line.BatchNumber,
+					ExpiryDate:      *line.ExpiryDate,
+					ReceivedDate:    receivedDate,
+					UnitCost:        line.UnitCost,
+				}, line.Quantity, order.OrderNo)
+			} else {
+				item, err = inventory.RecordMovement(&models.InventoryMovement{
+					UserID:          userID,
+					InventoryItemID: line.InventoryItemID,
+					Type:            models.MovementPurchase,
+					QuantityDelta:   line.Quantity,
+					UnitCost:        line.UnitCost,
+					Reference:       order.OrderNo,
+					Date:            receivedDate,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+
+			category := line.ExpenseCategory
+			if category == "" {
+				category = models.PurchaseExpenseCategory
+			}
+			itemID, flockID := line.InventoryItemID, item.FlockID
+			expense := models.Expense{
+				UserID:          userID,
+				FlockID:         &flockID,
+				Date:            receivedDate,
+				Description:     fmt.Sprintf("%s: %d x %s", order.OrderNo, line.Quantity, item.ItemName),
+				Amount:          line.Total,
+				CategoryRef:     models.CategoryRef{Category: category},
+				SupplierID:      &order.SupplierID,
+				PurchaseOrderID: &order.ID,
+				InventoryItemID: &itemID,
+			}
+			if err := applyCategory(tx, userID, models.CategoryKindExpense, &expense.CategoryRef); err != nil {
+				return fmt.Errorf("%s: %w", item.ItemName, err)
+			}
+			if err := tx.Omit("Flock").Create(&expense).Error; err != nil {
+				return err
+			}
+			if err := postExpenseEntries(tx, userID, expense.ID); err != nil {
+				return err
+			}
+			expenses = append(expenses, expense)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := NewInventoryService(s.DB)
+	for _, item := range items {
+		broadcast.SendInventoryUpdate(userID, "inventory_updated", *item)
+		inventory.CheckLowStock(item)
+	}
+	for _, expense := range expenses {
+		broadcast.SendExpenseUpdate(userID, "expense_added", expense)
+	}
+	NewBudgetService(s.DB).CheckExpenseAlerts(expenses...)
+	queueExpenseRecompute(expenses...)
+	supplierName := "supplier"
+	if order.Supplier != nil {
+		supplierName = order.Supplier.Name
+	}
+	broadcast.SendNotification(userID, "Purchase Order Received",
+		fmt.Sprintf("%s from %s was received (KES %.2f).", order.OrderNo, supplierName, order.Total), "/purchase-orders")
+
+	return &order, nil
+}
+
+// validate checks the supplier and every line's inventory item belong to the order's user
+func (s *PurchaseOrderService) validate(order *models.PurchaseOrder) error {
+	if len(order.Lines) == 0 {
+		return errors.New("purchase order must have at least one line")
+	}
+	if _, err := NewSupplierService(s.DB).GetSupplier(order.SupplierID, order.UserID); err != nil {
+		return err
+	}
+
+	for i, line := range order.Lines {
+		var count int64
+		if err := s.DB.Model(&models.InventoryItem{}).
+			Where("id = ? AND user_id = ?", line.InventoryItemID, order.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("line %d: inventory item not found", i+1)
+		}
+
+		if line.ExpenseCategory == "" {
+			continue
+		}
+		ref, err := NewCategoryService(s.DB).Resolve(order.UserID, models.CategoryKindExpense,
+			models.CategoryRef{Category: line.ExpenseCategory})
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+		order.Lines[i].ExpenseCategory = ref.Category
+		if ref.Subcategory != "" {
+			order.Lines[i].ExpenseCategory = ref.Subcategory
+		}
+	}
+	return nil
+}
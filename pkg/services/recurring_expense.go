@@ -0,0 +1,412 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecurringExpenseService manages recurring expense templates and posts their occurrences as expenses
+type RecurringExpenseService struct {
+	DB *gorm.DB
+}
+
+// NewRecurringExpenseService initializes a new service instance
+func NewRecurringExpenseService(db *gorm.DB) *RecurringExpenseService {
+	return &RecurringExpenseService{DB: db}
+}
+
+// GetRecurringExpenses returns a user's recurring expenses with their exceptions
+func (s *RecurringExpenseService) GetRecurringExpenses(userID uint) ([]models.RecurringExpense, error) {
+	var templates []models.RecurringExpense
+	err := s.DB.Preload("Exceptions", func(tx *gorm.DB) *gorm.DB { return tx.Order("date ASC") }).
+		Where("user_id = ?", userID).Order("description ASC").Find(&templates).Error
+	return templates, err
+}
+
+// GetRecurringExpense returns a single recurring expense owned by the user
+func (s *RecurringExpenseService) GetRecurringExpense(id, userID uint) (*models.RecurringExpense, error) {
+	var template models.RecurringExpense
+	if err := s.DB.Preload("Exceptions", func(tx *gorm.DB) *gorm.DB { return tx.Order("date ASC") }).
+		Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return nil, errors.New("recurring expense not found")
+	}
+	return &template, nil
+}
+
+// AddRecurringExpense creates a recurring expense and posts any occurrences already due
+func (s *RecurringExpenseService) AddRecurringExpense(template *models.RecurringExpense) error {
+	if err := s.validateFlock(template); err != nil {
+		return err
+	}
+	if err := applyCategory(s.DB, template.UserID, models.CategoryKindExpense, &template.CategoryRef); err != nil {
+		return err
+	}
+	template.StartDate = truncateDay(template.StartDate)
+	if template.EndDate != nil {
+		end := truncateDay(*template.EndDate)
+		template.EndDate = &end
+	}
+	template.NextDate = template.StartDate
+	template.Paused = false
+
+	if err := s.DB.Omit("Exceptions").Create(template).Error; err != nil {
+		return err
+	}
+	broadcast.SendExpenseUpdate(template.UserID, "recurring_expense_added", *template)
+
+	return s.postDue(template, truncateDay(time.Now()))
+}
+
+// UpdateRecurringExpense edits a recurring expense. Changes apply to occurrences not yet posted.
+func (s *RecurringExpenseService) UpdateRecurringExpense(template *models.RecurringExpense) error {
+	existing, err := s.GetRecurringExpense(template.ID, template.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateFlock(template); err != nil {
+		return err
+	}
+	if err := applyCategory(s.DB, template.UserID, models.CategoryKindExpense, &template.CategoryRef); err != nil {
+		return err
+	}
+	template.StartDate = truncateDay(template.StartDate)
+	if template.EndDate != nil {
+		end := truncateDay(*template.EndDate)
+		template.EndDate = &end
+	}
+	template.Paused = existing.Paused
+	template.CreatedAt = existing.CreatedAt
+
+	// Resume after the last posted occurrence so an edited schedule neither reposts nor drops dates
+	from := template.StartDate
+	var lastPosted sql.NullTime
+	if err := s.DB.Model(&models.Expense{}).Where("recurring_expense_id = ?", template.ID).
+		Select("MAX(date)").Row().Scan(&lastPosted); err != nil {
+		return err
+	}
+	if lastPosted.Valid && !lastPosted.Time.Before(from) {
+		from = truncateDay(lastPosted.Time).AddDate(0, 0, 1)
+	}
+	template.NextDate = firstOccurrenceFrom(template, from)
+	template.Exceptions = existing.Exceptions
+
+	if err := s.DB.Omit("Exceptions").Save(template).Error; err != nil {
+		return err
+	}
+	broadcast.SendExpenseUpdate(template.UserID, "recurring_expense_updated", *template)
+
+	if template.Paused {
+		return nil
+	}
+	return s.postDue(template, truncateDay(time.Now()))
+}
+
+// DeleteRecurringExpense removes a recurring expense. Expenses it already posted are kept.
+func (s *RecurringExpenseService) DeleteRecurringExpense(id, userID uint) error {
+	if _, err := s.GetRecurringExpense(id, userID); err != nil {
+		return err
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Expense{}).Where("recurring_expense_id = ?", id).
+			UpdateColumn("recurring_expense_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("recurring_expense_id = ?", id).Delete(&models.RecurringExpenseException{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.RecurringExpense{}, id).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	broadcast.SendExpenseUpdate(userID, "recurring_expense_deleted", id)
+	return nil
+}
+
+// PauseRecurringExpense stops a recurring expense from posting until it is resumed
+func (s *RecurringExpenseService) PauseRecurringExpense(id, userID uint) (*models.RecurringExpense, error) {
+	template, err := s.GetRecurringExpense(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	template.Paused = true
+	if err := s.DB.Model(template).UpdateColumn("paused", true).Error; err != nil {
+		return nil, err
+	}
+
+	broadcast.SendExpenseUpdate(userID, "recurring_expense_updated", *template)
+	return template, nil
+}
+
+// ResumeRecurringExpense restarts a paused recurring expense from its next occurrence on or after today.
+// Occurrences that fell while it was paused are not posted.
+func (s *RecurringExpenseService) ResumeRecurringExpense(id, userID uint) (*models.RecurringExpense, error) {
+	template, err := s.GetRecurringExpense(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(time.Now())
+	from := truncateDay(template.NextDate)
+	if from.Before(today) {
+		from = today
+	}
+	template.Paused = false
+	template.NextDate = firstOccurrenceFrom(template, from)
+	if err := s.DB.Model(template).UpdateColumns(map[string]interface{}{
+		"paused":    false,
+		"next_date": template.NextDate,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	broadcast.SendExpenseUpdate(userID, "recurring_expense_updated", *template)
+	if err := s.postDue(template, today); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// SetException skips a future occurrence or changes the amount or description posted for it,
+// replacing any exception already set for that date
+func (s *RecurringExpenseService) SetException(id, userID uint, exception *models.RecurringExpenseException) error {
+	template, err := s.GetRecurringExpense(id, userID)
+	if err != nil {
+		return err
+	}
+
+	exception.Date = truncateDay(exception.Date)
+	if exception.Date.Before(truncateDay(template.NextDate)) {
+		return errors.New("only occurrences that have not been posted can be changed")
+	}
+	if !isOccurrence(template, exception.Date) {
+		return fmt.Errorf("%s is not an occurrence of this recurring expense", exception.Date.Format("2006-01-02"))
+	}
+	if !exception.Skip && exception.Amount == nil && exception.Description == "" {
+		return errors.New("set skip, amount or description for the occurrence")
+	}
+	if exception.Amount != nil && *exception.Amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+
+	exception.RecurringExpenseID = template.ID
+	var existing models.RecurringExpenseException
+	err = s.DB.Where("recurring_expense_id = ? AND date = ?", template.ID, exception.Date.Format("2006-01-02")).
+		First(&existing).Error
+	if err == nil {
+		exception.ID = existing.ID
+		exception.CreatedAt = existing.CreatedAt
+		return s.DB.Save(exception).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	exception.ID = 0
+	return s.DB.Create(exception).Error
+}
+
+// DeleteException restores an occurrence to the template's schedule and amount
+func (s *RecurringExpenseService) DeleteException(id, exceptionID, userID uint) error {
+	if _, err := s.GetRecurringExpense(id, userID); err != nil {
+		return err
+	}
+	result := s.DB.Where("id = ? AND recurring_expense_id = ?", exceptionID, id).Delete(&models.RecurringExpenseException{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("exception not found")
+	}
+	return nil
+}
+
+// GetUpcoming lists the next count occurrences of a recurring expense with exceptions applied
+func (s *RecurringExpenseService) GetUpcoming(id, userID uint, count int) ([]models.RecurringOccurrence, error) {
+	template, err := s.GetRecurringExpense(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	exceptions := exceptionsByDate(template)
+
+	occurrences := []models.RecurringOccurrence{}
+	for date := truncateDay(template.NextDate); len(occurrences) < count && withinEnd(template, date); date = nextOccurrence(template, date) {
+		occurrence := models.RecurringOccurrence{
+			Date:        date.Format("2006-01-02"),
+			Description: template.Description,
+			Amount:      template.Amount,
+		}
+		if exception, ok := exceptions[occurrence.Date]; ok {
+			occurrence.Skipped = exception.Skip
+			occurrence.Edited = exception.Amount != nil || exception.Description != ""
+			if exception.Amount != nil {
+				occurrence.Amount = *exception.Amount
+			}
+			if exception.Description != "" {
+				occurrence.Description = exception.Description
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// PostDueExpenses posts every occurrence of active recurring expenses due on or before now's day
+func (s *RecurringExpenseService) PostDueExpenses(now time.Time) error {
+	today := truncateDay(now)
+
+	var templates []models.RecurringExpense
+	if err := s.DB.Preload("Exceptions").Where("paused = ? AND next_date <= ?", false, today.Format("2006-01-02")).
+		Find(&templates).Error; err != nil {
+		return err
+	}
+
+	for i := range templates {
+		if err := s.postDue(&templates[i], today); err != nil {
+			log.Printf("Error posting recurring expense %d: %v", templates[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// postDue posts a template's occurrences from NextDate up to today and advances NextDate past them.
+// Occurrences that already have an expense are not posted again.
+func (s *RecurringExpenseService) postDue(template *models.RecurringExpense, today time.Time) error {
+	if template.Paused {
+		return nil
+	}
+	exceptions := exceptionsByDate(template)
+
+	var posted []models.Expense
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		posted = nil
+		next := truncateDay(template.NextDate)
+		for !next.After(today) && withinEnd(template, next) {
+			date := next
+			next = nextOccurrence(template, date)
+
+			expense := models.Expense{
+				UserID:             template.UserID,
+				FlockID:            &template.FlockID,
+				Date:               date,
+				Description:        template.Description,
+				Amount:             template.Amount,
+				CategoryRef:        template.CategoryRef,
+				RecurringExpenseID: &template.ID,
+			}
+			if exception, ok := exceptions[date.Format("2006-01-02")]; ok {
+				if exception.Skip {
+					continue
+				}
+				if exception.Amount != nil {
+					expense.Amount = *exception.Amount
+				}
+				if exception.Description != "" {
+					expense.Description = exception.Description
+				}
+			}
+
+			var count int64
+			if err := tx.Model(&models.Expense{}).Where("recurring_expense_id = ? AND date = ?", template.ID, date.Format("2006-01-02")).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if err := tx.Omit("Flock").Create(&expense).Error; err != nil {
+				return err
+			}
+			if err := postExpenseEntries(tx, expense.UserID, expense.ID); err != nil {
+				return err
+			}
+			posted = append(posted, expense)
+		}
+
+		template.NextDate = next
+		return tx.Model(template).UpdateColumn("next_date", next).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, expense := range posted {
+		broadcast.SendExpenseUpdate(template.UserID, "expense_added", expense)
+	}
+	NewBudgetService(s.DB).CheckExpenseAlerts(posted...)
+	queueExpenseRecompute(posted...)
+	if len(posted) > 0 {
+		message := fmt.Sprintf("Recurring expense '%s' posted: KES %.2f.", template.Description, posted[len(posted)-1].Amount)
+		if len(posted) > 1 {
+			message = fmt.Sprintf("Recurring expense '%s' posted for %d occurrences.", template.Description, len(posted))
+		}
+		broadcast.SendNotification(template.UserID, "Recurring Expense Posted", message, "/expenses")
+	}
+	return nil
+}
+
+// validateFlock checks the template's flock belongs to its user
+func (s *RecurringExpenseService) validateFlock(template *models.RecurringExpense) error {
+	var count int64
+	if err := s.DB.Model(&models.Flock{}).Where("id = ? AND user_id = ?", template.FlockID, template.UserID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("flock not found")
+	}
+	return nil
+}
+
+// nextOccurrence returns the occurrence after date, which must itself be an occurrence.
+// Monthly schedules keep the start date's day of the month, falling back to the last day of shorter months.
+func nextOccurrence(template *models.RecurringExpense, date time.Time) time.Time {
+	switch template.Frequency {
+	case models.FrequencyWeekly:
+		return date.AddDate(0, 0, 7)
+	case models.FrequencyMonthly:
+		year, month := date.Year(), date.Month()+1
+		day := template.StartDate.Day()
+		if last := time.Date(year, month+1, 0, 0, 0, 0, 0, date.Location()).Day(); day > last {
+			day = last
+		}
+		return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+	case models.FrequencyCustom:
+		return date.AddDate(0, 0, template.IntervalDays)
+	default:
+		return date.AddDate(0, 0, 1)
+	}
+}
+
+// firstOccurrenceFrom returns the first occurrence on or after from
+func firstOccurrenceFrom(template *models.RecurringExpense, from time.Time) time.Time {
+	date := truncateDay(template.StartDate)
+	for date.Before(from) {
+		date = nextOccurrence(template, date)
+	}
+	return date
+}
+
+func isOccurrence(template *models.RecurringExpense, date time.Time) bool {
+	return firstOccurrenceFrom(template, date).Equal(date)
+}
+
+func withinEnd(template *models.RecurringExpense, date time.Time) bool {
+	return template.EndDate == nil || !date.After(truncateDay(*template.EndDate))
+}
+
+func exceptionsByDate(template *models.RecurringExpense) map[string]models.RecurringExpenseException {
+	exceptions := make(map[string]models.RecurringExpenseException, len(template.Exceptions))
+	for _, exception := range template.Exceptions {
+		exceptions[exception.Date.Format("2006-01-02")] = exception
+	}
+	return exceptions
+}
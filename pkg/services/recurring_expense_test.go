@@ -0,0 +1,79 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		frequency string
+		interval  int
+		start     string
+		from      string
+		want      []string
+	}{
+		{
+			name: "daily", frequency: models.FrequencyDaily,
+			start: "2026-02-27", from: "2026-02-27",
+			want: []string{"2026-02-28", "2026-03-01"},
+		},
+		{
+			name: "weekly", frequency: models.FrequencyWeekly,
+			start: "2026-12-24", from: "2026-12-24",
+			want: []string{"2026-12-31", "2027-01-07"},
+		},
+		{
+			name: "custom interval", frequency: models.FrequencyCustom, interval: 10,
+			start: "2026-01-25", from: "2026-01-25",
+			want: []string{"2026-02-04", "2026-02-14"},
+		},
+		{
+			name: "monthly keeps the day of the month", frequency: models.FrequencyMonthly,
+			start: "2026-01-15", from: "2026-01-15",
+			want: []string{"2026-02-15", "2026-03-15"},
+		},
+		{
+			name: "monthly from the 31st clamps to shorter months and recovers", frequency: models.FrequencyMonthly,
+			start: "2026-01-31", from: "2026-01-31",
+			want: []string{"2026-02-28", "2026-03-31", "2026-04-30", "2026-05-31"},
+		},
+		{
+			name: "monthly from the 30th in a leap year", frequency: models.FrequencyMonthly,
+			start: "2028-01-30", from: "2028-01-30",
+			want: []string{"2028-02-29", "2028-03-30"},
+		},
+		{
+			name: "monthly across the year end", frequency: models.FrequencyMonthly,
+			start: "2026-10-31", from: "2026-11-30",
+			want: []string{"2026-12-31", "2027-01-31", "2027-02-28"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &models.RecurringExpense{
+				Frequency:    tt.frequency,
+				IntervalDays: tt.interval,
+				StartDate:    date(tt.start),
+			}
+			current := date(tt.from)
+			for _, want := range tt.want {
+				current = nextOccurrence(template, current)
+				if got := current.Format("2006-01-02"); got != want {
+					t.Fatalf("nextOccurrence = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
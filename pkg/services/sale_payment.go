@@ -0,0 +1,151 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned when a sale's customer, amount or quantity conflicts with its payments and returns
+var (
+	ErrSaleCustomerNotFound = errors.New("customer not found")
+	ErrSaleBelowAmountPaid  = errors.New("sale amount is less than the amount already paid or credited")
+	ErrSaleBelowReturned    = errors.New("sale quantity is less than the quantity already returned")
+)
+
+// GetPayments returns the payments recorded against a sale, oldest first
+func (s *SalesService) GetPayments(saleID, userID uint) ([]models.SalePayment, error) {
+	var count int64
+	if err := s.DB.Model(&models.Sale{}).Where("id = ? AND user_id = ?", saleID, userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("sale not found")
+	}
+
+	var payments []models.SalePayment
+	err := s.DB.Where("sale_id = ?", saleID).Order("date ASC, id ASC").Find(&payments).Error
+	return payments, err
+}
+
+// AddPayment records an instalment against a sale and updates its amount paid and status.
+// Payments cannot exceed the sale's outstanding balance.
+func (s *SalesService) AddPayment(payment *models.SalePayment) (*models.Sale, error) {
+	if payment.Amount <= 0 {
+		return nil, errors.New("payment amount must be greater than zero")
+	}
+	if !models.IsValidPaymentMethod(payment.Method) {
+		return nil, fmt.Errorf("invalid payment method '%s'", payment.Method)
+	}
+	if payment.Date.IsZero() {
+		payment.Date = time.Now()
+	}
+
+	var sale models.Sale
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", payment.SaleID, payment.UserID).First(&sale).Error; err != nil {
+			return errors.New("sale not found")
+		}
+		if outstanding := sale.NetAmount() - sale.AmountPaid; payment.Amount > outstanding+0.005 {
+			return fmt.Errorf("payment of %.2f exceeds the outstanding balance of %.2f", payment.Amount, outstanding)
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		if err := syncSalePayments(tx, &sale); err != nil {
+			return err
+		}
+		return postSaleEntries(tx, sale.UserID, sale.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	broadcast.SendSaleUpdate(sale.UserID, "sale_payment_added", sale)
+	broadcast.SendNotification(sale.UserID, "Payment Received",
+		fmt.Sprintf("KES %.2f received for sale %s via %s.", payment.Amount, sale.RefNo, payment.Method), "/sales")
+
+	return &sale, nil
+}
+
+// DeletePayment removes a payment recorded in error and reopens the sale's balance
+func (s *SalesService) DeletePayment(saleID, paymentID, userID uint) (*models.Sale, error) {
+	var sale models.Sale
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", saleID, userID).First(&sale).Error; err != nil {
+			return errors.New("sale not found")
+		}
+		result := tx.Where("id = ? AND sale_id = ?", paymentID, saleID).Delete(&models.SalePayment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("payment not found")
+		}
+		if err := syncSalePayments(tx, &sale); err != nil {
+			return err
+		}
+		return postSaleEntries(tx, userID, sale.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	broadcast.SendSaleUpdate(sale.UserID, "sale_payment_deleted", sale)
+	return &sale, nil
+}
+
+// syncSalePayments stores the total paid on a sale and sets its status from it.
+// Sales that have never had payments keep whatever status they were given.
+func syncSalePayments(tx *gorm.DB, sale *models.Sale) error {
+	var totals struct {
+		Count int64
+		Paid  float64
+	}
+	if err := tx.Model(&models.SalePayment{}).Where("sale_id = ?", sale.ID).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS paid").Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	previouslyPaid := sale.AmountPaid
+	sale.AmountPaid = roundTo(totals.Paid, 2)
+	switch {
+	case totals.Count > 0 && sale.AmountPaid >= sale.NetAmount()-0.005:
+		sale.Status = models.SaleStatusPaid
+	case totals.Count > 0:
+		sale.Status = models.SaleStatusPartial
+	case previouslyPaid > 0:
+		sale.Status = models.SaleStatusPending
+	}
+
+	if err := tx.Model(sale).UpdateColumns(map[string]interface{}{
+		"amount_paid": sale.AmountPaid,
+		"status":      sale.Status,
+	}).Error; err != nil {
+		return err
+	}
+	return sale.AfterFind(tx)
+}
+
+// validateSaleCustomer checks the sale's customer belongs to its user and defaults the due date from the customer's credit terms
+func validateSaleCustomer(tx *gorm.DB, sale *models.Sale) error {
+	if sale.CustomerID == nil || *sale.CustomerID == 0 {
+		sale.CustomerID = nil
+		return nil
+	}
+
+	var customer models.Customer
+	if err := tx.Where("id = ? AND user_id = ?", *sale.CustomerID, sale.UserID).First(&customer).Error; err != nil {
+		return ErrSaleCustomerNotFound
+	}
+	if sale.DueDate == nil && customer.CreditDays > 0 {
+		due := sale.Date.AddDate(0, 0, customer.CreditDays)
+		sale.DueDate = &due
+	}
+	return nil
+}
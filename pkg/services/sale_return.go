@@ -0,0 +1,244 @@
+package services
+
+import (
+	"birdseye-backend/pkg/broadcast"
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetReturns returns a user's credit notes dated between start and end, newest first
+func (s *SalesService) GetReturns(userID uint, start, end time.Time) ([]models.SaleReturn, error) {
+	var returns []models.SaleReturn
+	err := s.DB.Preload("Sale").Where("user_id = ? AND date BETWEEN ? AND ?", userID, start, endOfDay(end)).
+		Order("date DESC, id DESC").Find(&returns).Error
+	return returns, err
+}
+
+// GetSaleReturns returns the credit notes raised against a sale, oldest first
+func (s *SalesService) GetSaleReturns(saleID, userID uint) ([]models.SaleReturn, error) {
+	var count int64
+	if err := s.DB.Model(&models.Sale{}).Where("id = ? AND user_id = ?", saleID, userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("sale not found")
+	}
+
+	var returns []models.SaleReturn
+	err := s.DB.Where("sale_id = ?", saleID).Order("date ASC, id ASC").Find(&returns).Error
+	return returns, err
+}
+
+// AddReturn raises a credit note against a sale for returned or rejected goods.
+// The amount defaults to the returned quantity at the sale's average price. Returned eggs go back into
+// stock, or with a write_off disposition are written off through an EggAdjustment.
+func (s *SalesService) AddReturn(ret *models.SaleReturn) (*models.Sale, error) {
+	if ret.Reason == "" {
+		return nil, errors.New("return reason is required")
+	}
+	if ret.Quantity < 0 || ret.Amount < 0 {
+		return nil, errors.New("return quantity and amount cannot be negative")
+	}
+	if ret.Quantity == 0 && ret.Amount == 0 {
+		return nil, errors.New("return must have a quantity or an amount")
+	}
+	if ret.Date.IsZero() {
+		ret.Date = time.Now()
+	}
+
+	var sale models.Sale
+	var adjustment *models.EggAdjustment
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", ret.SaleID, ret.UserID).First(&sale).Error; err != nil {
+			return errors.New("sale not found")
+		}
+		if truncateDay(ret.Date).Before(truncateDay(sale.Date)) {
+			return errors.New("return cannot be dated before the sale")
+		}
+		if ret.Quantity > sale.Quantity-sale.QuantityReturned {
+			return fmt.Errorf("only %d of this sale remain to be returned", sale.Quantity-sale.QuantityReturned)
+		}
+		if ret.Amount == 0 && sale.Quantity > 0 {
+			ret.Amount = roundTo(sale.Amount/float64(sale.Quantity)*float64(ret.Quantity), 2)
+		}
+		if remaining := sale.NetAmount(); ret.Amount > remaining+0.005 {
+			return fmt.Errorf("credit of %.2f exceeds the %.2f not yet credited on this sale", ret.Amount, remaining)
+		}
+
+		eggs, err := isEggSale(tx, &sale)
+		if err != nil {
+			return err
+		}
+
+		ret.FlockID = sale.FlockID
+		if !eggs || ret.Quantity == 0 {
+			ret.Disposition = ""
+		} else if ret.Disposition == "" {
+			ret.Disposition = models.ReturnRestock
+		} else if ret.Disposition != models.ReturnRestock && ret.Disposition != models.ReturnWriteOff {
+			return fmt.Errorf("invalid disposition '%s'", ret.Disposition)
+		}
+
+		ret.ID = 0
+		ret.EggAdjustmentID = nil
+		if err := tx.Omit("Sale").Create(ret).Error; err != nil {
+			return err
+		}
+
+		if ret.Disposition == models.ReturnWriteOff {
+			adjustment = &models.EggAdjustment{
+				UserID:       ret.UserID,
+				FlockID:      ret.FlockID,
+				Reason:       models.SalesReturnAdjustmentReason,
+				Quantity:     ret.Quantity,
+				Notes:        fmt.Sprintf("Credit note %s for sale %s: %s", ret.CreditNoteNo, sale.RefNo, ret.Reason),
+				DateAdjusted: ret.Date,
+			}
+			if err := tx.Create(adjustment).Error; err != nil {
+				return err
+			}
+			ret.EggAdjustmentID = &adjustment.ID
+			if err := tx.Model(ret).UpdateColumn("egg_adjustment_id", adjustment.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := syncSaleReturns(tx, &sale); err != nil {
+			return err
+		}
+		if err := syncSalePayments(tx, &sale); err != nil {
+			return err
+		}
+		return postSaleEntries(tx, sale.UserID, sale.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	broadcast.SendSaleUpdate(sale.UserID, "sale_return_added", *ret)
+	QueueFinancialRecompute(sale.UserID, ret.FlockID, ret.Date)
+	if adjustment != nil {
+		broadcast.SendEggAdjustmentUpdate(sale.UserID, "added", *adjustment)
+	}
+	broadcast.SendNotification(sale.UserID, "Credit Note Raised",
+		fmt.Sprintf("Credit note %s of KES %.2f raised against sale %s.", ret.CreditNoteNo, ret.Amount, sale.RefNo), "/sales")
+
+	return &sale, nil
+}
+
+// DeleteReturn voids a credit note raised in error, removing any write-off it made.
+// Voiding a restock takes the eggs back out of stock, so it is rejected if the flock no longer has them.
+func (s *SalesService) DeleteReturn(saleID, returnID, userID uint) (*models.Sale, error) {
+	var sale models.Sale
+	var ret models.SaleReturn
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", saleID, userID).First(&sale).Error; err != nil {
+			return errors.New("sale not found")
+		}
+		if err := tx.Where("id = ? AND sale_id = ?", returnID, saleID).First(&ret).Error; err != nil {
+			return errors.New("return not found")
+		}
+
+		if ret.EggAdjustmentID != nil {
+			if err := tx.Delete(&models.EggAdjustment{}, *ret.EggAdjustmentID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&ret).Error; err != nil {
+			return err
+		}
+		if err := syncSaleReturns(tx, &sale); err != nil {
+			return err
+		}
+		if err := syncSalePayments(tx, &sale); err != nil {
+			return err
+		}
+		if err := postSaleEntries(tx, userID, sale.ID); err != nil {
+			return err
+		}
+
+		if ret.Disposition != models.ReturnRestock {
+			return nil
+		}
+		return NewEggStockService(tx).CheckStock(userID, ret.FlockID, ret.Date)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	broadcast.SendSaleUpdate(userID, "sale_return_deleted", ret.ID)
+	QueueFinancialRecompute(userID, ret.FlockID, ret.Date)
+	if ret.EggAdjustmentID != nil {
+		broadcast.SendEggAdjustmentUpdate(userID, "deleted", *ret.EggAdjustmentID)
+	}
+	return &sale, nil
+}
+
+// syncSaleReturns stores the quantity and amount credited on a sale
+func syncSaleReturns(tx *gorm.DB, sale *models.Sale) error {
+	var totals struct {
+		Quantity int
+		Amount   float64
+	}
+	if err := tx.Model(&models.SaleReturn{}).Where("sale_id = ?", sale.ID).
+		Select("COALESCE(SUM(quantity), 0) AS quantity, COALESCE(SUM(amount), 0) AS amount").Scan(&totals).Error; err != nil {
+		return err
+	}
+
+	sale.QuantityReturned = totals.Quantity
+	sale.AmountReturned = roundTo(totals.Amount, 2)
+	return tx.Model(sale).UpdateColumns(map[string]interface{}{
+		"quantity_returned": sale.QuantityReturned,
+		"amount_returned":   sale.AmountReturned,
+	}).Error
+}
+
+// deleteSaleReturns removes a sale's credit notes and the write-offs they made
+func deleteSaleReturns(tx *gorm.DB, saleID uint) error {
+	var adjustmentIDs []uint
+	if err := tx.Model(&models.SaleReturn{}).Where("sale_id = ? AND egg_adjustment_id IS NOT NULL", saleID).
+		Pluck("egg_adjustment_id", &adjustmentIDs).Error; err != nil {
+		return err
+	}
+	if len(adjustmentIDs) > 0 {
+		if err := tx.Delete(&models.EggAdjustment{}, adjustmentIDs).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("sale_id = ?", saleID).Delete(&models.SaleReturn{}).Error
+}
+
+// saleRefunds returns the cash refunded with each of a sale's credit notes, given oldest first. A credit
+// note is refunded for the part of it that leaves the buyer having paid more than the sale's net amount by
+// its date; the rest comes off what they still owe. A sale marked paid without instalments was received
+// in full on its date.
+func saleRefunds(sale *models.Sale, returns []models.SaleReturn) []float64 {
+	settled := sale.Status == models.SaleStatusPaid && len(sale.Payments) == 0
+	received := func(date time.Time) float64 {
+		if settled {
+			return sale.Amount
+		}
+		total := 0.0
+		for _, payment := range sale.Payments {
+			if !truncateDay(payment.Date).After(truncateDay(date)) {
+				total += payment.Amount
+			}
+		}
+		return total
+	}
+
+	refunds := make([]float64, len(returns))
+	credited, refunded := 0.0, 0.0
+	for i, ret := range returns {
+		credited += ret.Amount
+		overpaid := received(ret.Date) - (sale.Amount - credited) - refunded
+		refunds[i] = roundTo(math.Max(0, math.Min(overpaid, ret.Amount)), 2)
+		refunded += refunds[i]
+	}
+	return refunds
+}
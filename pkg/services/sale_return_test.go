@@ -0,0 +1,97 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"math"
+	"testing"
+)
+
+func TestSaleRefunds(t *testing.T) {
+	type payment struct {
+		date   string
+		amount float64
+	}
+	type credit struct {
+		date   string
+		amount float64
+	}
+
+	tests := []struct {
+		name     string
+		status   string
+		payments []payment
+		returns  []credit
+		want     []float64
+	}{
+		{
+			name:    "sale marked paid refunds every credit note",
+			status:  models.SaleStatusPaid,
+			returns: []credit{{"2026-03-05", 200}, {"2026-03-06", 100}},
+			want:    []float64{200, 100},
+		},
+		{
+			name:    "unpaid sale refunds nothing",
+			status:  models.SaleStatusPending,
+			returns: []credit{{"2026-03-05", 200}},
+			want:    []float64{0},
+		},
+		{
+			name:     "instalments fully paid refund the credit",
+			status:   models.SaleStatusPaid,
+			payments: []payment{{"2026-03-02", 600}, {"2026-03-03", 400}},
+			returns:  []credit{{"2026-03-05", 250}},
+			want:     []float64{250},
+		},
+		{
+			name:     "part payment refunds only what was overpaid",
+			status:   models.SaleStatusPartial,
+			payments: []payment{{"2026-03-02", 900}},
+			returns:  []credit{{"2026-03-05", 300}},
+			want:     []float64{200},
+		},
+		{
+			name:     "credit within the balance owed is not refunded",
+			status:   models.SaleStatusPartial,
+			payments: []payment{{"2026-03-02", 500}},
+			returns:  []credit{{"2026-03-05", 300}},
+			want:     []float64{0},
+		},
+		{
+			name:     "payments after the credit note do not count",
+			status:   models.SaleStatusPaid,
+			payments: []payment{{"2026-03-02", 500}, {"2026-03-08", 300}},
+			returns:  []credit{{"2026-03-05", 200}},
+			want:     []float64{0},
+		},
+		{
+			name:     "later credit notes refund in full once the balance is cleared",
+			status:   models.SaleStatusPaid,
+			payments: []payment{{"2026-03-02", 900}},
+			returns:  []credit{{"2026-03-04", 50}, {"2026-03-05", 100}, {"2026-03-06", 75}},
+			want:     []float64{0, 50, 75},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sale := &models.Sale{Amount: 1000, Status: tt.status, Date: parseTestDate(t, "2026-03-01")}
+			for _, p := range tt.payments {
+				sale.Payments = append(sale.Payments, models.SalePayment{Date: parseTestDate(t, p.date), Amount: p.amount})
+			}
+			var returns []models.SaleReturn
+			for _, r := range tt.returns {
+				returns = append(returns, models.SaleReturn{Date: parseTestDate(t, r.date), Amount: r.amount})
+			}
+
+			refunds := saleRefunds(sale, returns)
+			if len(refunds) != len(tt.want) {
+				t.Fatalf("got %d refunds, want %d", len(refunds), len(tt.want))
+			}
+			for i := range refunds {
+				if math.Abs(refunds[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("refund %d = %.2f, want %.2f", i, refunds[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
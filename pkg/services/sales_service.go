@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"birdseye-backend/pkg/models"
 	"birdseye-backend/pkg/broadcast"
 	"gorm.io/gorm"
@@ -21,7 +22,7 @@ func NewSalesService(db *gorm.DB) *SalesService {
 // GetSalesByUser retrieves sales records for a specific user
 func (s *SalesService) GetSalesByUser(userID uint) ([]models.Sale, error) {
 	var sales []models.Sale
-	err := s.DB.Where("user_id = ?", userID).Find(&sales).Error
+	err := s.DB.Preload("Grades").Preload("Customer").Preload("Payments").Where("user_id = ?", userID).Find(&sales).Error
 	return sales, err
 }
 
@@ -29,7 +30,7 @@ func (s *SalesService) GetSalesByUser(userID uint) ([]models.Sale, error) {
 // including timestamps for dynamic filtering.
 func (s *SalesService) GetSalesByFlock(flockID uint, userID uint) ([]models.Sale, error) {
     var sales []models.Sale
-    err := s.DB.Where("flock_id = ? AND user_id = ?", flockID, userID).
+    err := s.DB.Preload("Grades").Preload("Customer").Preload("Payments").Where("flock_id = ? AND user_id = ?", flockID, userID).
         Order("created_at DESC").Find(&sales).Error
     return sales, err
 }
@@ -43,14 +44,42 @@ func (s *SalesService) GetSalesByFlockAndPeriod(flockID uint, userID uint, start
 }
 
 
-// AddSale adds a new sale, sends a WebSocket update, and notifies the user
+// AddSale adds a new sale, sends a WebSocket update, and notifies the user.
+// Prices left at zero are filled from the price list. Egg sales are rejected when the flock does not have enough eggs in stock.
 func (s *SalesService) AddSale(sale *models.Sale) error {
-	if err := s.DB.Create(sale).Error; err != nil {
+	if err := sale.ValidateGrades(); err != nil {
+		return err
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyCategory(tx, sale.UserID, models.CategoryKindSale, &sale.CategoryRef); err != nil {
+			return err
+		}
+		if err := validateSaleCustomer(tx, sale); err != nil {
+			return err
+		}
+		if err := fillSalePrices(tx, sale); err != nil {
+			return err
+		}
+		sale.AmountPaid = 0
+		sale.Payments = nil
+		if err := tx.Create(sale).Error; err != nil {
+			return err
+		}
+		if err := postSaleEntries(tx, sale.UserID, sale.ID); err != nil {
+			return err
+		}
+		if eggs, err := isEggSale(tx, sale); err != nil || !eggs {
+			return err
+		}
+		return NewEggStockService(tx).CheckStock(sale.UserID, sale.FlockID, sale.Date)
+	})
+	if err != nil {
 		return err
 	}
 
 	// Send WebSocket update
 	broadcast.SendSaleUpdate(sale.UserID, "sale_added", *sale)
+	QueueFinancialRecompute(sale.UserID, sale.FlockID, sale.Date)
 
 	// Send Notification
 	broadcast.SendNotification(
@@ -63,14 +92,75 @@ func (s *SalesService) AddSale(sale *models.Sale) error {
 	return nil
 }
 
-// UpdateSale updates an existing sale, sends a WebSocket update, and notifies the user
+// UpdateSale updates an existing sale, sends a WebSocket update, and notifies the user.
+// Grade lines are replaced with sale.Grades.
 func (s *SalesService) UpdateSale(sale *models.Sale) error {
-	if err := s.DB.Save(sale).Error; err != nil {
+	if err := sale.ValidateGrades(); err != nil {
+		return err
+	}
+	var old models.Sale
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", sale.ID, sale.UserID).First(&old).Error; err != nil {
+			return errors.New("sale not found")
+		}
+		if err := applyCategory(tx, sale.UserID, models.CategoryKindSale, &sale.CategoryRef); err != nil {
+			return err
+		}
+		if err := validateSaleCustomer(tx, sale); err != nil {
+			return err
+		}
+		if sale.Amount < old.AmountReturned+old.AmountPaid-0.005 {
+			return fmt.Errorf("%w (%.2f)", ErrSaleBelowAmountPaid, old.AmountReturned+old.AmountPaid)
+		}
+		if sale.Quantity < old.QuantityReturned {
+			return fmt.Errorf("%w (%d)", ErrSaleBelowReturned, old.QuantityReturned)
+		}
+		// Payments and returns are managed through their own endpoints
+		sale.AmountPaid = old.AmountPaid
+		sale.Payments = nil
+		sale.QuantityReturned = old.QuantityReturned
+		sale.AmountReturned = old.AmountReturned
+
+		if err := tx.Where("sale_id = ?", sale.ID).Delete(&models.SaleGradeLine{}).Error; err != nil {
+			return err
+		}
+		for i := range sale.Grades {
+			sale.Grades[i].ID = 0
+			sale.Grades[i].SaleID = sale.ID
+		}
+		if err := tx.Save(sale).Error; err != nil {
+			return err
+		}
+		if old.AmountPaid > 0 || old.AmountReturned > 0 {
+			if err := syncSalePayments(tx, sale); err != nil {
+				return err
+			}
+		}
+		if err := postSaleEntries(tx, sale.UserID, sale.ID); err != nil {
+			return err
+		}
+
+		eggSalesID, err := EggSalesCategoryID(tx)
+		if err != nil {
+			return err
+		}
+		if sale.CategoryID != eggSalesID {
+			return nil
+		}
+		if old.CategoryID == eggSalesID &&
+			!eggOutflowIncreased(old.FlockID, old.Date, old.Quantity, sale.FlockID, sale.Date, sale.Quantity) {
+			return nil
+		}
+		return NewEggStockService(tx).CheckStock(sale.UserID, sale.FlockID, sale.Date)
+	})
+	if err != nil {
 		return err
 	}
 
 	// Send WebSocket update
 	broadcast.SendSaleUpdate(sale.UserID, "sale_updated", *sale)
+	QueueFinancialRecompute(old.UserID, old.FlockID, old.Date)
+	QueueFinancialRecompute(sale.UserID, sale.FlockID, sale.Date)
 
 	// Send Notification
 	broadcast.SendNotification(
@@ -90,12 +180,28 @@ func (s *SalesService) DeleteSale(saleID uint, userID uint) error {
 		return errors.New("sale not found")
 	}
 
-	if err := s.DB.Delete(&sale).Error; err != nil {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sale_id = ?", sale.ID).Delete(&models.SaleGradeLine{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("sale_id = ?", sale.ID).Delete(&models.SalePayment{}).Error; err != nil {
+			return err
+		}
+		if err := deleteSaleReturns(tx, sale.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&sale).Error; err != nil {
+			return err
+		}
+		return postSaleEntries(tx, userID, sale.ID)
+	})
+	if err != nil {
 		return err
 	}
 
 	// Send WebSocket update
 	broadcast.SendSaleUpdate(userID, "sale_deleted", saleID)
+	QueueFinancialRecompute(userID, sale.FlockID, sale.Date)
 
 	// Send Notification
 	broadcast.SendNotification(
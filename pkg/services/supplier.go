@@ -0,0 +1,83 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SupplierService manages the supplier directory
+type SupplierService struct {
+	DB *gorm.DB
+}
+
+// NewSupplierService initializes a new service instance
+func NewSupplierService(db *gorm.DB) *SupplierService {
+	return &SupplierService{DB: db}
+}
+
+// GetSuppliers returns a user's suppliers ordered by name
+func (s *SupplierService) GetSuppliers(userID uint) ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	err := s.DB.Where("user_id = ?", userID).Order("name").Find(&suppliers).Error
+	return suppliers, err
+}
+
+// GetSupplier returns a single supplier owned by the user
+func (s *SupplierService) GetSupplier(id, userID uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	if err := s.DB.Where("id = ? AND user_id = ?", id, userID).First(&supplier).Error; err != nil {
+		return nil, errors.New("supplier not found")
+	}
+	return &supplier, nil
+}
+
+// AddSupplier creates a supplier
+func (s *SupplierService) AddSupplier(supplier *models.Supplier) error {
+	if supplier.Name == "" {
+		return errors.New("supplier name is required")
+	}
+	return s.DB.Create(supplier).Error
+}
+
+// UpdateSupplier saves changes to a supplier owned by the user
+func (s *SupplierService) UpdateSupplier(supplier *models.Supplier) error {
+	existing, err := s.GetSupplier(supplier.ID, supplier.UserID)
+	if err != nil {
+		return err
+	}
+	supplier.CreatedAt = existing.CreatedAt
+	return s.DB.Save(supplier).Error
+}
+
+// DeleteSupplier removes a supplier that has no purchase orders
+func (s *SupplierService) DeleteSupplier(id, userID uint) error {
+	if _, err := s.GetSupplier(id, userID); err != nil {
+		return err
+	}
+
+	var orders int64
+	if err := s.DB.Model(&models.PurchaseOrder{}).Where("supplier_id = ?", id).Count(&orders).Error; err != nil {
+		return err
+	}
+	if orders > 0 {
+		return errors.New("supplier has purchase orders and cannot be deleted")
+	}
+	return s.DB.Delete(&models.Supplier{}, id).Error
+}
+
+// GetSupplierSpend totals expenses linked to each supplier between start and end, highest spend first
+func (s *SupplierService) GetSupplierSpend(userID uint, start, end time.Time) ([]models.SupplierSpend, error) {
+	var spend []models.SupplierSpend
+	err := s.DB.Table("expenses").
+		Select("expenses.supplier_id, suppliers.name AS supplier_name, "+
+			"COUNT(DISTINCT expenses.purchase_order_id) AS orders, SUM(expenses.amount) AS total_spend").
+		Joins("JOIN suppliers ON suppliers.id = expenses.supplier_id AND suppliers.user_id = expenses.user_id").
+		Where("expenses.user_id = ? AND expenses.date BETWEEN ? AND ?", userID, start, end).
+		Group("expenses.supplier_id, suppliers.name").
+		Order("total_spend DESC").
+		Scan(&spend).Error
+	return spend, err
+}
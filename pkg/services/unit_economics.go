@@ -0,0 +1,205 @@
+package services
+
+import (
+	"birdseye-backend/pkg/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UnitEconomicsService works out what eggs and birds cost to produce from expenses, stock used,
+// egg production and bird counts
+type UnitEconomicsService struct {
+	DB *gorm.DB
+}
+
+// NewUnitEconomicsService initializes a new service instance
+func NewUnitEconomicsService(db *gorm.DB) *UnitEconomicsService {
+	return &UnitEconomicsService{DB: db}
+}
+
+// GetFlockUnitEconomics returns a flock's unit economics between start and end, month by month
+func (s *UnitEconomicsService) GetFlockUnitEconomics(userID, flockID uint, start, end time.Time) (*models.UnitEconomics, error) {
+	var flock models.Flock
+	if err := s.DB.Where("id = ? AND user_id = ?", flockID, userID).First(&flock).Error; err != nil {
+		return nil, ErrFlockNotFound
+	}
+	return s.flockUnitEconomics(&flock, start, end)
+}
+
+// GetUnitEconomics returns unit economics between start and end for the whole farm, first,
+// followed by each of the user's flocks
+func (s *UnitEconomicsService) GetUnitEconomics(userID uint, start, end time.Time) ([]models.UnitEconomics, error) {
+	var flocks []models.Flock
+	if err := s.DB.Where("user_id = ?", userID).Order("name ASC").Find(&flocks).Error; err != nil {
+		return nil, err
+	}
+
+	var perFlock []models.UnitEconomics
+	for i := range flocks {
+		economics, err := s.flockUnitEconomics(&flocks[i], start, end)
+		if err != nil {
+			return nil, err
+		}
+		perFlock = append(perFlock, *economics)
+	}
+
+	start, end = truncateDay(start), truncateDay(end)
+	farm := models.UnitEconomics{FlockName: "All flocks", StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02")}
+	for _, economics := range perFlock {
+		if farm.Months == nil {
+			farm.StartDate, farm.EndDate = economics.StartDate, economics.EndDate
+			farm.Total.Period = economics.Total.Period
+			farm.Months = make([]models.UnitEconomicsPeriod, len(economics.Months))
+			for m, month := range economics.Months {
+				farm.Months[m].Period = month.Period
+			}
+		}
+		addUnitEconomics(&farm.Total, economics.Total)
+		for m := range economics.Months {
+			addUnitEconomics(&farm.Months[m], economics.Months[m])
+		}
+	}
+	finishUnitEconomics(&farm.Total)
+	for m := range farm.Months {
+		finishUnitEconomics(&farm.Months[m])
+	}
+
+	results := append([]models.UnitEconomics{farm}, perFlock...)
+	return results, nil
+}
+
+// flockUnitEconomics works out a flock's unit economics for each month between start and end, and in total.
+// An end date in the future is brought back to today.
+func (s *UnitEconomicsService) flockUnitEconomics(flock *models.Flock, start, end time.Time) (*models.UnitEconomics, error) {
+	start, end = truncateDay(start), truncateDay(end)
+	if today := truncateDay(time.Now()); end.After(today) {
+		end = today
+	}
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	history, err := NewMortalityService(s.DB).BirdCountHistory(flock, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	economics := &models.UnitEconomics{
+		FlockID:   flock.ID,
+		FlockName: flock.Name,
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		Total:     models.UnitEconomicsPeriod{Period: fmt.Sprintf("%s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))},
+		Months:    []models.UnitEconomicsPeriod{},
+	}
+
+	totalBirdDays := 0
+	for monthStart := start; !monthStart.After(end); monthStart = firstOfMonth(monthStart).AddDate(0, 1, 0) {
+		monthEnd := firstOfMonth(monthStart).AddDate(0, 1, -1)
+		if monthEnd.After(end) {
+			monthEnd = end
+		}
+
+		period, birdDays, err := s.period(flock, history, monthStart, monthEnd)
+		if err != nil {
+			return nil, err
+		}
+		period.Period = monthStart.Format("2006-01")
+		totalBirdDays += birdDays
+
+		addUnitEconomics(&economics.Total, period)
+		finishUnitEconomics(&period)
+		economics.Months = append(economics.Months, period)
+	}
+
+	// Average birds over the whole range, not the sum of the monthly averages
+	economics.Total.AverageBirds = roundTo(float64(totalBirdDays)/float64(daysBetween(start, end)+1), 2)
+	finishUnitEconomics(&economics.Total)
+	return economics, nil
+}
+
+// period gathers a flock's costs, revenue, eggs, feed and average bird count between start and end,
+// returning it with the bird-days it covers
+func (s *UnitEconomicsService) period(flock *models.Flock, history map[string]int, start, end time.Time) (models.UnitEconomicsPeriod, int, error) {
+	var period models.UnitEconomicsPeriod
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	totals, err := computeFlockTotals(s.DB, flock.UserID, flock.ID, start, end)
+	if err != nil {
+		return period, 0, err
+	}
+	period.Costs = totals.Expenses
+	period.StockUsed = totals.StockUsed
+	period.Revenue = totals.Revenue
+	period.EggSales = totals.EggSales
+
+	var eggs int64
+	if err := s.DB.Model(&models.EggProduction{}).Select("COALESCE(SUM(eggs_collected), 0)").
+		Where("flock_id = ? AND date_produced BETWEEN ? AND ?", flock.ID, from, to).Scan(&eggs).Error; err != nil {
+		return period, 0, err
+	}
+	period.EggsCollected = int(eggs)
+
+	eggSalesID, err := EggSalesCategoryID(s.DB)
+	if err != nil {
+		return period, 0, err
+	}
+	var sold int64
+	if err := s.DB.Model(&models.Sale{}).Select("COALESCE(SUM(quantity - quantity_returned), 0)").
+		Where("flock_id = ? AND category_id = ? AND date BETWEEN ? AND ?", flock.ID, eggSalesID, start, endOfDay(end)).
+		Scan(&sold).Error; err != nil {
+		return period, 0, err
+	}
+	period.EggsSold = int(sold)
+
+	if err := s.DB.Model(&models.FeedLog{}).Select("COALESCE(SUM(quantity_kg), 0)").
+		Where("flock_id = ? AND date BETWEEN ? AND ?", flock.ID, from, to).Scan(&period.FeedKg).Error; err != nil {
+		return period, 0, err
+	}
+
+	days, birdDays := 0, 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		birdDays += history[day.Format("2006-01-02")]
+		days++
+	}
+	period.AverageBirds = roundTo(float64(birdDays)/float64(days), 2)
+	return period, birdDays, nil
+}
+
+// addUnitEconomics adds the amounts and counts of one period to another; ratios are left to finishUnitEconomics
+func addUnitEconomics(total *models.UnitEconomicsPeriod, period models.UnitEconomicsPeriod) {
+	total.Costs = roundTo(total.Costs+period.Costs, 2)
+	total.StockUsed = roundTo(total.StockUsed+period.StockUsed, 2)
+	total.Revenue = roundTo(total.Revenue+period.Revenue, 2)
+	total.EggSales = roundTo(total.EggSales+period.EggSales, 2)
+	total.EggsCollected += period.EggsCollected
+	total.EggsSold += period.EggsSold
+	total.FeedKg = roundTo(total.FeedKg+period.FeedKg, 2)
+	total.AverageBirds = roundTo(total.AverageBirds+period.AverageBirds, 2)
+}
+
+// finishUnitEconomics works out a period's unit costs, break-even price and average egg price
+func finishUnitEconomics(period *models.UnitEconomicsPeriod) {
+	period.OtherRevenue = roundTo(period.Revenue-period.EggSales, 2)
+	period.CostPerEgg, period.CostPerDozen, period.BreakEvenPerEgg, period.BreakEvenPerDozen = 0, 0, 0, 0
+	period.CostPerBird, period.AvgEggPrice = 0, 0
+
+	if period.EggsCollected > 0 {
+		eggs := float64(period.EggsCollected)
+		period.CostPerEgg = roundTo(period.Costs/eggs, 2)
+		period.CostPerDozen = roundTo(period.Costs/eggs*12, 2)
+		if uncovered := period.Costs - period.OtherRevenue; uncovered > 0 {
+			period.BreakEvenPerEgg = roundTo(uncovered/eggs, 2)
+			period.BreakEvenPerDozen = roundTo(uncovered/eggs*12, 2)
+		}
+	}
+	if period.AverageBirds > 0 {
+		period.CostPerBird = roundTo(period.Costs/period.AverageBirds, 2)
+	}
+	if period.EggsSold > 0 {
+		period.AvgEggPrice = roundTo(period.EggSales/float64(period.EggsSold), 2)
+	}
+}
@@ -23,7 +23,7 @@ func NewVaccinationService(db *gorm.DB) *VaccinationService {
 // GetVaccinationsByFlock retrieves all vaccination records for a flock
 func (s *VaccinationService) GetVaccinationsByFlock(flockID uint) ([]models.Vaccination, error) {
 	var vaccinations []models.Vaccination
-	err := s.DB.Where("flock_id = ?", flockID).Find(&vaccinations).Error
+	err := s.DB.Preload("InventoryLot").Where("flock_id = ?", flockID).Find(&vaccinations).Error
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +120,85 @@ func (s *VaccinationService) DeleteVaccination(vaccinationID uint) error {
 	broadcast.SendFlockUpdate(vaccination.FlockID, "vaccination_deleted", vaccinationID)
 	return nil
 }
+// SyncLotUsage keeps the stock drawn for a vaccination in step with it. A done vaccination with a lot
+// and doses draws DosesUsed from that lot on its date; anything else draws nothing. Usage already posted
+// that no longer matches, because the lot, doses, date or status changed, is reversed on the date it was
+// posted before the new usage is drawn. Pass deleted to reverse everything for a removed vaccination.
+// It returns the inventory items whose stock changed.
+func (s *VaccinationService) SyncLotUsage(vaccination *models.Vaccination, deleted bool) ([]*models.InventoryItem, error) {
+	reference := fmt.Sprintf("vaccination:%d", vaccination.ID)
+	var wantLot *uint
+	if !deleted && vaccination.InventoryLotID != nil && vaccination.DosesUsed > 0 && vaccination.IsDone() {
+		wantLot = vaccination.InventoryLotID
+	}
+
+	var posted []struct {
+		InventoryItemID uint
+		LotID           *uint
+		Net             int
+		Date            time.Time
+	}
+	if err := s.DB.Model(&models.InventoryMovement{}).
+		Select("inventory_item_id, lot_id, SUM(quantity_delta) AS net, MAX(date) AS date").
+		Where("user_id = ? AND reference = ?", vaccination.UserID, reference).
+		Group("inventory_item_id, lot_id").Scan(&posted).Error; err != nil {
+		return nil, err
+	}
+
+	inventory := NewInventoryService(s.DB)
+	var items []*models.InventoryItem
+	satisfied := false
+	for _, p := range posted {
+		if p.Net == 0 {
+			continue
+		}
+		if wantLot != nil && p.LotID != nil && *p.LotID == *wantLot && p.Net == -vaccination.DosesUsed &&
+			truncateDay(p.Date).Equal(truncateDay(vaccination.Date)) {
+			satisfied = true
+			continue
+		}
+		item, err := inventory.RecordMovement(&models.InventoryMovement{
+			UserID:          vaccination.UserID,
+			InventoryItemID: p.InventoryItemID,
+			Type:            models.MovementUsage,
+			QuantityDelta:   -p.Net,
+			Reference:       reference,
+			Notes:           "Reversal: " + vaccination.VaccineName,
+			FlockID:         &vaccination.FlockID,
+			LotID:           p.LotID,
+			Date:            p.Date,
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if wantLot == nil || satisfied {
+		return items, nil
+	}
+
+	lot, err := inventory.GetLot(*wantLot, vaccination.UserID)
+	if err != nil {
+		return nil, err
+	}
+	flockID := vaccination.FlockID
+	item, err := inventory.RecordMovement(&models.InventoryMovement{
+		UserID:          vaccination.UserID,
+		InventoryItemID: lot.InventoryItemID,
+		Type:            models.MovementUsage,
+		QuantityDelta:   -vaccination.DosesUsed,
+		Reference:       reference,
+		Notes:           vaccination.VaccineName,
+		FlockID:         &flockID,
+		LotID:           &lot.ID,
+		Date:            vaccination.Date,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(items, item), nil
+}
+
 // SendVaccinationReminder sends a reminder to the user for upcoming vaccinations
 func (s *VaccinationService) SendVaccinationReminder(vaccination *models.Vaccination, userID uint) error {
 	// Calculate the reminder date (e.g., 3 days before the vaccination date)